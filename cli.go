@@ -0,0 +1,1250 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"groupscholar-review-queue-forecaster/export"
+	"groupscholar-review-queue-forecaster/forecast"
+	"groupscholar-review-queue-forecaster/ingest"
+	"groupscholar-review-queue-forecaster/server"
+	"groupscholar-review-queue-forecaster/store"
+)
+
+const (
+	exitOK           = 0
+	exitFailure      = 1
+	exitUsage        = 2
+	exitInvalidInput = 3
+)
+
+// maxBacktestRuns caps backtest --runs, which decodes every scored run's
+// report and holds their queues in memory together.
+const maxBacktestRuns = 200
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+func commands() []command {
+	return []command{
+		{name: "report", summary: "Build the full review report (console or JSON), optionally writing exports and storing the run", run: runReportCommand},
+		{name: "forecast", summary: "Print the pending queue forecast only", run: runForecastCommand},
+		{name: "export", summary: "Write CSV, brief, and projection files without console output", run: runExportCommand},
+		{name: "backtest", summary: "Replay history (or stored runs) to score past queue forecasts: MAE, MAPE, and bias", run: runBacktestCommand},
+		{name: "validate", summary: "Check event and queue inputs row by row without building a report (pre-upload gate)", run: runValidateCommand},
+		{name: "serve", summary: "Run the HTTP API for uploads, stored runs, and the latest queue forecast", run: runServeCommand},
+		{name: "db", summary: "Manage the database and stored runs: db init | migrate | ingest | list | show | diff | series", run: runDBCommand},
+	}
+}
+
+// runCLI dispatches to a subcommand and returns the process exit code. Invocations
+// that start with a flag (or have no arguments) run the report command so older
+// scripts keep working.
+func runCLI(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") && !isHelpArg(args[0]) {
+		return runReportCommand(args)
+	}
+	if isHelpArg(args[0]) || args[0] == "help" {
+		printRootUsage(os.Stdout)
+		return exitOK
+	}
+	for _, cmd := range commands() {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:])
+		}
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+	printRootUsage(os.Stderr)
+	return exitUsage
+}
+
+func isHelpArg(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help"
+}
+
+func printRootUsage(out io.Writer) {
+	fmt.Fprintf(out, "Usage: %s <command> [flags]\n\nCommands:\n", programName())
+	for _, cmd := range commands() {
+		fmt.Fprintf(out, "  %-9s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(out, "\nRun '%s <command> -h' for command flags.\n", programName())
+	fmt.Fprintf(out, "\nCSV columns required: application_id, stage, submitted_at, reviewed_at, reviewer_id\n")
+	fmt.Fprintf(out, "Date formats accepted: RFC3339, YYYY-MM-DD, YYYY-MM-DD HH:MM:SS\n")
+	fmt.Fprintf(out, "\nExit codes: 0 success, 1 runtime failure, 2 usage error, 3 invalid input data\n")
+}
+
+func programName() string {
+	return "review-queue-forecaster"
+}
+
+func newFlagSet(name string, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s\n\n", programName(), usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses flags that may appear before or after positional arguments
+// and maps parse failures onto exit codes; ok is false when the caller should
+// return the code immediately.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, int, bool) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, exitOK, false
+			}
+			return nil, exitUsage, false
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, exitOK, true
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func usageError(fs *flag.FlagSet, format string, args ...any) int {
+	fmt.Fprintf(fs.Output(), format+"\n\n", args...)
+	fs.Usage()
+	return exitUsage
+}
+
+func unexpectedArgs(fs *flag.FlagSet, positional []string) int {
+	return usageError(fs, "unexpected arguments: %s", strings.Join(positional, " "))
+}
+
+func fail(format string, args ...any) int {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	return exitFailure
+}
+
+// failLoad reports an error from loading or analyzing the inputs, exiting with
+// exitInvalidInput when the input data itself was at fault.
+func failLoad(err error) int {
+	if errors.Is(err, ingest.ErrInvalidData) {
+		fmt.Fprintln(os.Stderr, err)
+		return exitInvalidInput
+	}
+	return fail("%v", err)
+}
+
+// modelOptions holds the flags that shape the analytics: SLA policy, clock,
+// throughput window, clearance target, and simulations.
+type modelOptions struct {
+	slaDays          int
+	slaClock         string
+	weekend          string
+	holidaysPath     string
+	timezone         string
+	throughputDays   int
+	dueSoonRatio     float64
+	slaPolicyPath    string
+	targetClearDays  int
+	queuePriorityTop int
+	simulations      int
+	simulationSeed   int64
+	journeys         bool
+	stageOrder       string
+	journeyTop       int
+	arrivals         bool
+}
+
+func registerModelFlags(fs *flag.FlagSet) *modelOptions {
+	opts := &modelOptions{}
+	fs.IntVar(&opts.slaDays, "sla-days", 10, "SLA threshold in days")
+	fs.StringVar(&opts.slaClock, "sla-clock", "calendar", "Day counting for latency, age, and SLA math: calendar or business")
+	fs.StringVar(&opts.weekend, "weekend", "sat,sun", "Comma-separated weekend days skipped by the business clock (or none)")
+	fs.StringVar(&opts.holidaysPath, "holidays", "", "Holiday calendar (ICS or CSV with date,name) skipped by the business clock")
+	registerTimezoneFlag(fs, &opts.timezone)
+	fs.IntVar(&opts.throughputDays, "throughput-days", 28, "Window in days for throughput metrics")
+	fs.Float64Var(&opts.dueSoonRatio, "due-soon-ratio", 0.8, "Fraction of SLA days considered due soon")
+	fs.StringVar(&opts.slaPolicyPath, "sla-policy", "", "JSON SLA policy mapping stage, program, and priority to SLA days and due-soon ratios")
+	fs.IntVar(&opts.targetClearDays, "target-clear-days", 14, "Target days to clear the pending queue for capacity planning")
+	fs.IntVar(&opts.queuePriorityTop, "queue-priority-top", 10, "Top queue items to show in priority list")
+	fs.IntVar(&opts.simulations, "simulations", 0, "Monte Carlo trials for queue clearance forecasts (0 disables)")
+	fs.Int64Var(&opts.simulationSeed, "simulation-seed", 1, "Random seed for Monte Carlo clearance forecasts")
+	fs.BoolVar(&opts.arrivals, "arrivals", false, "Forecast new submissions per stage and add them to clearance estimates and simulations")
+	fs.BoolVar(&opts.journeys, "journeys", false, "Stitch events and queue items by application_id into end-to-end journeys")
+	fs.StringVar(&opts.stageOrder, "stage-order", "", "Comma-separated pipeline stage order for journeys (unlisted stages are inferred)")
+	fs.IntVar(&opts.journeyTop, "journey-top", 10, "Slowest journeys to list")
+	return opts
+}
+
+func registerTimezoneFlag(fs *flag.FlagSet, timezone *string) {
+	fs.StringVar(timezone, "timezone", "UTC", "IANA timezone for timestamps without an offset, window and day boundaries, and business days")
+}
+
+// options loads the SLA policy and calendar and returns the forecast options.
+func (opts *modelOptions) options() (forecast.Options, error) {
+	cal, err := forecast.NewCalendar(opts.slaClock, opts.timezone, opts.weekend, opts.holidaysPath)
+	if err != nil {
+		return forecast.Options{}, fmt.Errorf("failed to configure calendar: %w", err)
+	}
+	policy, err := forecast.LoadSLAPolicy(opts.slaPolicyPath, opts.slaDays, opts.dueSoonRatio)
+	if err != nil {
+		return forecast.Options{}, fmt.Errorf("failed to load sla policy: %w", err)
+	}
+	return forecast.Options{
+		Policy:          policy,
+		Calendar:        cal,
+		ThroughputDays:  opts.throughputDays,
+		TargetClearDays: opts.targetClearDays,
+		PriorityTop:     opts.queuePriorityTop,
+		Simulations:     opts.simulations,
+		SimulationSeed:  opts.simulationSeed,
+		Journeys:        opts.journeys,
+		StageOrder:      strings.Split(opts.stageOrder, ","),
+		JourneyTop:      opts.journeyTop,
+		Arrivals:        opts.arrivals,
+	}, nil
+}
+
+type analysisOptions struct {
+	*modelOptions
+	db        *dbOptions
+	source    *sourceOptions
+	inputPath string
+	queuePath string
+	asOf      string
+	fromDB    bool
+	since     string
+	until     string
+	lenient   bool
+}
+
+func registerAnalysisFlags(fs *flag.FlagSet) *analysisOptions {
+	opts := &analysisOptions{modelOptions: registerModelFlags(fs), db: registerDBFlags(fs)}
+	opts.source = registerSourceFlags(fs, opts.db)
+	opts.source.timezone = &opts.timezone
+	fs.StringVar(&opts.inputPath, "input", "data/sample-events.csv", "Review events source: file path (CSV, JSON, or NDJSON), - for stdin, sql:<query>, or view:<name>")
+	fs.StringVar(&opts.queuePath, "queue", "", "Pending queue source: file path (CSV, JSON, or NDJSON), - for stdin, sql:<query>, or view:<name> (optional)")
+	fs.StringVar(&opts.asOf, "as-of", "", "As-of date for throughput window (defaults to latest reviewed_at)")
+	fs.BoolVar(&opts.fromDB, "from-db", false, "Read events and the queue snapshot from Postgres instead of --input/--queue")
+	fs.StringVar(&opts.since, "since", "", "With --from-db, only use events reviewed on or after this date")
+	fs.StringVar(&opts.until, "until", "", "With --from-db, only use events reviewed on or before this date, or before this timestamp (also the default --as-of)")
+	fs.BoolVar(&opts.lenient, "lenient", false, "Skip invalid --input/--queue rows instead of failing, and add a data-quality section to the report")
+	return opts
+}
+
+func (opts *analysisOptions) build(ctx context.Context) (forecast.Report, error) {
+	options, err := opts.options()
+	if err != nil {
+		return forecast.Report{}, err
+	}
+	if strings.TrimSpace(opts.asOf) != "" {
+		options.AsOf, err = ingest.ParseDateIn(opts.asOf, options.Calendar.Location)
+		if err != nil {
+			return forecast.Report{}, fmt.Errorf("invalid --as-of: %w", err)
+		}
+	}
+	if opts.fromDB {
+		if opts.lenient {
+			return forecast.Report{}, errors.New("--lenient applies to --input and --queue, not --from-db")
+		}
+		events, queueItems, err := opts.loadDatabase(&options)
+		if err != nil {
+			return forecast.Report{}, err
+		}
+		report, err := forecast.BuildReport(ctx, events, queueItems, options)
+		if err != nil {
+			return forecast.Report{}, fmt.Errorf("failed to build report: %w", err)
+		}
+		return report, nil
+	}
+
+	// File and query sources are streamed into the aggregator, so the event
+	// history never has to fit in memory at once.
+	aggregator := forecast.NewAggregator(options)
+	if opts.lenient {
+		opts.source.quality = ingest.NewQuality(time.Now(), options.Policy.RuleStages())
+	}
+	queueItems, err := opts.stream(ctx, aggregator.Add)
+	if err != nil {
+		return forecast.Report{}, err
+	}
+	report, err := aggregator.Report(ctx, queueItems)
+	if err != nil {
+		return forecast.Report{}, fmt.Errorf("failed to build report: %w", err)
+	}
+	report.DataQuality = opts.source.quality.Report()
+	return report, nil
+}
+
+func (opts *analysisOptions) loadDatabase(options *forecast.Options) ([]forecast.ReviewEvent, []forecast.QueueItem, error) {
+	var recordRange store.RecordRange
+	var err error
+	recordRange.Since, recordRange.Until, err = parseDateBounds(opts.since, opts.until, options.Calendar.Location)
+	if err != nil {
+		return nil, nil, err
+	}
+	if options.AsOf.IsZero() {
+		options.AsOf = recordRange.Until
+	}
+	events, queueItems, err := loadDatabaseRecords(opts.db.url, opts.db.schema, recordRange, options.AsOf)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load records from database: %w", err)
+	}
+	if len(events) == 0 {
+		return nil, nil, errors.New("no stored review events in the requested range")
+	}
+	return events, queueItems, nil
+}
+
+// stream feeds the --input events to add and loads the --queue items.
+func (opts *analysisOptions) stream(ctx context.Context, add func(forecast.ReviewEvent)) ([]forecast.QueueItem, error) {
+	if strings.TrimSpace(opts.since) != "" || strings.TrimSpace(opts.until) != "" {
+		return nil, errors.New("--since and --until require --from-db")
+	}
+	if strings.TrimSpace(opts.inputPath) == ingest.StdinPath && strings.TrimSpace(opts.queuePath) == ingest.StdinPath {
+		return nil, errors.New("--input and --queue cannot both read stdin")
+	}
+	eventSource, err := opts.source.open(opts.inputPath, opts.source.inputMap, opts.source.inputFormat)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --input: %w", err)
+	}
+	err = eventSource.StreamEvents(ctx, func(event forecast.ReviewEvent) error {
+		add(event)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load events: %w", err)
+	}
+	var queueItems []forecast.QueueItem
+	if strings.TrimSpace(opts.queuePath) != "" {
+		queueSource, err := opts.source.open(opts.queuePath, opts.source.queueMap, opts.source.queueFormat)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --queue: %w", err)
+		}
+		queueItems, err = queueSource.QueueItems(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load queue: %w", err)
+		}
+	}
+	return queueItems, nil
+}
+
+// sources labels the event and queue inputs for stored runs.
+func (opts *analysisOptions) sources() (string, string) {
+	if opts.fromDB {
+		return "db:review_events", "db:queue_snapshots"
+	}
+	label := func(path string) string {
+		if strings.TrimSpace(path) == ingest.StdinPath {
+			return "stdin"
+		}
+		return path
+	}
+	return label(opts.inputPath), label(opts.queuePath)
+}
+
+// parseDateBounds parses optional --since and --until values, reading dates
+// without an offset in location. The until bound comes back exclusive, so a
+// bare date keeps everything through the end of that day.
+func parseDateBounds(since string, until string, location *time.Location) (time.Time, time.Time, error) {
+	var bounds [2]time.Time
+	for i, bound := range []struct {
+		name  string
+		value string
+		parse func(string, *time.Location) (time.Time, error)
+	}{{"since", since, ingest.ParseDateIn}, {"until", until, ingest.ParseUntilIn}} {
+		if strings.TrimSpace(bound.value) == "" {
+			continue
+		}
+		parsed, err := bound.parse(bound.value, location)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --%s: %w", bound.name, err)
+		}
+		bounds[i] = parsed
+	}
+	if !bounds[0].IsZero() && !bounds[1].IsZero() && !bounds[1].After(bounds[0]) {
+		return time.Time{}, time.Time{}, errors.New("--until is before --since")
+	}
+	return bounds[0], bounds[1], nil
+}
+
+type outputOptions struct {
+	csvOut         string
+	briefOut       string
+	projectionsOut string
+	htmlOut        string
+}
+
+func registerOutputFlags(fs *flag.FlagSet) *outputOptions {
+	opts := &outputOptions{}
+	fs.StringVar(&opts.csvOut, "csv-out", "", "Write CSV summaries using this path prefix or directory")
+	fs.StringVar(&opts.briefOut, "brief-out", "", "Write a markdown ops brief to this path or directory")
+	fs.StringVar(&opts.projectionsOut, "projections-out", "", "Write per-item projected review dates to this CSV or .json path")
+	fs.StringVar(&opts.htmlOut, "html-out", "", "Write a self-contained HTML report with charts to this path or directory")
+	return opts
+}
+
+func (opts *outputOptions) any() bool {
+	return strings.TrimSpace(opts.csvOut) != "" || strings.TrimSpace(opts.briefOut) != "" || strings.TrimSpace(opts.projectionsOut) != "" ||
+		strings.TrimSpace(opts.htmlOut) != ""
+}
+
+func (opts *outputOptions) write(report forecast.Report) error {
+	if strings.TrimSpace(opts.csvOut) != "" {
+		if err := export.WriteCSV(report, opts.csvOut); err != nil {
+			return fmt.Errorf("failed to write csv output: %w", err)
+		}
+	}
+	if strings.TrimSpace(opts.briefOut) != "" {
+		if err := export.WriteBrief(report, opts.briefOut); err != nil {
+			return fmt.Errorf("failed to write brief output: %w", err)
+		}
+	}
+	if strings.TrimSpace(opts.projectionsOut) != "" {
+		if err := export.WriteProjections(report, opts.projectionsOut); err != nil {
+			return fmt.Errorf("failed to write projections output: %w", err)
+		}
+	}
+	if strings.TrimSpace(opts.htmlOut) != "" {
+		if err := export.WriteHTML(report, opts.htmlOut); err != nil {
+			return fmt.Errorf("failed to write html output: %w", err)
+		}
+	}
+	return nil
+}
+
+type sourceOptions struct {
+	db          *dbOptions
+	url         string
+	inputMap    string
+	queueMap    string
+	inputFormat string
+	queueFormat string
+	dialectPath string
+	// timezone points at the --timezone value, which applies to timestamps
+	// without an offset unless the ingest config sets its own.
+	timezone *string
+	// quality is set for --lenient loads.
+	quality *ingest.Quality
+}
+
+// registerSourceFlags adds the connection and column mapping used when --input
+// or --queue name a SQL query or view. db supplies the fallback DSN.
+func registerSourceFlags(fs *flag.FlagSet, db *dbOptions) *sourceOptions {
+	opts := &sourceOptions{db: db}
+	fs.StringVar(&opts.url, "source-url", os.Getenv("GS_REVIEW_QUEUE_SOURCE_URL"), "Postgres connection string for sql: and view: sources (or GS_REVIEW_QUEUE_SOURCE_URL; defaults to the --db-url DSN)")
+	fs.StringVar(&opts.inputMap, "input-map", "", "Map event fields to source columns, e.g. application_id=app_id,reviewed_at=decided_at")
+	fs.StringVar(&opts.queueMap, "queue-map", "", "Map queue fields to source columns, e.g. submitted_at=received_at")
+	fs.StringVar(&opts.inputFormat, "input-format", "auto", "Events file format: auto, csv, json, or ndjson")
+	fs.StringVar(&opts.queueFormat, "queue-format", "auto", "Queue file format: auto, csv, json, or ndjson")
+	registerDialectFlag(fs, &opts.dialectPath)
+	return opts
+}
+
+func registerDialectFlag(fs *flag.FlagSet, path *string) {
+	fs.StringVar(path, "ingest-config", "", "JSON ingest config with header aliases, delimiter, quote mode, and encoding for event and queue files")
+}
+
+// open resolves a source spec with the given column mapping and file format.
+func (opts *sourceOptions) open(spec string, mapping string, format string) (ingest.Source, error) {
+	columns, err := ingest.ParseMapping(mapping)
+	if err != nil {
+		return nil, err
+	}
+	dialect, err := ingest.LoadDialect(opts.dialectPath)
+	if err != nil {
+		return nil, err
+	}
+	if dialect.Timezone == "" && opts.timezone != nil {
+		dialect.Timezone = *opts.timezone
+	}
+	sourceOpts := ingest.SourceOptions{DSN: strings.TrimSpace(opts.url), Mapping: columns, Dialect: dialect, Format: format, Quality: opts.quality}
+	if ingest.IsSQLSpec(spec) && sourceOpts.DSN == "" {
+		dbURL := ""
+		if opts.db != nil {
+			dbURL = opts.db.url
+		}
+		cfg, err := store.ResolveConfig(dbURL, "")
+		if err != nil {
+			return nil, fmt.Errorf("source DSN missing: set --source-url or GS_REVIEW_QUEUE_SOURCE_URL (%v)", err)
+		}
+		sourceOpts.DSN = cfg.DSN
+	}
+	return ingest.OpenSource(spec, sourceOpts)
+}
+
+type dbOptions struct {
+	url    string
+	schema string
+}
+
+func registerDBFlags(fs *flag.FlagSet) *dbOptions {
+	opts := &dbOptions{}
+	fs.StringVar(&opts.url, "db-url", "", "Postgres connection string (or GS_REVIEW_QUEUE_DB_URL env var)")
+	fs.StringVar(&opts.schema, "db-schema", "gs_review_queue_forecaster", "Database schema for stored runs")
+	return opts
+}
+
+type reportOptions struct {
+	jsonOutput  bool
+	reviewerTop int
+	storeDB     bool
+}
+
+func registerReportFlags(fs *flag.FlagSet) *reportOptions {
+	opts := &reportOptions{}
+	fs.BoolVar(&opts.jsonOutput, "json", false, "Emit JSON output")
+	fs.IntVar(&opts.reviewerTop, "reviewer-top", 5, "Top reviewers to show by throughput")
+	fs.BoolVar(&opts.storeDB, "store-db", false, "Store report in Postgres when DB url is available")
+	return opts
+}
+
+// parseConfiguredFlags parses the command line and then layers the config file and
+// profile underneath it.
+func parseConfiguredFlags(fs *flag.FlagSet, config *configOptions, args []string) (int, bool) {
+	positional, code, ok := parseFlags(fs, args)
+	if !ok {
+		return code, false
+	}
+	if len(positional) > 0 {
+		return unexpectedArgs(fs, positional), false
+	}
+	if err := config.apply(fs); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+func runReportCommand(args []string) int {
+	fs := newFlagSet("report", "report [flags]")
+	config := registerConfigFlags(fs)
+	analysis := registerAnalysisFlags(fs)
+	outputs := registerOutputFlags(fs)
+	reportOpts := registerReportFlags(fs)
+	if code, ok := parseConfiguredFlags(fs, config, args); !ok {
+		return code
+	}
+
+	report, err := analysis.build(context.Background())
+	if err != nil {
+		return failLoad(err)
+	}
+	report.RunConfig = config.runConfig(fs)
+	if err := outputs.write(report); err != nil {
+		return fail("%v", err)
+	}
+	if reportOpts.storeDB {
+		inputPath, queuePath := analysis.sources()
+		if err := saveReportToDB(analysis.db.url, analysis.db.schema, report, inputPath, queuePath); err != nil {
+			return fail("failed to store report: %v", err)
+		}
+	}
+	if reportOpts.jsonOutput {
+		return printJSON(report)
+	}
+	export.WriteReport(os.Stdout, report, reportOpts.reviewerTop)
+	return exitOK
+}
+
+func runForecastCommand(args []string) int {
+	fs := newFlagSet("forecast", "forecast --queue <path> [flags]")
+	config := registerConfigFlags(fs)
+	analysis := registerAnalysisFlags(fs)
+	jsonOutput := fs.Bool("json", false, "Emit the queue forecast as JSON")
+	if code, ok := parseConfiguredFlags(fs, config, args); !ok {
+		return code
+	}
+	if strings.TrimSpace(analysis.queuePath) == "" {
+		return usageError(fs, "forecast requires --queue")
+	}
+
+	report, err := analysis.build(context.Background())
+	if err != nil {
+		return failLoad(err)
+	}
+	if *jsonOutput {
+		return printJSON(report.Queue)
+	}
+	export.WriteQueueForecast(os.Stdout, report.Queue)
+	return exitOK
+}
+
+func runExportCommand(args []string) int {
+	fs := newFlagSet("export", "export --csv-out <prefix> | --brief-out <path> | --projections-out <path> | --html-out <path> [flags]")
+	config := registerConfigFlags(fs)
+	analysis := registerAnalysisFlags(fs)
+	outputs := registerOutputFlags(fs)
+	if code, ok := parseConfiguredFlags(fs, config, args); !ok {
+		return code
+	}
+	if !outputs.any() {
+		return usageError(fs, "export requires at least one of --csv-out, --brief-out, --projections-out, or --html-out")
+	}
+
+	report, err := analysis.build(context.Background())
+	if err != nil {
+		return failLoad(err)
+	}
+	report.RunConfig = config.runConfig(fs)
+	if err := outputs.write(report); err != nil {
+		return fail("%v", err)
+	}
+	fmt.Println("Exports written.")
+	return exitOK
+}
+
+func runBacktestCommand(args []string) int {
+	fs := newFlagSet("backtest", "backtest [--start <date>] [--as-of <date>] [--every N] | backtest --runs [--limit N] [flags]")
+	config := registerConfigFlags(fs)
+	analysis := registerAnalysisFlags(fs)
+	start := fs.String("start", "", "First as-of date to replay (defaults to one throughput window after the first review)")
+	defaults := forecast.DefaultBacktestOptions()
+	every := fs.Int("every", defaults.EveryDays, "Days between replayed as-of dates")
+	settleDays := fs.Int("settle-days", defaults.SettleDays, "Without --as-of, stop this many days before the last review so pending items can finish")
+	runs := fs.Bool("runs", false, "Score the queue forecasts of stored runs against later stored runs instead of replaying events")
+	limit := fs.Int("limit", 20, fmt.Sprintf("With --runs, number of most recent runs to score (at most %d)", maxBacktestRuns))
+	runProfile := fs.String("run-profile", "", "With --runs, only score runs stored under this config profile")
+	jsonOutput := fs.Bool("json", false, "Print the backtest as JSON")
+	csvOut := fs.String("csv-out", "", "Write accuracy and per-date CSVs using this path prefix or directory")
+	if code, ok := parseConfiguredFlags(fs, config, args); !ok {
+		return code
+	}
+	if *every <= 0 || *settleDays < 0 {
+		return usageError(fs, "--every must be positive and --settle-days zero or more")
+	}
+	if *limit <= 0 || *limit > maxBacktestRuns {
+		return usageError(fs, "--limit must be between 1 and %d", maxBacktestRuns)
+	}
+
+	var result forecast.BacktestReport
+	if *runs {
+		stored, err := loadBacktestRuns(analysis.db.url, analysis.db.schema, store.RunFilter{Limit: *limit, Profile: *runProfile})
+		if err != nil {
+			return fail("failed to load stored runs: %v", err)
+		}
+		result = forecast.BacktestStoredRuns(stored)
+	} else {
+		if analysis.lenient {
+			return usageError(fs, "--lenient is not supported by backtest; run validate to find bad rows")
+		}
+		options, err := analysis.options()
+		if err != nil {
+			return fail("%v", err)
+		}
+		backtestOpts := forecast.BacktestOptions{EveryDays: *every, SettleDays: *settleDays}
+		for _, bound := range []struct {
+			name  string
+			value string
+			into  *time.Time
+		}{{"start", *start, &backtestOpts.Start}, {"as-of", analysis.asOf, &backtestOpts.End}} {
+			if strings.TrimSpace(bound.value) == "" {
+				continue
+			}
+			*bound.into, err = ingest.ParseDateIn(bound.value, options.Calendar.Location)
+			if err != nil {
+				return usageError(fs, "invalid --%s: %v", bound.name, err)
+			}
+		}
+		// Every as-of date rescans the whole history, so replay holds all of
+		// the events in memory rather than streaming them.
+		ctx := context.Background()
+		var events []forecast.ReviewEvent
+		var queueItems []forecast.QueueItem
+		if analysis.fromDB {
+			events, queueItems, err = analysis.loadDatabase(&options)
+		} else {
+			queueItems, err = analysis.stream(ctx, func(event forecast.ReviewEvent) {
+				events = append(events, event)
+			})
+		}
+		if err != nil {
+			return failLoad(err)
+		}
+		result, err = forecast.Backtest(ctx, events, queueItems, options, backtestOpts)
+		if err != nil {
+			return fail("failed to backtest: %v", err)
+		}
+	}
+
+	if strings.TrimSpace(*csvOut) != "" {
+		if err := export.WriteBacktestCSV(result, *csvOut); err != nil {
+			return fail("failed to write backtest csv: %v", err)
+		}
+	}
+	if *jsonOutput {
+		return printJSON(result)
+	}
+	export.WriteBacktest(os.Stdout, result)
+	return exitOK
+}
+
+// validationResult is the machine-readable outcome of validate.
+type validationResult struct {
+	Valid              bool                `json:"valid"`
+	Errors             int                 `json:"errors"`
+	Warnings           int                 `json:"warnings"`
+	Inputs             []validationInput   `json:"inputs"`
+	Diagnostics        []ingest.Diagnostic `json:"diagnostics"`
+	DiagnosticsOmitted int                 `json:"diagnostics_omitted,omitempty"`
+}
+
+// validationInput summarizes one input. Error is set when the input could not
+// be read at all, such as a missing file or column.
+type validationInput struct {
+	forecast.DataQualityInput
+	Error string `json:"error,omitempty"`
+}
+
+func runValidateCommand(args []string) int {
+	fs := newFlagSet("validate", "validate --input <events> [--queue <queue>] [--json]")
+	inputPath := fs.String("input", "data/sample-events.csv", "Review events source: file path (CSV, JSON, or NDJSON), - for stdin, sql:<query>, or view:<name>")
+	queuePath := fs.String("queue", "", "Pending queue source: file path (CSV, JSON, or NDJSON), - for stdin, sql:<query>, or view:<name> (optional)")
+	slaPolicyPath := fs.String("sla-policy", "", "JSON SLA policy; stages it does not name are flagged as unknown")
+	jsonOut := fs.Bool("json", false, "Print the validation result as JSON")
+	maxDiagnostics := fs.Int("max-diagnostics", 200, "Most per-row diagnostics to list (counts always cover every row)")
+	sources := registerSourceFlags(fs, registerDBFlags(fs))
+	var timezone string
+	registerTimezoneFlag(fs, &timezone)
+	sources.timezone = &timezone
+	positional, code, ok := parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	if len(positional) > 0 {
+		return unexpectedArgs(fs, positional)
+	}
+	if strings.TrimSpace(*inputPath) == ingest.StdinPath && strings.TrimSpace(*queuePath) == ingest.StdinPath {
+		return usageError(fs, "--input and --queue cannot both read stdin")
+	}
+	if *maxDiagnostics < 0 {
+		return usageError(fs, "--max-diagnostics must be zero or more")
+	}
+	policy, err := forecast.LoadSLAPolicy(*slaPolicyPath, 10, 0.8)
+	if err != nil {
+		return usageError(fs, "failed to load sla policy: %v", err)
+	}
+
+	ctx := context.Background()
+	code = exitOK
+	quality := ingest.NewQuality(time.Now(), policy.RuleStages())
+	quality.MaxDiagnostics = *maxDiagnostics
+	sources.quality = quality
+	result := validationResult{}
+	check := func(label string, spec string, mapping string, format string, load func(ingest.Source) error) {
+		input := validationInput{DataQualityInput: forecast.DataQualityInput{Input: label, Source: spec, Issues: []forecast.DataQualityIssue{}}}
+		defer func() { result.Inputs = append(result.Inputs, input) }()
+		source, err := sources.open(spec, mapping, format)
+		if err != nil {
+			input.Error = err.Error()
+			code = exitUsage
+			return
+		}
+		input.Source = source.Name()
+		if !ingest.IsSQLSpec(spec) && strings.TrimSpace(spec) != ingest.StdinPath {
+			if _, err := os.Stat(spec); err != nil {
+				input.Error = err.Error()
+				code = exitFailure
+				return
+			}
+		}
+		screened := len(quality.Report())
+		err = load(source)
+		if report := quality.Report(); len(report) > screened {
+			input.DataQualityInput = report[screened]
+		}
+		if err != nil {
+			input.Error = err.Error()
+			if code == exitOK {
+				code = exitInvalidInput
+			}
+		}
+	}
+	check("events", *inputPath, sources.inputMap, sources.inputFormat, func(source ingest.Source) error {
+		return source.StreamEvents(ctx, func(forecast.ReviewEvent) error { return nil })
+	})
+	if strings.TrimSpace(*queuePath) != "" {
+		check("queue", *queuePath, sources.queueMap, sources.queueFormat, func(source ingest.Source) error {
+			_, err := source.QueueItems(ctx)
+			return err
+		})
+	}
+
+	for _, input := range result.Inputs {
+		result.Errors += input.RowsSkipped
+		for _, issue := range input.Issues {
+			if issue.Action == "kept" {
+				result.Warnings += issue.Count
+			}
+		}
+	}
+	result.Diagnostics, result.DiagnosticsOmitted = quality.Diagnostics()
+	if result.Diagnostics == nil {
+		result.Diagnostics = []ingest.Diagnostic{}
+	}
+	if result.Errors > 0 && code == exitOK {
+		code = exitInvalidInput
+	}
+	result.Valid = code == exitOK
+
+	if *jsonOut {
+		if printJSON(result) != exitOK {
+			return exitFailure
+		}
+		return code
+	}
+	writeValidation(os.Stdout, result)
+	return code
+}
+
+// writeValidation prints each input's counts and a table of row diagnostics.
+func writeValidation(w io.Writer, result validationResult) {
+	for _, input := range result.Inputs {
+		if input.Error != "" {
+			fmt.Fprintf(w, "%s %s: invalid: %s\n", input.Input, input.Source, input.Error)
+			continue
+		}
+		warnings := 0
+		for _, issue := range input.Issues {
+			if issue.Action == "kept" {
+				warnings += issue.Count
+			}
+		}
+		fmt.Fprintf(w, "%s %s: %d rows | %d OK | %d errors | %d warnings\n",
+			input.Input, input.Source, input.RowsRead, input.RowsAccepted, input.RowsSkipped, warnings)
+	}
+	if len(result.Diagnostics) > 0 {
+		fmt.Fprintln(w)
+		table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "INPUT\tROW\tSEVERITY\tISSUE\tMESSAGE")
+		for _, diagnostic := range result.Diagnostics {
+			fmt.Fprintf(table, "%s\t%d\t%s\t%s\t%s\n", diagnostic.Input, diagnostic.Row, diagnostic.Severity, diagnostic.Issue, diagnostic.Message)
+		}
+		table.Flush()
+		if result.DiagnosticsOmitted > 0 {
+			fmt.Fprintf(w, "... %d more (raise --max-diagnostics to list them)\n", result.DiagnosticsOmitted)
+		}
+	}
+	status := "valid"
+	if !result.Valid {
+		status = "invalid"
+	}
+	fmt.Fprintf(w, "\nResult: %s (%d errors, %d warnings)\n", status, result.Errors, result.Warnings)
+}
+
+func runDBCommand(args []string) int {
+	if len(args) == 0 || isHelpArg(args[0]) {
+		printDBUsage(os.Stderr)
+		if len(args) > 0 {
+			return exitOK
+		}
+		return exitUsage
+	}
+	switch args[0] {
+	case "init":
+		return runDBInitCommand(args[1:])
+	case "list":
+		return runDBListCommand(args[1:])
+	case "show":
+		return runDBShowCommand(args[1:])
+	case "diff":
+		return runDBDiffCommand(args[1:])
+	case "migrate":
+		return runDBMigrateCommand(args[1:])
+	case "series":
+		return runDBSeriesCommand(args[1:])
+	case "ingest":
+		return runDBIngestCommand(args[1:])
+	}
+	fmt.Fprintf(os.Stderr, "unknown db command %q\n\n", args[0])
+	printDBUsage(os.Stderr)
+	return exitUsage
+}
+
+func printDBUsage(out io.Writer) {
+	fmt.Fprintf(out, "Usage: %s db <command> [flags]\n\nCommands:\n", programName())
+	fmt.Fprintf(out, "  init      Apply migrations and seed a sample run if empty\n")
+	fmt.Fprintf(out, "  migrate   Apply pending schema migrations (up) or list them (status)\n")
+	fmt.Fprintf(out, "  ingest    Upsert review events and a queue snapshot into Postgres\n")
+	fmt.Fprintf(out, "  list      List recent stored runs\n")
+	fmt.Fprintf(out, "  show      Re-render or re-export a stored run by id\n")
+	fmt.Fprintf(out, "  diff      Compare two stored runs (default: latest vs previous)\n")
+	fmt.Fprintf(out, "  series    Print or export a stage, reviewer, or queue metric across runs\n")
+}
+
+func runDBInitCommand(args []string) int {
+	fs := newFlagSet("db init", "db init [flags]")
+	dbOpts := registerDBFlags(fs)
+	positional, code, ok := parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	if len(positional) > 0 {
+		return unexpectedArgs(fs, positional)
+	}
+	if err := initDatabase(dbOpts.url, dbOpts.schema); err != nil {
+		return fail("failed to init database: %v", err)
+	}
+	fmt.Println("Database initialized and seed data verified.")
+	return exitOK
+}
+
+func runDBListCommand(args []string) int {
+	fs := newFlagSet("db list", "db list [--limit N] [flags]")
+	dbOpts := registerDBFlags(fs)
+	limit := fs.Int("limit", 5, "Number of recent runs to list")
+	positional, code, ok := parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	if len(positional) > 0 {
+		*limit = parseLimit(positional[0])
+	}
+	if err := listDatabaseRuns(dbOpts.url, dbOpts.schema, *limit); err != nil {
+		return fail("failed to list database runs: %v", err)
+	}
+	return exitOK
+}
+
+func runDBShowCommand(args []string) int {
+	fs := newFlagSet("db show", "db show [flags] <run-id | latest>")
+	dbOpts := registerDBFlags(fs)
+	outputs := registerOutputFlags(fs)
+	jsonOutput := fs.Bool("json", false, "Print the stored report JSON")
+	reviewerTop := fs.Int("reviewer-top", 5, "Top reviewers to show by throughput")
+	positional, code, ok := parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	if len(positional) != 1 {
+		return usageError(fs, "db show requires exactly one run id")
+	}
+	var id int64
+	if positional[0] != "latest" {
+		parsed, err := strconv.ParseInt(positional[0], 10, 64)
+		if err != nil || parsed <= 0 {
+			return usageError(fs, "invalid run id %q", positional[0])
+		}
+		id = parsed
+	}
+
+	run, report, err := loadDatabaseRun(dbOpts.url, dbOpts.schema, id)
+	if err != nil {
+		return fail("failed to show database run: %v", err)
+	}
+	if err := outputs.write(report); err != nil {
+		return fail("%v", err)
+	}
+	if *jsonOutput {
+		if err := printStoredReportJSON(run); err != nil {
+			return fail("failed to print stored report: %v", err)
+		}
+		return exitOK
+	}
+	if outputs.any() {
+		fmt.Printf("Exports written for run #%d.\n", run.ID)
+		return exitOK
+	}
+	printRunHeader(os.Stdout, run)
+	export.WriteReport(os.Stdout, report, *reviewerTop)
+	return exitOK
+}
+
+func runServeCommand(args []string) int {
+	fs := newFlagSet("serve", "serve [--addr :8080] [flags]")
+	config := registerConfigFlags(fs)
+	model := registerModelFlags(fs)
+	dbOpts := registerDBFlags(fs)
+	var dialectPath string
+	registerDialectFlag(fs, &dialectPath)
+	addr := fs.String("addr", ":8080", "Address to listen on")
+	if code, ok := parseConfiguredFlags(fs, config, args); !ok {
+		return code
+	}
+
+	options, err := model.options()
+	if err != nil {
+		return fail("%v", err)
+	}
+	dialect, err := ingest.LoadDialect(dialectPath)
+	if err != nil {
+		return fail("failed to load ingest config: %v", err)
+	}
+	if dialect.Timezone == "" {
+		dialect.Timezone = model.timezone
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var st *store.Store
+	if cfg, err := store.ResolveConfig(dbOpts.url, dbOpts.schema); err != nil {
+		log.Printf("stored-run endpoints disabled: %v", err)
+	} else {
+		st, err = store.Open(ctx, cfg)
+		if err != nil {
+			return fail("failed to open database: %v", err)
+		}
+		defer st.Close()
+	}
+
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           server.New(server.Config{Options: options, Store: st, Dialect: dialect}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errs := make(chan error, 1)
+	go func() {
+		log.Printf("listening on %s", *addr)
+		errs <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return fail("server stopped: %v", err)
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fail("shutdown failed: %v", err)
+	}
+	return exitOK
+}
+
+func runDBMigrateCommand(args []string) int {
+	fs := newFlagSet("db migrate", "db migrate [flags] <up | status>")
+	dbOpts := registerDBFlags(fs)
+	positional, code, ok := parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	if len(positional) != 1 {
+		return usageError(fs, "db migrate requires up or status")
+	}
+	switch positional[0] {
+	case "up":
+		if err := migrateDatabase(dbOpts.url, dbOpts.schema); err != nil {
+			return fail("failed to migrate database: %v", err)
+		}
+	case "status":
+		if err := printMigrationStatus(dbOpts.url, dbOpts.schema); err != nil {
+			return fail("failed to read migration status: %v", err)
+		}
+	default:
+		return usageError(fs, "unknown migrate command %q", positional[0])
+	}
+	return exitOK
+}
+
+func runDBIngestCommand(args []string) int {
+	fs := newFlagSet("db ingest", "db ingest [--input <events>] [--queue <queue>] [--captured-at <time>] [flags]")
+	dbOpts := registerDBFlags(fs)
+	sources := registerSourceFlags(fs, dbOpts)
+	var timezone string
+	registerTimezoneFlag(fs, &timezone)
+	sources.timezone = &timezone
+	inputPath := fs.String("input", "", "Review events to upsert into review_events (file, - for stdin, sql:<query>, or view:<name>)")
+	queuePath := fs.String("queue", "", "Pending queue to record as a queue snapshot (file, - for stdin, sql:<query>, or view:<name>)")
+	capturedAt := fs.String("captured-at", "", "When the queue snapshot was taken (defaults to now)")
+	positional, code, ok := parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	if len(positional) > 0 {
+		return unexpectedArgs(fs, positional)
+	}
+	if strings.TrimSpace(*inputPath) == "" && strings.TrimSpace(*queuePath) == "" {
+		return usageError(fs, "db ingest requires --input, --queue, or both")
+	}
+	location, err := time.LoadLocation(strings.TrimSpace(timezone))
+	if err != nil {
+		return usageError(fs, "invalid --timezone: %v", err)
+	}
+	captured := time.Now().UTC().Truncate(time.Second)
+	if strings.TrimSpace(*capturedAt) != "" {
+		parsed, err := ingest.ParseDateIn(*capturedAt, location)
+		if err != nil {
+			return usageError(fs, "invalid --captured-at: %v", err)
+		}
+		captured = parsed
+	}
+	if strings.TrimSpace(*inputPath) == ingest.StdinPath && strings.TrimSpace(*queuePath) == ingest.StdinPath {
+		return usageError(fs, "--input and --queue cannot both read stdin")
+	}
+
+	ctx := context.Background()
+	var events []forecast.ReviewEvent
+	var eventsSource, queueSource string
+	if strings.TrimSpace(*inputPath) != "" {
+		source, err := sources.open(*inputPath, sources.inputMap, sources.inputFormat)
+		if err != nil {
+			return usageError(fs, "invalid --input: %v", err)
+		}
+		events, err = source.Events(ctx)
+		if err != nil {
+			return failLoad(fmt.Errorf("failed to load events: %w", err))
+		}
+		eventsSource = source.Name()
+	}
+	var queueItems []forecast.QueueItem
+	if strings.TrimSpace(*queuePath) != "" {
+		source, err := sources.open(*queuePath, sources.queueMap, sources.queueFormat)
+		if err != nil {
+			return usageError(fs, "invalid --queue: %v", err)
+		}
+		queueItems, err = source.QueueItems(ctx)
+		if err != nil {
+			return failLoad(fmt.Errorf("failed to load queue: %w", err))
+		}
+		queueSource = source.Name()
+	}
+	if err := ingestRecords(dbOpts.url, dbOpts.schema, events, eventsSource, queueItems, queueSource, captured); err != nil {
+		return fail("failed to ingest records: %v", err)
+	}
+	return exitOK
+}
+
+func runDBSeriesCommand(args []string) int {
+	fs := newFlagSet("db series", "db series --metric <name> [--stage <stage> | --reviewer <id> | --queue-stage <stage>] [flags]")
+	dbOpts := registerDBFlags(fs)
+	metric := fs.String("metric", "p90_days", "Metric column to chart")
+	stage := fs.String("stage", "", "Stage to chart from stage_metrics (default overall)")
+	reviewer := fs.String("reviewer", "", "Reviewer to chart from reviewer_metrics")
+	queueStage := fs.String("queue-stage", "", "Stage to chart from queue_stage_forecasts (use overall for the whole queue)")
+	limit := fs.Int("limit", 20, "Number of most recent runs to include")
+	since := fs.String("since", "", "Only include runs generated on or after this date")
+	until := fs.String("until", "", "Only include runs generated on or before this date, or before this timestamp")
+	jsonOutput := fs.Bool("json", false, "Print the series as JSON")
+	csvOut := fs.String("csv-out", "", "Write the series to this CSV path or directory")
+	positional, code, ok := parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	if len(positional) > 0 {
+		return unexpectedArgs(fs, positional)
+	}
+
+	query := store.SeriesQuery{Metric: *metric, Scope: store.ScopeStage, Key: *stage, Limit: *limit}
+	scopes := 0
+	for _, value := range []string{*stage, *reviewer, *queueStage} {
+		if strings.TrimSpace(value) != "" {
+			scopes++
+		}
+	}
+	if scopes > 1 {
+		return usageError(fs, "use only one of --stage, --reviewer, or --queue-stage")
+	}
+	if strings.TrimSpace(*reviewer) != "" {
+		query.Scope, query.Key = store.ScopeReviewer, *reviewer
+	}
+	if strings.TrimSpace(*queueStage) != "" {
+		query.Scope, query.Key = store.ScopeQueue, *queueStage
+	}
+	var err error
+	query.Since, query.Until, err = parseDateBounds(*since, *until, time.UTC)
+	if err != nil {
+		return usageError(fs, "%v", err)
+	}
+	if err := query.Validate(); err != nil {
+		return usageError(fs, "%v", err)
+	}
+
+	series, err := loadMetricSeries(dbOpts.url, dbOpts.schema, query)
+	if err != nil {
+		return fail("failed to load metric series: %v", err)
+	}
+	if strings.TrimSpace(*csvOut) != "" {
+		if err := export.WriteSeriesCSV(series, *csvOut); err != nil {
+			return fail("failed to write series csv: %v", err)
+		}
+	}
+	if *jsonOutput {
+		return printJSON(series)
+	}
+	export.WriteSeries(os.Stdout, series)
+	return exitOK
+}
+
+func runDBDiffCommand(args []string) int {
+	fs := newFlagSet("db diff", "db diff [flags] [latest | <target-id> | <base-id> <target-id>]")
+	dbOpts := registerDBFlags(fs)
+	jsonOutput := fs.Bool("json", false, "Print the diff as JSON")
+	markdown := fs.Bool("markdown", false, "Print the diff as markdown")
+	positional, code, ok := parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	if *jsonOutput && *markdown {
+		return usageError(fs, "--json and --markdown cannot be combined")
+	}
+	if len(positional) == 1 && positional[0] == "latest" {
+		positional = nil
+	}
+	if len(positional) > 2 {
+		return unexpectedArgs(fs, positional[2:])
+	}
+	ids := make([]int64, len(positional))
+	for i, value := range positional {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id <= 0 {
+			return usageError(fs, "invalid run id %q", value)
+		}
+		ids[i] = id
+	}
+
+	var baseID, targetID int64
+	switch len(ids) {
+	case 1:
+		targetID = ids[0]
+	case 2:
+		baseID, targetID = ids[0], ids[1]
+	}
+	diff, err := diffDatabaseRuns(dbOpts.url, dbOpts.schema, baseID, targetID)
+	if err != nil {
+		return fail("failed to diff database runs: %v", err)
+	}
+	switch {
+	case *jsonOutput:
+		return printJSON(diff)
+	case *markdown:
+		fmt.Print(export.BuildDiffMarkdown(diff))
+	default:
+		export.WriteDiff(os.Stdout, diff)
+	}
+	return exitOK
+}
+
+func printJSON(value any) int {
+	payload, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fail("failed to encode json: %v", err)
+	}
+	fmt.Println(string(payload))
+	return exitOK
+}
+
+func parseLimit(input string) int {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return 0
+	}
+	value, err := strconv.Atoi(input)
+	if err != nil {
+		return 0
+	}
+	return value
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+// ConfigFile holds flag values shared by every run plus named profiles. Keys are
+// flag names (underscores are accepted in place of hyphens).
+type ConfigFile struct {
+	Defaults map[string]any            `json:"defaults"`
+	Profiles map[string]map[string]any `json:"profiles"`
+}
+
+const (
+	sourceDefault = "default"
+	sourceConfig  = "config"
+	sourceProfile = "profile"
+	sourceFlag    = "flag"
+)
+
+type configOptions struct {
+	path    string
+	profile string
+	sources map[string]string
+}
+
+func registerConfigFlags(fs *flag.FlagSet) *configOptions {
+	opts := &configOptions{}
+	fs.StringVar(&opts.path, "config", os.Getenv("GS_REVIEW_QUEUE_CONFIG"), "JSON config file with defaults and named profiles (or GS_REVIEW_QUEUE_CONFIG env var)")
+	fs.StringVar(&opts.profile, "profile", "", "Named profile from the config file to apply")
+	return opts
+}
+
+func loadConfigFile(path string) (ConfigFile, error) {
+	payload, err := os.ReadFile(path)
+	if err != nil {
+		return ConfigFile{}, err
+	}
+	var file ConfigFile
+	if err := json.Unmarshal(payload, &file); err != nil {
+		return ConfigFile{}, fmt.Errorf("invalid config: %w", err)
+	}
+	return file, nil
+}
+
+// apply layers config defaults, then the selected profile, under any flag that
+// was set explicitly on the command line. String values expand $VAR and ${VAR}.
+func (opts *configOptions) apply(fs *flag.FlagSet) error {
+	opts.sources = map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		opts.sources[f.Name] = sourceFlag
+	})
+	path := strings.TrimSpace(opts.path)
+	profile := strings.TrimSpace(opts.profile)
+	if path == "" {
+		if profile != "" {
+			return errors.New("--profile requires --config")
+		}
+		return nil
+	}
+
+	file, err := loadConfigFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := opts.applyValues(fs, file.Defaults, sourceConfig); err != nil {
+		return err
+	}
+	if profile == "" {
+		return nil
+	}
+	values, ok := file.Profiles[profile]
+	if !ok {
+		return fmt.Errorf("profile %q not found in %s (available: %s)", profile, path, strings.Join(profileNames(file), ", "))
+	}
+	return opts.applyValues(fs, values, sourceProfile)
+}
+
+func (opts *configOptions) applyValues(fs *flag.FlagSet, values map[string]any, source string) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	known := knownConfigKeys()
+	for _, key := range keys {
+		name := strings.ReplaceAll(strings.TrimSpace(key), "_", "-")
+		if name == "config" || name == "profile" || !known[name] {
+			return fmt.Errorf("config %s: unknown setting %q", source, key)
+		}
+		if opts.sources[name] == sourceFlag || fs.Lookup(name) == nil {
+			continue
+		}
+		value, err := configValueString(values[key])
+		if err != nil {
+			return fmt.Errorf("config %s: %s: %w", source, key, err)
+		}
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("config %s: %s: %w", source, key, err)
+		}
+		opts.sources[name] = source
+	}
+	return nil
+}
+
+// credentialFlags are the flags that take a Postgres DSN, which may carry a
+// password. runConfig leaves them out.
+var credentialFlags = map[string]bool{
+	"db-url":     true,
+	"source-url": true,
+}
+
+// runConfig snapshots every flag value in effect for the command. Connection
+// strings are left out so credentials never land in stored reports.
+func (opts *configOptions) runConfig(fs *flag.FlagSet) *forecast.RunConfig {
+	config := &forecast.RunConfig{
+		ConfigPath: forecast.SanitizePath(opts.path),
+		Profile:    strings.TrimSpace(opts.profile),
+		Settings:   map[string]forecast.ConfigSetting{},
+	}
+	fs.VisitAll(func(f *flag.Flag) {
+		if f.Name == "config" || f.Name == "profile" || credentialFlags[f.Name] {
+			return
+		}
+		source := opts.sources[f.Name]
+		if source == "" {
+			source = sourceDefault
+		}
+		config.Settings[f.Name] = forecast.ConfigSetting{Value: f.Value.String(), Source: source}
+	})
+	return config
+}
+
+func configValueString(value any) (string, error) {
+	switch typed := value.(type) {
+	case string:
+		return os.ExpandEnv(typed), nil
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(typed), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v (use a string, number, or boolean)", value)
+	}
+}
+
+// knownConfigKeys lists every flag a config file may set across all commands, so a
+// profile shared by report and forecast runs can carry export or DB settings.
+func knownConfigKeys() map[string]bool {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	registerAnalysisFlags(fs)
+	registerOutputFlags(fs)
+	registerReportFlags(fs)
+	fs.String("addr", "", "")
+	known := map[string]bool{}
+	fs.VisitAll(func(f *flag.Flag) {
+		known[f.Name] = true
+	})
+	return known
+}
+
+func profileNames(file ConfigFile) []string {
+	names := make([]string, 0, len(file.Profiles))
+	for name := range file.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,135 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+// WriteBacktest renders forecast accuracy for the console: overall first, then
+// by stage and reviewer, followed by the error at each replayed date or run.
+func WriteBacktest(w io.Writer, result forecast.BacktestReport) {
+	fmt.Fprintf(w, "Forecast Backtest (%s)\n", result.Source)
+	switch result.Source {
+	case forecast.BacktestEvents:
+		fmt.Fprintf(w, "- As-of dates: %s to %s every %d days | throughput window %d days\n", result.Start, result.End, result.EveryDays, result.ThroughputDays)
+	default:
+		fmt.Fprintf(w, "- Runs: %d", len(result.Points))
+		if len(result.Points) > 0 {
+			fmt.Fprintf(w, " | queue as-of %s to %s", result.Start, result.End)
+		}
+		fmt.Fprintln(w)
+	}
+	if len(result.Accuracy) == 0 {
+		fmt.Fprintln(w, "- No forecasts could be scored yet; pending items have not been reviewed.")
+		return
+	}
+
+	for _, section := range []struct{ scope, title string }{
+		{"overall", "Overall"},
+		{"stage", "By Stage"},
+		{"reviewer", "By Reviewer"},
+	} {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, section.title)
+		for _, row := range result.Accuracy {
+			if row.Scope != section.scope {
+				continue
+			}
+			label := row.Metric
+			if row.Key != "" {
+				label = row.Key + " " + row.Metric
+			}
+			fmt.Fprintf(w, "- %s: MAE %s days | MAPE %s%% | bias %s days | %d samples\n", label, formatNumber(row.MAE), formatNumber(row.MAPE), formatSigned(row.Bias), row.Samples)
+		}
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "By As-Of")
+	for _, point := range result.Points {
+		label := point.AsOf
+		if point.RunID != 0 {
+			label = fmt.Sprintf("#%d | %s", point.RunID, point.AsOf)
+		}
+		fmt.Fprintf(w, "- %s | pending %d (%d resolved) | item MAE %s | bias %s\n", label, point.Pending, point.Resolved, formatNumber(point.ItemMAE), formatSigned(point.ItemBias))
+	}
+}
+
+// WriteBacktestCSV writes the accuracy table and per-forecast points using the
+// same path prefix or directory rules as WriteCSV.
+func WriteBacktestCSV(result forecast.BacktestReport, output string) error {
+	basePath, err := resolveCSVBase(output)
+	if err != nil {
+		return err
+	}
+	if err := writeBacktestAccuracyCSV(basePath+"-backtest-accuracy.csv", result.Accuracy); err != nil {
+		return err
+	}
+	return writeBacktestPointCSV(basePath+"-backtest-points.csv", result.Points)
+}
+
+func writeBacktestAccuracyCSV(path string, rows []forecast.ForecastAccuracy) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{"scope", "key", "metric", "samples", "mae_days", "mape", "bias_days"}); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := []string{
+			row.Scope,
+			row.Key,
+			row.Metric,
+			strconv.Itoa(row.Samples),
+			formatFloat(row.MAE, 2),
+			formatFloat(row.MAPE, 1),
+			formatFloat(row.Bias, 2),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeBacktestPointCSV(path string, points []forecast.BacktestPoint) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{"as_of", "run_id", "pending", "resolved", "unresolved", "item_mae_days", "item_bias_days"}); err != nil {
+		return err
+	}
+	for _, point := range points {
+		runID := ""
+		if point.RunID != 0 {
+			runID = strconv.FormatInt(point.RunID, 10)
+		}
+		record := []string{
+			point.AsOf,
+			runID,
+			strconv.Itoa(point.Pending),
+			strconv.Itoa(point.Resolved),
+			strconv.Itoa(point.Unresolved),
+			formatFloat(point.ItemMAE, 2),
+			formatFloat(point.ItemBias, 2),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,231 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+// WriteBrief writes the markdown ops brief to output, a file path or directory.
+func WriteBrief(report forecast.Report, output string) error {
+	path, err := resolveBriefPath(output)
+	if err != nil {
+		return err
+	}
+	content := BuildBrief(report)
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+func resolveBriefPath(output string) (string, error) {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return "", errors.New("brief output path is empty")
+	}
+	info, err := os.Stat(output)
+	if err == nil && info.IsDir() {
+		return filepath.Join(output, "review-queue-brief.md"), nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if filepath.Ext(output) == "" {
+		return output + ".md", nil
+	}
+	return output, nil
+}
+
+// BuildBrief renders the markdown ops brief for a report.
+func BuildBrief(report forecast.Report) string {
+	var builder strings.Builder
+	builder.WriteString("# Review Queue Ops Brief\n\n")
+	builder.WriteString(fmt.Sprintf("Generated: %s\n", report.GeneratedAt))
+	builder.WriteString(fmt.Sprintf("SLA Days: %d\n", report.SLADays))
+	builder.WriteString(fmt.Sprintf("SLA Clock: %s\n", formatCalendarSummary(report.Calendar)))
+	builder.WriteString(fmt.Sprintf("Total Events: %d\n\n", report.TotalEvents))
+
+	builder.WriteString("## Overall\n")
+	builder.WriteString(fmt.Sprintf("- Avg: %.2f days | Median: %.2f days | P90: %.2f days | Max: %.2f days\n",
+		report.Overall.AverageDays, report.Overall.MedianDays, report.Overall.P90Days, report.Overall.MaxDays))
+	builder.WriteString(fmt.Sprintf("- SLA Breach: %d (%.1f%%) | Risk Tier: %s | Distinct Reviewers: %d\n\n",
+		report.Overall.SLABreachCount, report.Overall.SLABreachRate, report.Overall.RiskTier, report.Overall.DistinctReviewers))
+
+	if report.SLAPolicySource != "" {
+		builder.WriteString(fmt.Sprintf("## SLA Policy (%s)\n", report.SLAPolicySource))
+		for _, policy := range report.SLAPolicy {
+			builder.WriteString(fmt.Sprintf("- %s\n", formatStagePolicy(policy)))
+		}
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString("## Stage Risk\n")
+	stageRisks := forecast.SelectStageRisks(report.Stages, report.SLADays, 3)
+	if len(stageRisks) == 0 {
+		builder.WriteString("- No elevated stages detected.\n\n")
+	} else {
+		for _, stage := range stageRisks {
+			builder.WriteString(fmt.Sprintf("- %s | Avg: %.2f days | Breach: %.1f%% | Risk: %s\n",
+				stage.Stage, stage.AverageDays, stage.SLABreachRate, stage.RiskTier))
+		}
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString("## Throughput Trend\n")
+	builder.WriteString(formatTrendSection(report.ThroughputTrend.Trends, 3))
+	builder.WriteString("\n")
+
+	builder.WriteString("## Latency Trend\n")
+	builder.WriteString(formatLatencySection(report.LatencyTrend.Trends, 3))
+	builder.WriteString("\n")
+
+	if report.Queue != nil {
+		queue := report.Queue
+		builder.WriteString("## Queue Snapshot\n")
+		builder.WriteString(fmt.Sprintf("- Pending: %d | Assigned: %d | Unassigned: %d | Avg Age: %.2f days\n",
+			queue.TotalPending, queue.AssignedCount, queue.UnassignedCount, queue.AvgAgeDays))
+		builder.WriteString(fmt.Sprintf("- On Track: %d | Due Soon: %d | Overdue: %d | Due Soon Ratio: %.2f\n",
+			queue.OnTrackCount, queue.DueSoonCount, queue.OverdueCount, queue.DueSoonRatio))
+		if queue.ClearancePlan != nil {
+			plan := queue.ClearancePlan
+			builder.WriteString(fmt.Sprintf("- Clearance Target: %d days | Required: %.2f/day | Current: %.2f/day | Gap: %.2f/day | Status: %s\n",
+				plan.TargetDays, plan.RequiredDaily, plan.CurrentDaily, plan.GapDaily, plan.Status))
+		}
+		if queue.Arrivals != nil {
+			builder.WriteString(fmt.Sprintf("- Arrivals: %s\n", formatArrivalSummary(queue.Arrivals)))
+		}
+		builder.WriteString("\n")
+
+		if queue.Simulation != nil {
+			builder.WriteString(fmt.Sprintf("## Clearance Forecast (%d trials)\n", queue.Simulation.Trials))
+			builder.WriteString(formatSimulationLine("Overall", queue.Simulation))
+			for _, stage := range queue.Stages {
+				if stage.Simulation != nil {
+					builder.WriteString(formatSimulationLine(stage.Stage, stage.Simulation))
+				}
+			}
+			builder.WriteString("\n")
+		}
+
+		builder.WriteString("## Queue Priority\n")
+		if len(queue.PriorityItems) == 0 {
+			builder.WriteString("- No priority items.\n\n")
+		} else {
+			maxItems := 5
+			if maxItems > len(queue.PriorityItems) {
+				maxItems = len(queue.PriorityItems)
+			}
+			for i := 0; i < maxItems; i++ {
+				item := queue.PriorityItems[i]
+				builder.WriteString(fmt.Sprintf("- %s | %s | %s | Age %.2f days | %s\n",
+					item.ApplicationID, item.Stage, item.ReviewerID, item.AgeDays, item.Status))
+			}
+			builder.WriteString("\n")
+		}
+	}
+
+	if journeys := report.Journeys; journeys != nil {
+		builder.WriteString("## Application Journeys\n")
+		builder.WriteString(fmt.Sprintf("- Applications: %d | Complete: %d | In Flight: %d | Cycle Time: %.2f days avg, %.2f median\n",
+			journeys.Applications, journeys.Completed, journeys.InFlight, journeys.CycleTime.AverageDays, journeys.CycleTime.MedianDays))
+		for _, conversion := range journeys.Conversions {
+			builder.WriteString(fmt.Sprintf("- %s -> %s: %.1f%% converted\n", conversion.From, conversion.To, conversion.ConversionRate))
+		}
+		maxJourneys := min(3, len(journeys.Slowest))
+		for _, summary := range journeys.Slowest[:maxJourneys] {
+			builder.WriteString(fmt.Sprintf("- Slow: %s | %.2f days | %s\n", summary.ApplicationID, summary.CycleDays, summary.Status))
+		}
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString("## Insights\n")
+	if len(report.Insights) == 0 {
+		builder.WriteString("- No critical insights flagged.\n")
+	} else {
+		for _, insight := range report.Insights {
+			builder.WriteString(fmt.Sprintf("- [%s] %s (%s)\n",
+				strings.ToUpper(insight.Severity), insight.Message, insight.Metric))
+		}
+	}
+
+	return builder.String()
+}
+
+func formatStagePolicy(policy forecast.StagePolicy) string {
+	line := fmt.Sprintf("%s: SLA %d days | Due Soon Ratio %.2f | Source: %s", policy.Stage, policy.SLADays, policy.DueSoonRatio, policy.Source)
+	if policy.Overrides > 0 {
+		line += fmt.Sprintf(" | Program/Priority Overrides: %d", policy.Overrides)
+	}
+	return line
+}
+
+func formatSimulationLine(label string, simulation *forecast.ClearanceSimulation) string {
+	return fmt.Sprintf("- %s: %s\n", label, formatSimulationSummary(simulation))
+}
+
+func formatSimulationSummary(simulation *forecast.ClearanceSimulation) string {
+	if simulation.Status == "no throughput data" {
+		return "no throughput data in window"
+	}
+	return fmt.Sprintf("P50 %s (%.1f days) | P80 %s (%.1f days) | P95 %s (%.1f days) | P(clear <= %d days): %.1f%% | Status: %s",
+		simulation.P50Date, simulation.P50Days, simulation.P80Date, simulation.P80Days,
+		simulation.P95Date, simulation.P95Days, simulation.TargetDays, simulation.ProbClearWithinTarget, simulation.Status)
+}
+
+func formatTrendSection(trends []forecast.ThroughputTrend, maxStages int) string {
+	if len(trends) == 0 {
+		return "- No throughput trend data.\n"
+	}
+	var builder strings.Builder
+	for _, trend := range trends {
+		if trend.Label != "overall" {
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("- Overall: %+d (%.1f%%) | Current %.2f/week | Prior %.2f/week | Trend %s\n",
+			trend.Delta, trend.DeltaPercent, trend.CurrentPerWeek, trend.PriorPerWeek, trend.Trend))
+		break
+	}
+	count := 0
+	for _, trend := range trends {
+		if trend.Label == "overall" {
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("- %s: %+d (%.1f%%) | Current %.2f/week | Prior %.2f/week | Trend %s\n",
+			trend.Label, trend.Delta, trend.DeltaPercent, trend.CurrentPerWeek, trend.PriorPerWeek, trend.Trend))
+		count++
+		if count >= maxStages {
+			break
+		}
+	}
+	return builder.String()
+}
+
+func formatLatencySection(trends []forecast.LatencyTrend, maxStages int) string {
+	if len(trends) == 0 {
+		return "- No latency trend data.\n"
+	}
+	var builder strings.Builder
+	for _, trend := range trends {
+		if trend.Label != "overall" {
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("- Overall: Avg %+0.2f days | Median %+0.2f days | Trend %s\n",
+			trend.AvgDeltaDays, trend.MedianDeltaDays, trend.Trend))
+		break
+	}
+	count := 0
+	for _, trend := range trends {
+		if trend.Label == "overall" {
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("- %s: Avg %+0.2f days | Median %+0.2f days | Trend %s\n",
+			trend.Label, trend.AvgDeltaDays, trend.MedianDeltaDays, trend.Trend))
+		count++
+		if count >= maxStages {
+			break
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,382 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+// WriteReport renders the human-readable console report.
+func WriteReport(w io.Writer, report forecast.Report, reviewerTop int) {
+	fmt.Fprintf(w, "Review Queue Forecaster\n")
+	fmt.Fprintf(w, "Generated: %s\n", report.GeneratedAt)
+	fmt.Fprintf(w, "SLA Days: %d\n", report.SLADays)
+	fmt.Fprintf(w, "SLA Clock: %s\n", formatCalendarSummary(report.Calendar))
+	if report.Timezone != "" {
+		fmt.Fprintf(w, "Timezone: %s (naive timestamps, windows, and queue ages)\n", report.Timezone)
+	}
+	if config := formatRunConfig(report.RunConfig); config != "" {
+		fmt.Fprintf(w, "Config: %s\n", config)
+	}
+	fmt.Fprintf(w, "Total Events: %d\n\n", report.TotalEvents)
+
+	if report.SLAPolicySource != "" {
+		fmt.Fprintf(w, "SLA Policy (%s)\n", report.SLAPolicySource)
+		for _, policy := range report.SLAPolicy {
+			fmt.Fprintf(w, "- %s\n", formatStagePolicy(policy))
+		}
+		fmt.Fprintln(w)
+	}
+
+	printDataQuality(w, report.DataQuality)
+
+	fmt.Fprintln(w, "Overall")
+	printStats(w, report.Overall)
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "By Stage")
+	for _, stats := range report.Stages {
+		printStats(w, stats)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Throughput")
+	fmt.Fprintf(w, "- Window: last %d days (as of %s)\n", report.Throughput.WindowDays, report.Throughput.AsOf)
+	fmt.Fprintf(w, "  Events in window: %d | Throughput: %.2f events/week\n", report.Throughput.EventsInWindow, report.Throughput.ThroughputPerWeek)
+
+	printReviewerSnapshot(w, report.Reviewers, reviewerTop)
+	printThroughputTrends(w, report.ThroughputTrend)
+	printLatencyTrends(w, report.LatencyTrend)
+	printJourneys(w, report.Journeys)
+	printInsights(w, report.Insights)
+
+	if report.Queue != nil {
+		fmt.Fprintln(w)
+		WriteQueueForecast(w, report.Queue)
+	}
+}
+
+// WriteQueueForecast renders the queue forecast section of the console report.
+func WriteQueueForecast(w io.Writer, queue *forecast.QueueReport) {
+	if queue == nil {
+		return
+	}
+	fmt.Fprintln(w, "Queue Forecast")
+	fmt.Fprintf(w, "- As of %s | Pending: %d | Assigned: %d | Unassigned: %d | Avg Age: %.2f days\n",
+		queue.AsOf, queue.TotalPending, queue.AssignedCount, queue.UnassignedCount, queue.AvgAgeDays)
+	fmt.Fprintf(w, "  On Track: %d | Due Soon: %d | Overdue: %d | Due Soon Ratio: %.2f\n",
+		queue.OnTrackCount, queue.DueSoonCount, queue.OverdueCount, queue.DueSoonRatio)
+	if queue.ClearancePlan != nil {
+		plan := queue.ClearancePlan
+		fmt.Fprintf(w, "  Clearance Target: %d days | Required: %.2f/day (%.2f/week) | Current: %.2f/day (%.2f/week) | Gap: %.2f/day | Status: %s\n",
+			plan.TargetDays, plan.RequiredDaily, plan.RequiredWeekly, plan.CurrentDaily, plan.CurrentWeekly, plan.GapDaily, plan.Status)
+	}
+	if len(queue.ItemProjections) > 0 {
+		fmt.Fprintf(w, "  Projected Past SLA: %d of %d pending items\n", queue.ProjectedPastSLA, queue.TotalPending)
+	}
+	if queue.Simulation != nil {
+		fmt.Fprintf(w, "  Clearance Forecast (%d trials): %s\n", queue.Simulation.Trials, formatSimulationSummary(queue.Simulation))
+	}
+	if queue.Arrivals != nil {
+		fmt.Fprintf(w, "  Arrivals: %s\n", formatArrivalSummary(queue.Arrivals))
+	}
+	for _, stage := range queue.Stages {
+		fmt.Fprintf(w, "  - %s\n", stage.Stage)
+		fmt.Fprintf(w, "    Pending: %d | Avg Age: %.2f days | On Track: %d | Due Soon: %d | Overdue: %d\n",
+			stage.PendingCount, stage.AvgAgeDays, stage.OnTrackCount, stage.DueSoonCount, stage.OverdueCount)
+		fmt.Fprintf(w, "    Daily Throughput: %.2f | Clear Days: %.2f | Status: %s\n",
+			stage.DailyThroughput, stage.EstimatedClearDays, stage.ClearanceStatus)
+		if stage.Simulation != nil {
+			fmt.Fprintf(w, "    Simulated Clear: %s\n", formatSimulationSummary(stage.Simulation))
+		}
+		if stage.Arrivals != nil {
+			fmt.Fprintf(w, "    Arrivals: %s\n", formatArrivalSummary(stage.Arrivals))
+		}
+		if stage.RequiredDailyThroughput > 0 {
+			fmt.Fprintf(w, "    Required: %.2f/day (%.2f/week) | Gap: %.2f/day | Capacity: %s\n",
+				stage.RequiredDailyThroughput, stage.RequiredWeeklyThroughput, stage.ThroughputGapDaily, stage.CapacityStatus)
+		}
+	}
+	if len(queue.Reviewers) > 0 {
+		maxReviewers := 5
+		if maxReviewers > len(queue.Reviewers) {
+			maxReviewers = len(queue.Reviewers)
+		}
+		fmt.Fprintf(w, "  Reviewer Forecast (Top %d by Pending)\n", maxReviewers)
+		for i := 0; i < maxReviewers; i++ {
+			reviewer := queue.Reviewers[i]
+			fmt.Fprintf(w, "  - %s\n", reviewer.ReviewerID)
+			fmt.Fprintf(w, "    Pending: %d | Avg Age: %.2f days | On Track: %d | Due Soon: %d | Overdue: %d\n",
+				reviewer.PendingCount, reviewer.AvgAgeDays, reviewer.OnTrackCount, reviewer.DueSoonCount, reviewer.OverdueCount)
+			fmt.Fprintf(w, "    Throughput: %.2f/week | Clear Days: %.2f | Status: %s\n",
+				reviewer.ThroughputPerWeek, reviewer.EstimatedClearDays, reviewer.ClearanceStatus)
+		}
+	}
+}
+
+// formatArrivalSummary describes an arrival forecast on one line.
+func formatArrivalSummary(arrivals *forecast.ArrivalForecast) string {
+	line := fmt.Sprintf("%.2f/day recent | %.2f/day forecast | Next 7 days: %.1f | Next 28 days: %.1f | Net: %+.2f/day",
+		arrivals.RecentDaily, arrivals.ForecastDaily, arrivals.Next7Days, arrivals.Next28Days, arrivals.NetDaily)
+	if arrivals.Seasonal {
+		line += " | seasonal"
+	}
+	return line
+}
+
+func printJourneys(w io.Writer, journeys *forecast.JourneyReport) {
+	if journeys == nil {
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Application Journeys")
+	fmt.Fprintf(w, "- Stages: %s\n", strings.Join(journeys.StageOrder, " > "))
+	fmt.Fprintf(w, "  Applications: %d | Complete: %d | In Flight: %d | Incomplete: %d\n",
+		journeys.Applications, journeys.Completed, journeys.InFlight, journeys.Incomplete)
+	cycle := journeys.CycleTime
+	fmt.Fprintf(w, "  Cycle Time (%d journeys): Avg %.2f | Median %.2f | P90 %.2f | Max %.2f days\n",
+		cycle.Count, cycle.AverageDays, cycle.MedianDays, cycle.P90Days, cycle.MaxDays)
+	if len(journeys.Conversions) > 0 {
+		fmt.Fprintln(w, "  Conversion")
+		for _, conversion := range journeys.Conversions {
+			fmt.Fprintf(w, "  - %s -> %s: %d of %d (%.1f%%)\n",
+				conversion.From, conversion.To, conversion.Entered, conversion.Reviewed, conversion.ConversionRate)
+		}
+	}
+	if len(journeys.Handoffs) > 0 {
+		fmt.Fprintln(w, "  Hand-off Wait")
+		for _, handoff := range journeys.Handoffs {
+			fmt.Fprintf(w, "  - %s -> %s | Count: %d | Avg: %.2f | Median: %.2f | P90: %.2f days\n",
+				handoff.From, handoff.To, handoff.Count, handoff.AverageWaitDays, handoff.MedianWaitDays, handoff.P90WaitDays)
+		}
+	}
+	if len(journeys.Slowest) > 0 {
+		fmt.Fprintf(w, "  Slowest Journeys (Top %d)\n", len(journeys.Slowest))
+		for _, summary := range journeys.Slowest {
+			fmt.Fprintf(w, "  - %s | %.2f days (%.2f waiting) | %s | %s\n",
+				summary.ApplicationID, summary.CycleDays, summary.WaitDays, summary.Status, summary.Path)
+		}
+	}
+	if len(journeys.Progress) > 0 {
+		fmt.Fprintln(w, "  In-Flight Progress")
+		for _, step := range journeyStepCounts(journeys) {
+			fmt.Fprintf(w, "  - Step %d/%d %s: %d pending | Avg Journey Age: %.2f days\n",
+				step.step, len(journeys.StageOrder), step.stage, step.count, step.ageDays)
+		}
+	}
+}
+
+type journeyStepCount struct {
+	step    int
+	stage   string
+	count   int
+	ageDays float64
+}
+
+// journeyStepCounts groups in-flight items by the step they are queued at,
+// furthest along first, averaging how long their journeys have run.
+func journeyStepCounts(journeys *forecast.JourneyReport) []journeyStepCount {
+	var counts []journeyStepCount
+	for _, progress := range journeys.Progress {
+		if len(counts) == 0 || counts[len(counts)-1].stage != progress.Stage {
+			counts = append(counts, journeyStepCount{step: progress.Step, stage: progress.Stage})
+		}
+		last := &counts[len(counts)-1]
+		last.ageDays = (last.ageDays*float64(last.count) + progress.JourneyAgeDays) / float64(last.count+1)
+		last.count++
+	}
+	return counts
+}
+
+func printDataQuality(w io.Writer, inputs []forecast.DataQualityInput) {
+	if len(inputs) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Data Quality (lenient)")
+	for _, input := range inputs {
+		fmt.Fprintf(w, "- %s %s: %d read | %d accepted | %d skipped\n",
+			input.Input, input.Source, input.RowsRead, input.RowsAccepted, input.RowsSkipped)
+		for _, issue := range input.Issues {
+			fmt.Fprintf(w, "  %s: %d %s (rows %s)\n", issue.Issue, issue.Count, issue.Action, formatSampleRows(issue.SampleRows))
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// formatSampleRows joins sample row numbers with spaces.
+func formatSampleRows(rows []int) string {
+	parts := make([]string, len(rows))
+	for i, row := range rows {
+		parts[i] = strconv.Itoa(row)
+	}
+	return strings.Join(parts, " ")
+}
+
+func printInsights(w io.Writer, insights []forecast.Insight) {
+	if len(insights) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Insights")
+	for _, insight := range insights {
+		fmt.Fprintf(w, "- [%s] %s (%s)\n", strings.ToUpper(insight.Severity), insight.Message, insight.Metric)
+	}
+}
+
+func printStats(w io.Writer, stats forecast.StageStats) {
+	fmt.Fprintf(w, "- %s\n", stats.Stage)
+	fmt.Fprintf(w, "  Count: %d | Avg: %.2f days | Median: %.2f days | P90: %.2f days | Max: %.2f days\n",
+		stats.Count, stats.AverageDays, stats.MedianDays, stats.P90Days, stats.MaxDays)
+	fmt.Fprintf(w, "  SLA Breach: %d (%.1f%%) | Distinct Reviewers: %d\n",
+		stats.SLABreachCount, stats.SLABreachRate, stats.DistinctReviewers)
+	if stats.Count > 0 {
+		fmt.Fprintf(w, "  Aging: On Time %d (%.1f%%) | At Risk %d (%.1f%%) | Overdue %d (%.1f%%) | Risk Tier: %s\n",
+			stats.AgingBuckets.OnTime, forecast.Percent(stats.AgingBuckets.OnTime, stats.Count),
+			stats.AgingBuckets.AtRisk, forecast.Percent(stats.AgingBuckets.AtRisk, stats.Count),
+			stats.AgingBuckets.Overdue, forecast.Percent(stats.AgingBuckets.Overdue, stats.Count),
+			stats.RiskTier)
+	}
+	if survival := stats.Survival; survival != nil {
+		fmt.Fprintf(w, "  With Pending (Kaplan-Meier, %d censored): Median: %s | P90: %s | Within SLA: %.1f%%\n",
+			survival.Censored, formatSurvivalDays(survival.MedianDays), formatSurvivalDays(survival.P90Days), survival.ProbWithinSLA)
+	}
+}
+
+// formatSurvivalDays prints a survival quantile, which is nil when too many
+// items are still pending to reach it.
+func formatSurvivalDays(days *float64) string {
+	if days == nil {
+		return "not reached"
+	}
+	return fmt.Sprintf("%.2f days", *days)
+}
+
+func printReviewerSnapshot(w io.Writer, reviewers []forecast.ReviewerStats, top int) {
+	if len(reviewers) == 0 {
+		return
+	}
+	if top <= 0 {
+		top = 5
+	}
+	if top > len(reviewers) {
+		top = len(reviewers)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Reviewer Snapshot (Top %d by Throughput)\n", top)
+	for i := 0; i < top; i++ {
+		stats := reviewers[i]
+		fmt.Fprintf(w, "- %s\n", stats.ReviewerID)
+		fmt.Fprintf(w, "  Count: %d | Avg: %.2f days | Median: %.2f days | P90: %.2f days | Max: %.2f days\n",
+			stats.Count, stats.AverageDays, stats.MedianDays, stats.P90Days, stats.MaxDays)
+		fmt.Fprintf(w, "  SLA Breach: %d (%.1f%%) | Last Reviewed: %s | Throughput: %.2f events/week\n",
+			stats.SLABreachCount, stats.SLABreachRate, stats.LastReviewedAt, stats.ThroughputPerWeek)
+		if stats.Count > 0 {
+			fmt.Fprintf(w, "  Aging: On Time %d (%.1f%%) | At Risk %d (%.1f%%) | Overdue %d (%.1f%%) | Risk Tier: %s\n",
+				stats.AgingBuckets.OnTime, forecast.Percent(stats.AgingBuckets.OnTime, stats.Count),
+				stats.AgingBuckets.AtRisk, forecast.Percent(stats.AgingBuckets.AtRisk, stats.Count),
+				stats.AgingBuckets.Overdue, forecast.Percent(stats.AgingBuckets.Overdue, stats.Count),
+				stats.RiskTier)
+		}
+	}
+}
+
+func printThroughputTrends(w io.Writer, summary forecast.ThroughputTrendSummary) {
+	if len(summary.Trends) == 0 {
+		return
+	}
+	maxStages := 5
+	trends := summary.Trends
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Throughput Trend")
+	fmt.Fprintf(w, "- Current window: %s to %s (%d days)\n", summary.CurrentWindowStart, summary.CurrentWindowEnd, summary.WindowDays)
+	fmt.Fprintf(w, "  Prior window: %s to %s\n", summary.PriorWindowStart, summary.PriorWindowEnd)
+
+	fmt.Fprintln(w, "  Overall")
+	for _, trend := range trends {
+		if trend.Label != "overall" {
+			continue
+		}
+		fmt.Fprintf(w, "  - %s | Current: %d | Prior: %d | Delta: %+d (%.1f%%) | Trend: %s\n",
+			trend.Label, trend.CurrentCount, trend.PriorCount, trend.Delta, trend.DeltaPercent, trend.Trend)
+		fmt.Fprintf(w, "    Current: %.2f/week | Prior: %.2f/week\n", trend.CurrentPerWeek, trend.PriorPerWeek)
+	}
+
+	fmt.Fprintf(w, "  Top %d Stages\n", maxStages)
+	count := 0
+	for _, trend := range trends {
+		if trend.Label == "overall" {
+			continue
+		}
+		fmt.Fprintf(w, "  - %s | Current: %d | Prior: %d | Delta: %+d (%.1f%%) | Trend: %s\n",
+			trend.Label, trend.CurrentCount, trend.PriorCount, trend.Delta, trend.DeltaPercent, trend.Trend)
+		fmt.Fprintf(w, "    Current: %.2f/week | Prior: %.2f/week\n", trend.CurrentPerWeek, trend.PriorPerWeek)
+		count++
+		if count >= maxStages {
+			break
+		}
+	}
+}
+
+func printLatencyTrends(w io.Writer, summary forecast.LatencyTrendSummary) {
+	if len(summary.Trends) == 0 {
+		return
+	}
+	maxStages := 5
+	trends := summary.Trends
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Latency Trend")
+	fmt.Fprintf(w, "- Current window: %s to %s (%d days)\n", summary.CurrentWindowStart, summary.CurrentWindowEnd, summary.WindowDays)
+	fmt.Fprintf(w, "  Prior window: %s to %s\n", summary.PriorWindowStart, summary.PriorWindowEnd)
+
+	fmt.Fprintln(w, "  Overall")
+	for _, trend := range trends {
+		if trend.Label != "overall" {
+			continue
+		}
+		fmt.Fprintf(w, "  - %s | Avg: %.2f -> %.2f days (%+.2f, %.1f%%) | Median: %.2f -> %.2f days (%+.2f, %.1f%%) | Trend: %s\n",
+			trend.Label, trend.PriorAvgDays, trend.CurrentAvgDays, trend.AvgDeltaDays, trend.AvgDeltaPercent,
+			trend.PriorMedianDays, trend.CurrentMedianDays, trend.MedianDeltaDays, trend.MedianDeltaPct, trend.Trend)
+	}
+
+	fmt.Fprintf(w, "  Top %d Stages\n", maxStages)
+	count := 0
+	for _, trend := range trends {
+		if trend.Label == "overall" {
+			continue
+		}
+		fmt.Fprintf(w, "  - %s | Avg: %.2f -> %.2f days (%+.2f, %.1f%%) | Median: %.2f -> %.2f days (%+.2f, %.1f%%) | Trend: %s\n",
+			trend.Label, trend.PriorAvgDays, trend.CurrentAvgDays, trend.AvgDeltaDays, trend.AvgDeltaPercent,
+			trend.PriorMedianDays, trend.CurrentMedianDays, trend.MedianDeltaDays, trend.MedianDeltaPct, trend.Trend)
+		count++
+		if count >= maxStages {
+			break
+		}
+	}
+}
+
+func formatCalendarSummary(summary forecast.CalendarSummary) string {
+	if summary.Mode != forecast.ClockBusiness {
+		return fmt.Sprintf("calendar days (%s)", summary.Timezone)
+	}
+	weekend := "none"
+	if len(summary.Weekend) > 0 {
+		weekend = strings.Join(summary.Weekend, ",")
+	}
+	return fmt.Sprintf("business days (%s) | Weekend: %s | Holidays: %d", summary.Timezone, weekend, summary.HolidayCount)
+}
+
+func formatRunConfig(config *forecast.RunConfig) string {
+	if config == nil || config.ConfigPath == "" {
+		return ""
+	}
+	if config.Profile == "" {
+		return config.ConfigPath
+	}
+	return fmt.Sprintf("%s (profile: %s)", config.ConfigPath, config.Profile)
+}
@@ -0,0 +1,785 @@
+// Package export renders reports as CSV bundles, markdown briefs, projection
+// files, and console text.
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+// WriteCSV writes the CSV bundle (stage, reviewer, throughput, trend, insight, SLA
+// policy, queue, arrival, journey, and data-quality files) using output as a path prefix
+// or directory.
+func WriteCSV(report forecast.Report, output string) error {
+	basePath, err := resolveCSVBase(output)
+	if err != nil {
+		return err
+	}
+
+	if err := writeStageCSV(basePath+"-stage-summary.csv", report.Stages); err != nil {
+		return err
+	}
+	if err := writeReviewerCSV(basePath+"-reviewer-summary.csv", report.Reviewers); err != nil {
+		return err
+	}
+	if err := writeThroughputCSV(basePath+"-throughput-summary.csv", report.Throughput); err != nil {
+		return err
+	}
+	if err := writeTrendCSV(basePath+"-throughput-trend.csv", report.ThroughputTrend.Trends); err != nil {
+		return err
+	}
+	if err := writeLatencyTrendCSV(basePath+"-latency-trend.csv", report.LatencyTrend.Trends); err != nil {
+		return err
+	}
+	if err := writeInsightCSV(basePath+"-insights.csv", report.Insights); err != nil {
+		return err
+	}
+	if err := writeSLAPolicyCSV(basePath+"-sla-policy.csv", report.SLAPolicy); err != nil {
+		return err
+	}
+	if report.Queue != nil {
+		if err := writeQueueCSV(basePath+"-queue-forecast.csv", report.Queue); err != nil {
+			return err
+		}
+		if err := writeQueueReviewerCSV(basePath+"-queue-reviewer-forecast.csv", report.Queue); err != nil {
+			return err
+		}
+		if err := writeQueuePriorityCSV(basePath+"-queue-priority.csv", report.Queue); err != nil {
+			return err
+		}
+		if err := writeQueueProjectionCSV(basePath+"-queue-projections.csv", report.Queue); err != nil {
+			return err
+		}
+		if report.Queue.Arrivals != nil {
+			if err := writeArrivalCSV(basePath+"-arrivals.csv", report.Queue); err != nil {
+				return err
+			}
+		}
+	}
+	if report.Journeys != nil {
+		if err := writeJourneyStageCSV(basePath+"-journey-stages.csv", report.Journeys); err != nil {
+			return err
+		}
+		if err := writeJourneyCSV(basePath+"-journeys.csv", report.Journeys.Slowest); err != nil {
+			return err
+		}
+		if err := writeJourneyProgressCSV(basePath+"-journey-progress.csv", report.Journeys.Progress); err != nil {
+			return err
+		}
+	}
+	if len(report.DataQuality) > 0 {
+		if err := writeDataQualityCSV(basePath+"-data-quality.csv", report.DataQuality); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func resolveCSVBase(output string) (string, error) {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return "", errors.New("csv output path is empty")
+	}
+	info, err := os.Stat(output)
+	if err == nil && info.IsDir() {
+		return filepath.Join(output, "review-queue"), nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	return strings.TrimSuffix(output, ".csv"), nil
+}
+
+func writeStageCSV(path string, stages []forecast.StageStats) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{
+		"stage", "count", "avg_days", "median_days", "p90_days", "max_days",
+		"sla_breach_count", "sla_breach_rate", "distinct_reviewers",
+		"on_time", "at_risk", "overdue", "risk_tier", "sla_days",
+		"censored_count", "km_median_days", "km_p90_days", "km_prob_within_sla",
+	}); err != nil {
+		return err
+	}
+	for _, stats := range stages {
+		record := []string{
+			stats.Stage,
+			strconv.Itoa(stats.Count),
+			formatFloat(stats.AverageDays, 2),
+			formatFloat(stats.MedianDays, 2),
+			formatFloat(stats.P90Days, 2),
+			formatFloat(stats.MaxDays, 2),
+			strconv.Itoa(stats.SLABreachCount),
+			formatFloat(stats.SLABreachRate, 1),
+			strconv.Itoa(stats.DistinctReviewers),
+			strconv.Itoa(stats.AgingBuckets.OnTime),
+			strconv.Itoa(stats.AgingBuckets.AtRisk),
+			strconv.Itoa(stats.AgingBuckets.Overdue),
+			stats.RiskTier,
+			strconv.Itoa(stats.SLADays),
+		}
+		record = append(record, survivalColumns(stats.Survival)...)
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// survivalColumns leaves a quantile blank when the estimate never reaches it.
+func survivalColumns(survival *forecast.SurvivalEstimate) []string {
+	if survival == nil {
+		return []string{"", "", "", ""}
+	}
+	quantile := func(days *float64) string {
+		if days == nil {
+			return ""
+		}
+		return formatFloat(*days, 2)
+	}
+	return []string{
+		strconv.Itoa(survival.Censored),
+		quantile(survival.MedianDays),
+		quantile(survival.P90Days),
+		formatFloat(survival.ProbWithinSLA, 1),
+	}
+}
+
+func writeSLAPolicyCSV(path string, policies []forecast.StagePolicy) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{"stage", "sla_days", "due_soon_ratio", "source", "overrides"}); err != nil {
+		return err
+	}
+	for _, policy := range policies {
+		record := []string{
+			policy.Stage,
+			strconv.Itoa(policy.SLADays),
+			formatFloat(policy.DueSoonRatio, 2),
+			policy.Source,
+			strconv.Itoa(policy.Overrides),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeReviewerCSV(path string, reviewers []forecast.ReviewerStats) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{
+		"reviewer_id", "count", "avg_days", "median_days", "p90_days", "max_days",
+		"sla_breach_count", "sla_breach_rate", "last_reviewed_at",
+		"throughput_per_week", "window_count",
+		"on_time", "at_risk", "overdue", "risk_tier",
+	}); err != nil {
+		return err
+	}
+	for _, stats := range reviewers {
+		record := []string{
+			stats.ReviewerID,
+			strconv.Itoa(stats.Count),
+			formatFloat(stats.AverageDays, 2),
+			formatFloat(stats.MedianDays, 2),
+			formatFloat(stats.P90Days, 2),
+			formatFloat(stats.MaxDays, 2),
+			strconv.Itoa(stats.SLABreachCount),
+			formatFloat(stats.SLABreachRate, 1),
+			stats.LastReviewedAt,
+			formatFloat(stats.ThroughputPerWeek, 2),
+			strconv.Itoa(stats.WindowCount),
+			strconv.Itoa(stats.AgingBuckets.OnTime),
+			strconv.Itoa(stats.AgingBuckets.AtRisk),
+			strconv.Itoa(stats.AgingBuckets.Overdue),
+			stats.RiskTier,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeThroughputCSV(path string, throughput forecast.ThroughputSummary) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{"as_of", "window_days", "events_in_window", "throughput_per_week"}); err != nil {
+		return err
+	}
+	record := []string{
+		throughput.AsOf,
+		strconv.Itoa(throughput.WindowDays),
+		strconv.Itoa(throughput.EventsInWindow),
+		formatFloat(throughput.ThroughputPerWeek, 2),
+	}
+	if err := writer.Write(record); err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeTrendCSV(path string, trends []forecast.ThroughputTrend) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{
+		"label", "current_count", "prior_count", "delta", "delta_percent",
+		"current_per_week", "prior_per_week", "trend",
+	}); err != nil {
+		return err
+	}
+	for _, trend := range trends {
+		record := []string{
+			trend.Label,
+			strconv.Itoa(trend.CurrentCount),
+			strconv.Itoa(trend.PriorCount),
+			strconv.Itoa(trend.Delta),
+			formatFloat(trend.DeltaPercent, 1),
+			formatFloat(trend.CurrentPerWeek, 2),
+			formatFloat(trend.PriorPerWeek, 2),
+			trend.Trend,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeQueueCSV(path string, queue *forecast.QueueReport) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{
+		"stage", "pending_count", "avg_age_days", "overdue_count", "due_soon_count",
+		"on_track_count", "daily_throughput", "estimated_clear_days", "clearance_status",
+		"required_daily_throughput", "required_weekly_throughput",
+		"throughput_gap_daily", "throughput_gap_weekly", "capacity_status",
+		"assigned_count", "unassigned_count",
+		"sim_p50_days", "sim_p80_days", "sim_p95_days",
+		"sim_p50_date", "sim_p80_date", "sim_p95_date", "sim_prob_within_target",
+		"sla_days", "due_soon_ratio",
+		"arrivals_recent_daily", "arrivals_forecast_daily", "arrivals_next_28_days", "arrivals_net_daily",
+	}); err != nil {
+		return err
+	}
+	overallRequiredDaily := ""
+	overallRequiredWeekly := ""
+	overallGapDaily := ""
+	overallGapWeekly := ""
+	overallCapacity := ""
+	if queue.ClearancePlan != nil {
+		overallRequiredDaily = formatFloat(queue.ClearancePlan.RequiredDaily, 2)
+		overallRequiredWeekly = formatFloat(queue.ClearancePlan.RequiredWeekly, 2)
+		overallGapDaily = formatFloat(queue.ClearancePlan.GapDaily, 2)
+		overallGapWeekly = formatFloat(queue.ClearancePlan.GapWeekly, 2)
+		overallCapacity = queue.ClearancePlan.Status
+	}
+	overall := []string{
+		"overall",
+		strconv.Itoa(queue.TotalPending),
+		formatFloat(queue.AvgAgeDays, 2),
+		strconv.Itoa(queue.OverdueCount),
+		strconv.Itoa(queue.DueSoonCount),
+		strconv.Itoa(queue.OnTrackCount),
+		"",
+		"",
+		"",
+		overallRequiredDaily,
+		overallRequiredWeekly,
+		overallGapDaily,
+		overallGapWeekly,
+		overallCapacity,
+		strconv.Itoa(queue.AssignedCount),
+		strconv.Itoa(queue.UnassignedCount),
+	}
+	overall = append(overall, simulationColumns(queue.Simulation)...)
+	overall = append(overall, "", formatFloat(queue.DueSoonRatio, 2))
+	overall = append(overall, arrivalColumns(queue.Arrivals)...)
+	if err := writer.Write(overall); err != nil {
+		return err
+	}
+	for _, stage := range queue.Stages {
+		record := []string{
+			stage.Stage,
+			strconv.Itoa(stage.PendingCount),
+			formatFloat(stage.AvgAgeDays, 2),
+			strconv.Itoa(stage.OverdueCount),
+			strconv.Itoa(stage.DueSoonCount),
+			strconv.Itoa(stage.OnTrackCount),
+			formatFloat(stage.DailyThroughput, 2),
+			formatFloat(stage.EstimatedClearDays, 2),
+			stage.ClearanceStatus,
+			formatFloat(stage.RequiredDailyThroughput, 2),
+			formatFloat(stage.RequiredWeeklyThroughput, 2),
+			formatFloat(stage.ThroughputGapDaily, 2),
+			formatFloat(stage.ThroughputGapWeekly, 2),
+			stage.CapacityStatus,
+			"",
+			"",
+		}
+		record = append(record, simulationColumns(stage.Simulation)...)
+		record = append(record, strconv.Itoa(stage.SLADays), formatFloat(stage.DueSoonRatio, 2))
+		record = append(record, arrivalColumns(stage.Arrivals)...)
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func simulationColumns(simulation *forecast.ClearanceSimulation) []string {
+	if simulation == nil || simulation.Status == "no throughput data" {
+		return []string{"", "", "", "", "", "", ""}
+	}
+	return []string{
+		formatFloat(simulation.P50Days, 2),
+		formatFloat(simulation.P80Days, 2),
+		formatFloat(simulation.P95Days, 2),
+		simulation.P50Date,
+		simulation.P80Date,
+		simulation.P95Date,
+		formatFloat(simulation.ProbClearWithinTarget, 1),
+	}
+}
+
+func arrivalColumns(arrivals *forecast.ArrivalForecast) []string {
+	if arrivals == nil {
+		return []string{"", "", "", ""}
+	}
+	return []string{
+		formatFloat(arrivals.RecentDaily, 2),
+		formatFloat(arrivals.ForecastDaily, 2),
+		formatFloat(arrivals.Next28Days, 2),
+		formatFloat(arrivals.NetDaily, 2),
+	}
+}
+
+// writeArrivalCSV writes the daily arrival forecast, overall first and then by
+// stage.
+func writeArrivalCSV(path string, queue *forecast.QueueReport) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{"stage", "date", "expected_arrivals"}); err != nil {
+		return err
+	}
+	write := func(stage string, arrivals *forecast.ArrivalForecast) error {
+		if arrivals == nil {
+			return nil
+		}
+		for _, day := range arrivals.Daily {
+			if err := writer.Write([]string{stage, day.Date, formatFloat(day.Expected, 2)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := write("overall", queue.Arrivals); err != nil {
+		return err
+	}
+	for _, stage := range queue.Stages {
+		if err := write(stage.Stage, stage.Arrivals); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeQueueReviewerCSV(path string, queue *forecast.QueueReport) error {
+	if queue == nil || len(queue.Reviewers) == 0 {
+		return nil
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{
+		"reviewer_id", "pending_count", "avg_age_days", "overdue_count", "due_soon_count",
+		"on_track_count", "throughput_per_week", "estimated_clear_days", "clearance_status",
+	}); err != nil {
+		return err
+	}
+	for _, reviewer := range queue.Reviewers {
+		record := []string{
+			reviewer.ReviewerID,
+			strconv.Itoa(reviewer.PendingCount),
+			formatFloat(reviewer.AvgAgeDays, 2),
+			strconv.Itoa(reviewer.OverdueCount),
+			strconv.Itoa(reviewer.DueSoonCount),
+			strconv.Itoa(reviewer.OnTrackCount),
+			formatFloat(reviewer.ThroughputPerWeek, 2),
+			formatFloat(reviewer.EstimatedClearDays, 2),
+			reviewer.ClearanceStatus,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeLatencyTrendCSV(path string, trends []forecast.LatencyTrend) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{
+		"label", "current_count", "prior_count",
+		"current_avg_days", "prior_avg_days", "avg_delta_days", "avg_delta_percent",
+		"current_median_days", "prior_median_days", "median_delta_days", "median_delta_percent",
+		"trend",
+	}); err != nil {
+		return err
+	}
+	for _, trend := range trends {
+		record := []string{
+			trend.Label,
+			strconv.Itoa(trend.CurrentCount),
+			strconv.Itoa(trend.PriorCount),
+			formatFloat(trend.CurrentAvgDays, 2),
+			formatFloat(trend.PriorAvgDays, 2),
+			formatFloat(trend.AvgDeltaDays, 2),
+			formatFloat(trend.AvgDeltaPercent, 1),
+			formatFloat(trend.CurrentMedianDays, 2),
+			formatFloat(trend.PriorMedianDays, 2),
+			formatFloat(trend.MedianDeltaDays, 2),
+			formatFloat(trend.MedianDeltaPct, 1),
+			trend.Trend,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeInsightCSV(path string, insights []forecast.Insight) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{"severity", "area", "message", "metric"}); err != nil {
+		return err
+	}
+	for _, insight := range insights {
+		record := []string{
+			insight.Severity,
+			insight.Area,
+			insight.Message,
+			insight.Metric,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeDataQualityCSV writes one row per issue, or a single row with a blank
+// issue for an input that had none.
+func writeDataQualityCSV(path string, inputs []forecast.DataQualityInput) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{"input", "source", "rows_read", "rows_accepted", "rows_skipped", "issue", "action", "count", "sample_rows"}); err != nil {
+		return err
+	}
+	for _, input := range inputs {
+		prefix := []string{
+			input.Input,
+			input.Source,
+			strconv.Itoa(input.RowsRead),
+			strconv.Itoa(input.RowsAccepted),
+			strconv.Itoa(input.RowsSkipped),
+		}
+		if len(input.Issues) == 0 {
+			if err := writer.Write(append(prefix, "", "", "0", "")); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, issue := range input.Issues {
+			record := append(append([]string{}, prefix...), issue.Issue, issue.Action, strconv.Itoa(issue.Count), formatSampleRows(issue.SampleRows))
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeJourneyStageCSV writes one row per stage pair: conversions for adjacent
+// stages in the order, then hand-off waits for every pair seen. A pair that has
+// both shares a row.
+func writeJourneyStageCSV(path string, journeys *forecast.JourneyReport) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	type pair struct{ from, to string }
+	handoffs := map[pair]forecast.JourneyHandoff{}
+	for _, handoff := range journeys.Handoffs {
+		handoffs[pair{handoff.From, handoff.To}] = handoff
+	}
+	written := map[pair]bool{}
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{
+		"from_stage", "to_stage", "reviewed", "entered", "conversion_rate",
+		"handoffs", "avg_wait_days", "median_wait_days", "p90_wait_days", "max_wait_days",
+	}); err != nil {
+		return err
+	}
+	write := func(from, to string, conversion []string) error {
+		key := pair{from, to}
+		written[key] = true
+		record := append([]string{from, to}, conversion...)
+		if handoff, ok := handoffs[key]; ok {
+			record = append(record,
+				strconv.Itoa(handoff.Count),
+				formatFloat(handoff.AverageWaitDays, 2),
+				formatFloat(handoff.MedianWaitDays, 2),
+				formatFloat(handoff.P90WaitDays, 2),
+				formatFloat(handoff.MaxWaitDays, 2),
+			)
+		} else {
+			record = append(record, "0", "", "", "", "")
+		}
+		return writer.Write(record)
+	}
+	for _, conversion := range journeys.Conversions {
+		if err := write(conversion.From, conversion.To, []string{
+			strconv.Itoa(conversion.Reviewed),
+			strconv.Itoa(conversion.Entered),
+			formatFloat(conversion.ConversionRate, 1),
+		}); err != nil {
+			return err
+		}
+	}
+	for _, handoff := range journeys.Handoffs {
+		if written[pair{handoff.From, handoff.To}] {
+			continue
+		}
+		if err := write(handoff.From, handoff.To, []string{"", "", ""}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeJourneyCSV(path string, journeys []forecast.JourneySummary) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{
+		"application_id", "status", "path", "started_at", "ended_at",
+		"stages_completed", "cycle_days", "wait_days",
+	}); err != nil {
+		return err
+	}
+	for _, journey := range journeys {
+		record := []string{
+			journey.ApplicationID,
+			journey.Status,
+			journey.Path,
+			journey.StartedAt,
+			journey.EndedAt,
+			strconv.Itoa(journey.StagesCompleted),
+			formatFloat(journey.CycleDays, 2),
+			formatFloat(journey.WaitDays, 2),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeJourneyProgressCSV(path string, progress []forecast.JourneyProgress) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{
+		"application_id", "stage", "step", "steps", "stages_completed",
+		"progress_percent", "started_at", "journey_age_days",
+	}); err != nil {
+		return err
+	}
+	for _, item := range progress {
+		record := []string{
+			item.ApplicationID,
+			item.Stage,
+			strconv.Itoa(item.Step),
+			strconv.Itoa(item.Steps),
+			strconv.Itoa(item.StagesCompleted),
+			formatFloat(item.ProgressPercent, 1),
+			item.StartedAt,
+			formatFloat(item.JourneyAgeDays, 2),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeQueuePriorityCSV(path string, queue *forecast.QueueReport) error {
+	if queue == nil {
+		return nil
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{
+		"application_id", "stage", "reviewer_id", "submitted_at", "age_days",
+		"days_to_sla", "urgency_score", "status",
+	}); err != nil {
+		return err
+	}
+	for _, item := range queue.PriorityItems {
+		record := []string{
+			item.ApplicationID,
+			item.Stage,
+			item.ReviewerID,
+			item.SubmittedAt,
+			formatFloat(item.AgeDays, 2),
+			formatFloat(item.DaysToSLA, 2),
+			formatFloat(item.UrgencyScore, 2),
+			item.Status,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeQueueProjectionCSV(path string, queue *forecast.QueueReport) error {
+	if queue == nil {
+		return nil
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{
+		"application_id", "stage", "reviewer_id", "submitted_at", "age_days",
+		"backlog_position", "backlog_size", "daily_throughput", "throughput_source",
+		"projected_days", "projected_review_at", "projected_age_days", "past_sla", "status",
+	}); err != nil {
+		return err
+	}
+	for _, item := range queue.ItemProjections {
+		record := []string{
+			item.ApplicationID,
+			item.Stage,
+			item.ReviewerID,
+			item.SubmittedAt,
+			formatFloat(item.AgeDays, 2),
+			strconv.Itoa(item.BacklogPosition),
+			strconv.Itoa(item.BacklogSize),
+			formatFloat(item.DailyThroughput, 2),
+			item.ThroughputSource,
+			formatFloat(item.ProjectedDays, 2),
+			item.ProjectedReviewAt,
+			formatFloat(item.ProjectedAgeDays, 2),
+			strconv.FormatBool(item.PastSLA),
+			item.Status,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatFloat(value float64, decimals int) string {
+	return strconv.FormatFloat(value, 'f', decimals, 64)
+}
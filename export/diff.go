@@ -0,0 +1,230 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+// WriteDiff renders a run-to-run comparison for the console. Stages and reviewers
+// with no movement are counted but not listed.
+func WriteDiff(w io.Writer, diff forecast.ReportDiff) {
+	fmt.Fprintf(w, "Review Queue Diff: %s -> %s\n", diff.Base.Label, diff.Target.Label)
+	fmt.Fprintf(w, "- Base: generated %s | events %d%s\n", diff.Base.GeneratedAt, diff.Base.TotalEvents, formatQueueAsOf(diff.Base))
+	fmt.Fprintf(w, "- Target: generated %s | events %d%s\n\n", diff.Target.GeneratedAt, diff.Target.TotalEvents, formatQueueAsOf(diff.Target))
+
+	fmt.Fprintln(w, "Overall")
+	fmt.Fprintf(w, "- %s\n", formatStageDiff(diff.Overall))
+	if diff.Queue != nil {
+		fmt.Fprintf(w, "- Queue: %s\n", formatQueueDiff(*diff.Queue))
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "By Stage")
+	unchanged := 0
+	for _, stage := range diff.Stages {
+		if !stage.Changed() {
+			unchanged++
+			continue
+		}
+		fmt.Fprintf(w, "- %s%s: %s\n", stage.Stage, formatPresence(stage.Presence), formatStageDiff(stage))
+	}
+	if unchanged > 0 {
+		fmt.Fprintf(w, "- %d unchanged\n", unchanged)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "By Reviewer")
+	unchanged = 0
+	for _, reviewer := range diff.Reviewers {
+		if !reviewer.Changed() {
+			unchanged++
+			continue
+		}
+		fmt.Fprintf(w, "- %s%s: %s\n", reviewer.ReviewerID, formatPresence(reviewer.Presence), formatReviewerDiff(reviewer))
+	}
+	if unchanged > 0 {
+		fmt.Fprintf(w, "- %d unchanged\n", unchanged)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Insights")
+	if len(diff.InsightsAdded) == 0 && len(diff.InsightsResolved) == 0 {
+		fmt.Fprintln(w, "- No insight changes.")
+	}
+	for _, insight := range diff.InsightsAdded {
+		fmt.Fprintf(w, "- NEW [%s] %s (%s)\n", strings.ToUpper(insight.Severity), insight.Message, insight.Metric)
+	}
+	for _, insight := range diff.InsightsResolved {
+		fmt.Fprintf(w, "- RESOLVED [%s] %s (%s)\n", strings.ToUpper(insight.Severity), insight.Message, insight.Metric)
+	}
+}
+
+// BuildDiffMarkdown renders a run-to-run comparison as markdown tables.
+func BuildDiffMarkdown(diff forecast.ReportDiff) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("# Review Queue Diff: %s -> %s\n\n", diff.Base.Label, diff.Target.Label))
+	builder.WriteString(fmt.Sprintf("- Base: generated %s | events %d%s\n", diff.Base.GeneratedAt, diff.Base.TotalEvents, formatQueueAsOf(diff.Base)))
+	builder.WriteString(fmt.Sprintf("- Target: generated %s | events %d%s\n\n", diff.Target.GeneratedAt, diff.Target.TotalEvents, formatQueueAsOf(diff.Target)))
+
+	if diff.Queue != nil {
+		queue := diff.Queue
+		builder.WriteString("## Queue\n")
+		builder.WriteString("| Metric | Base | Target | Delta |\n|---|---|---|---|\n")
+		for _, row := range []struct {
+			label string
+			delta forecast.MetricDelta
+		}{
+			{"Pending", queue.Pending},
+			{"Unassigned", queue.Unassigned},
+			{"Due soon", queue.DueSoon},
+			{"Overdue", queue.Overdue},
+			{"Avg age (days)", queue.AvgAgeDays},
+			{"Projected past SLA", queue.ProjectedPastSLA},
+		} {
+			builder.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", row.label, formatNumber(row.delta.Base), formatNumber(row.delta.Target), formatSigned(row.delta.Delta)))
+		}
+		builder.WriteString(fmt.Sprintf("| Clearance status | %s | %s | %s |\n\n", orDash(queue.ClearanceStatus.Base), orDash(queue.ClearanceStatus.Target), changedMark(queue.ClearanceStatus)))
+	}
+
+	builder.WriteString("## Stages\n")
+	builder.WriteString("| Stage | Avg days | P90 days | Breach % | Pending | Overdue | Risk | Clearance |\n|---|---|---|---|---|---|---|---|\n")
+	for _, stage := range append([]forecast.StageDiff{diff.Overall}, diff.Stages...) {
+		builder.WriteString(fmt.Sprintf("| %s%s | %s | %s | %s | %s | %s | %s | %s |\n",
+			markdownCell(stage.Stage), formatPresence(stage.Presence),
+			formatDeltaCell(stage.AverageDays), formatDeltaCell(stage.P90Days), formatDeltaCell(stage.SLABreachRate),
+			formatDeltaCell(stage.Pending), formatDeltaCell(stage.Overdue),
+			formatStatusCell(stage.RiskTier), formatStatusCell(stage.ClearanceStatus)))
+	}
+	builder.WriteString("\n")
+
+	builder.WriteString("## Reviewers\n")
+	if len(diff.Reviewers) == 0 {
+		builder.WriteString("- No reviewers in either run.\n\n")
+	} else {
+		builder.WriteString("| Reviewer | Avg days | Breach % | Throughput/week | Pending | Overdue | Clearance |\n|---|---|---|---|---|---|---|\n")
+		for _, reviewer := range diff.Reviewers {
+			builder.WriteString(fmt.Sprintf("| %s%s | %s | %s | %s | %s | %s | %s |\n",
+				markdownCell(reviewer.ReviewerID), formatPresence(reviewer.Presence),
+				formatDeltaCell(reviewer.AverageDays), formatDeltaCell(reviewer.SLABreachRate), formatDeltaCell(reviewer.ThroughputPerWeek),
+				formatDeltaCell(reviewer.Pending), formatDeltaCell(reviewer.Overdue), formatStatusCell(reviewer.ClearanceStatus)))
+		}
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString("## Insights\n")
+	if len(diff.InsightsAdded) == 0 && len(diff.InsightsResolved) == 0 {
+		builder.WriteString("- No insight changes.\n")
+	}
+	for _, insight := range diff.InsightsAdded {
+		builder.WriteString(fmt.Sprintf("- New: [%s] %s (%s)\n", strings.ToUpper(insight.Severity), insight.Message, insight.Metric))
+	}
+	for _, insight := range diff.InsightsResolved {
+		builder.WriteString(fmt.Sprintf("- Resolved: [%s] %s (%s)\n", strings.ToUpper(insight.Severity), insight.Message, insight.Metric))
+	}
+	return builder.String()
+}
+
+func formatStageDiff(stage forecast.StageDiff) string {
+	parts := []string{
+		fmt.Sprintf("Avg %s days", formatDeltaCell(stage.AverageDays)),
+		fmt.Sprintf("P90 %s days", formatDeltaCell(stage.P90Days)),
+		fmt.Sprintf("Breach %s%%", formatDeltaCell(stage.SLABreachRate)),
+	}
+	if stage.RiskTier.Changed {
+		parts = append(parts, fmt.Sprintf("Risk %s", formatStatusCell(stage.RiskTier)))
+	}
+	if stage.Pending.Base != 0 || stage.Pending.Target != 0 {
+		parts = append(parts, fmt.Sprintf("Pending %s", formatDeltaCell(stage.Pending)), fmt.Sprintf("Overdue %s", formatDeltaCell(stage.Overdue)))
+	}
+	if stage.ClearanceStatus.Changed {
+		parts = append(parts, fmt.Sprintf("Clearance %s", formatStatusCell(stage.ClearanceStatus)))
+	}
+	return strings.Join(parts, " | ")
+}
+
+func formatReviewerDiff(reviewer forecast.ReviewerDiff) string {
+	parts := []string{
+		fmt.Sprintf("Avg %s days", formatDeltaCell(reviewer.AverageDays)),
+		fmt.Sprintf("Breach %s%%", formatDeltaCell(reviewer.SLABreachRate)),
+		fmt.Sprintf("Throughput %s/week", formatDeltaCell(reviewer.ThroughputPerWeek)),
+	}
+	if reviewer.Pending.Base != 0 || reviewer.Pending.Target != 0 {
+		parts = append(parts, fmt.Sprintf("Pending %s", formatDeltaCell(reviewer.Pending)), fmt.Sprintf("Overdue %s", formatDeltaCell(reviewer.Overdue)))
+	}
+	if reviewer.ClearanceStatus.Changed {
+		parts = append(parts, fmt.Sprintf("Clearance %s", formatStatusCell(reviewer.ClearanceStatus)))
+	}
+	return strings.Join(parts, " | ")
+}
+
+func formatQueueDiff(queue forecast.QueueDiff) string {
+	line := fmt.Sprintf("Pending %s | Unassigned %s | Due Soon %s | Overdue %s | Avg Age %s days | Projected Past SLA %s",
+		formatDeltaCell(queue.Pending), formatDeltaCell(queue.Unassigned), formatDeltaCell(queue.DueSoon),
+		formatDeltaCell(queue.Overdue), formatDeltaCell(queue.AvgAgeDays), formatDeltaCell(queue.ProjectedPastSLA))
+	if queue.ClearanceStatus.Base != "" || queue.ClearanceStatus.Target != "" {
+		line += fmt.Sprintf(" | Clearance %s", formatStatusCell(queue.ClearanceStatus))
+	}
+	return line
+}
+
+// formatDeltaCell renders "base -> target (+delta)", or just the value when it
+// did not move.
+func formatDeltaCell(delta forecast.MetricDelta) string {
+	if delta.Delta == 0 {
+		return formatNumber(delta.Target)
+	}
+	return fmt.Sprintf("%s -> %s (%s)", formatNumber(delta.Base), formatNumber(delta.Target), formatSigned(delta.Delta))
+}
+
+func formatStatusCell(change forecast.StatusChange) string {
+	if !change.Changed {
+		return orDash(change.Target)
+	}
+	return fmt.Sprintf("%s -> %s", orDash(change.Base), orDash(change.Target))
+}
+
+func formatNumber(value float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", value), "0"), ".")
+}
+
+func formatSigned(value float64) string {
+	if value > 0 {
+		return "+" + formatNumber(value)
+	}
+	return formatNumber(value)
+}
+
+func formatPresence(presence string) string {
+	if presence == forecast.PresenceBoth || presence == "" {
+		return ""
+	}
+	return " (" + presence + ")"
+}
+
+func formatQueueAsOf(side forecast.DiffSide) string {
+	if side.QueueAsOf == "" {
+		return ""
+	}
+	return " | queue as of " + side.QueueAsOf
+}
+
+func changedMark(change forecast.StatusChange) string {
+	if change.Changed {
+		return "changed"
+	}
+	return ""
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func markdownCell(value string) string {
+	return strings.ReplaceAll(value, "|", `\|`)
+}
@@ -0,0 +1,173 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+func TestBuildBriefIncludesQueueAndInsights(t *testing.T) {
+	report := forecast.Report{
+		GeneratedAt: "2026-02-07T12:00:00Z",
+		TotalEvents: 12,
+		SLADays:     10,
+		Overall: forecast.StageStats{
+			AverageDays:       9.2,
+			MedianDays:        8.8,
+			P90Days:           12.1,
+			MaxDays:           14.0,
+			SLABreachCount:    3,
+			SLABreachRate:     25.0,
+			RiskTier:          "medium",
+			DistinctReviewers: 4,
+		},
+		Stages: []forecast.StageStats{
+			{Stage: "initial", AverageDays: 11.2, SLABreachRate: 30.0, RiskTier: "high", Count: 4},
+		},
+		Insights: []forecast.Insight{
+			{Severity: "medium", Area: "overall", Message: "SLA risk is trending up.", Metric: "breach 25.0%"},
+		},
+		Queue: &forecast.QueueReport{
+			TotalPending:    5,
+			AssignedCount:   3,
+			UnassignedCount: 2,
+			AvgAgeDays:      6.2,
+			OnTrackCount:    2,
+			DueSoonCount:    2,
+			OverdueCount:    1,
+			DueSoonRatio:    0.8,
+			PriorityItems: []forecast.QueuePriorityItem{
+				{ApplicationID: "A-100", Stage: "initial", ReviewerID: "rev-1", AgeDays: 9.1, Status: "due soon"},
+			},
+		},
+	}
+	content := BuildBrief(report)
+	if !strings.Contains(content, "Review Queue Ops Brief") {
+		t.Fatalf("expected brief title")
+	}
+	if !strings.Contains(content, "Queue Snapshot") {
+		t.Fatalf("expected queue snapshot section")
+	}
+	if !strings.Contains(content, "Insights") {
+		t.Fatalf("expected insights section")
+	}
+	if !strings.Contains(content, "A-100") {
+		t.Fatalf("expected priority item in brief")
+	}
+}
+
+func TestWriteCSVReportsIncludesInsightsAndPriority(t *testing.T) {
+	report := forecast.Report{
+		GeneratedAt: time.Now().Format(time.RFC3339),
+		TotalEvents: 1,
+		Overall: forecast.StageStats{
+			Stage:          "overall",
+			AverageDays:    2.0,
+			MedianDays:     2.0,
+			P90Days:        2.0,
+			MaxDays:        2.0,
+			SLABreachCount: 0,
+			SLABreachRate:  0,
+			RiskTier:       "low",
+		},
+		Stages: []forecast.StageStats{{Stage: "review", Count: 1}},
+		Reviewers: []forecast.ReviewerStats{{
+			ReviewerID:        "rev-1",
+			AverageDays:       2.0,
+			ThroughputPerWeek: 1.0,
+			SLABreachCount:    0,
+			SLABreachRate:     0,
+		}},
+		SLADays: 10,
+		Throughput: forecast.ThroughputSummary{
+			AsOf:              time.Now().Format(time.RFC3339),
+			WindowDays:        7,
+			EventsInWindow:    1,
+			ThroughputPerWeek: 1.0,
+		},
+		ThroughputTrend: forecast.ThroughputTrendSummary{
+			Trends: []forecast.ThroughputTrend{{Label: "overall"}},
+		},
+		LatencyTrend: forecast.LatencyTrendSummary{
+			Trends: []forecast.LatencyTrend{{Label: "overall"}},
+		},
+		Insights: []forecast.Insight{{Severity: "high", Area: "overall", Message: "test", Metric: "metric"}},
+		Queue: &forecast.QueueReport{
+			TotalPending: 1,
+			PriorityItems: []forecast.QueuePriorityItem{{
+				ApplicationID: "app-1",
+				Stage:         "review",
+				ReviewerID:    "rev-1",
+				SubmittedAt:   time.Now().Format(time.RFC3339),
+				AgeDays:       2.0,
+				DaysToSLA:     8.0,
+				UrgencyScore:  1.2,
+				Status:        "due soon",
+			}},
+		},
+	}
+
+	dir := t.TempDir()
+	if err := WriteCSV(report, dir); err != nil {
+		t.Fatalf("writeCSVReports failed: %v", err)
+	}
+
+	base := filepath.Join(dir, "review-queue")
+	paths := []string{
+		base + "-insights.csv",
+		base + "-queue-priority.csv",
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("expected csv output at %s: %v", path, err)
+		}
+	}
+}
+
+func TestWriteHTMLIsSelfContainedAndEscapesLabels(t *testing.T) {
+	report := forecast.Report{
+		GeneratedAt: "2026-02-07T12:00:00Z",
+		TotalEvents: 4,
+		SLADays:     10,
+		Overall:     forecast.StageStats{Stage: "overall", Count: 4, MedianDays: 6, P90Days: 11, MaxDays: 12, AverageDays: 7, SLADays: 10},
+		Stages: []forecast.StageStats{
+			{Stage: "<script>alert(1)</script>", Count: 4, MedianDays: 6, P90Days: 11, MaxDays: 12, AverageDays: 7, SLADays: 10,
+				AgingBuckets: forecast.AgingBuckets{OnTime: 2, AtRisk: 1, Overdue: 1}},
+		},
+		ThroughputTrend: forecast.ThroughputTrendSummary{
+			WindowDays: 14,
+			Trends:     []forecast.ThroughputTrend{{Label: "overall", CurrentCount: 3, PriorCount: 1}},
+		},
+		Queue: &forecast.QueueReport{
+			TotalPending:    2,
+			ItemProjections: []forecast.QueueItemProjection{{AgeDays: 3}, {AgeDays: 14}},
+			ClearancePlan:   &forecast.QueueClearancePlan{TargetDays: 14, CurrentDaily: 0.5, RequiredDaily: 0.2, Status: "on track"},
+			Simulation:      &forecast.ClearanceSimulation{Trials: 100, P50Days: 3, P80Days: 4, P95Days: 6},
+		},
+	}
+
+	dir := t.TempDir()
+	if err := WriteHTML(report, dir); err != nil {
+		t.Fatalf("WriteHTML failed: %v", err)
+	}
+	payload, err := os.ReadFile(filepath.Join(dir, "review-queue-report.html"))
+	if err != nil {
+		t.Fatalf("expected html output: %v", err)
+	}
+	content := string(payload)
+	if got := strings.Count(content, "<svg"); got != 5 {
+		t.Fatalf("expected 5 charts, got %d", got)
+	}
+	if strings.Contains(content, "<script") {
+		t.Fatalf("expected stage label to be escaped and no scripts in output")
+	}
+	for _, external := range []string{"src=", "href=", "@import", "url("} {
+		if strings.Contains(content, external) {
+			t.Fatalf("expected no external references, found %q", external)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+// WriteHTML writes a single self-contained HTML report with inline SVG charts to
+// output, a file path or directory. The page loads no scripts, fonts, or styles
+// from the network, so it can be archived or emailed as-is.
+func WriteHTML(report forecast.Report, output string) error {
+	path, err := resolveHTMLPath(output)
+	if err != nil {
+		return err
+	}
+	content, err := BuildHTML(report)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+func resolveHTMLPath(output string) (string, error) {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return "", errors.New("html output path is empty")
+	}
+	info, err := os.Stat(output)
+	if err == nil && info.IsDir() {
+		return filepath.Join(output, "review-queue-report.html"), nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if filepath.Ext(output) == "" {
+		return output + ".html", nil
+	}
+	return output, nil
+}
+
+type htmlCard struct {
+	Label string
+	Value string
+	Tone  string
+}
+
+type htmlChart struct {
+	Title   string
+	Caption string
+	SVG     template.HTML
+}
+
+type htmlPage struct {
+	Report   forecast.Report
+	Calendar string
+	Cards    []htmlCard
+	Charts   []htmlChart
+	Priority []forecast.QueuePriorityItem
+}
+
+// BuildHTML renders the HTML report for a report.
+func BuildHTML(report forecast.Report) (string, error) {
+	page := htmlPage{
+		Report:   report,
+		Calendar: formatCalendarSummary(report.Calendar),
+		Cards:    buildHTMLCards(report),
+	}
+
+	page.Charts = append(page.Charts,
+		htmlChart{
+			Title:   "Latency by Stage",
+			Caption: "Whisker spans 0 to max, box spans median to P90, dot marks the average, red tick marks the stage SLA.",
+			SVG:     latencyChart(report.Overall, report.Stages),
+		},
+		htmlChart{
+			Title:   "Aging Buckets",
+			Caption: "Share of completed reviews finished on time, at risk, or past SLA.",
+			SVG:     agingChart(report.Overall, report.Stages),
+		},
+	)
+	if len(report.ThroughputTrend.Trends) > 0 {
+		page.Charts = append(page.Charts, htmlChart{
+			Title:   "Throughput Trend",
+			Caption: fmt.Sprintf("Completed reviews in the last %d days vs the %d days before.", report.ThroughputTrend.WindowDays, report.ThroughputTrend.WindowDays),
+			SVG:     throughputChart(report.ThroughputTrend.Trends),
+		})
+	}
+	if report.Queue != nil {
+		if chart := queueAgeChart(report.Queue, report.SLADays); chart != "" {
+			page.Charts = append(page.Charts, htmlChart{
+				Title:   "Pending Queue Age",
+				Caption: "Pending items by current age; bins past the default SLA are shaded red.",
+				SVG:     chart,
+			})
+		}
+		if chart := clearanceChart(report.Queue); chart != "" {
+			page.Charts = append(page.Charts, htmlChart{
+				Title:   "Clearance Projection",
+				Caption: "Backlog burn-down at the current daily pace and at the pace needed to clear within the target.",
+				SVG:     chart,
+			})
+		}
+		page.Priority = report.Queue.PriorityItems
+		if len(page.Priority) > 10 {
+			page.Priority = page.Priority[:10]
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := htmlTemplate.Execute(&buffer, page); err != nil {
+		return "", fmt.Errorf("render html: %w", err)
+	}
+	return buffer.String(), nil
+}
+
+func buildHTMLCards(report forecast.Report) []htmlCard {
+	cards := []htmlCard{
+		{Label: "Total events", Value: fmt.Sprintf("%d", report.TotalEvents)},
+		{Label: "Median latency", Value: fmt.Sprintf("%.2f days", report.Overall.MedianDays)},
+		{Label: "P90 latency", Value: fmt.Sprintf("%.2f days", report.Overall.P90Days)},
+		{Label: "SLA breach", Value: fmt.Sprintf("%.1f%%", report.Overall.SLABreachRate), Tone: report.Overall.RiskTier},
+	}
+	if queue := report.Queue; queue != nil {
+		cards = append(cards,
+			htmlCard{Label: "Pending", Value: fmt.Sprintf("%d", queue.TotalPending)},
+			htmlCard{Label: "Overdue", Value: fmt.Sprintf("%d", queue.OverdueCount), Tone: toneForCount(queue.OverdueCount)},
+			htmlCard{Label: "Projected past SLA", Value: fmt.Sprintf("%d", queue.ProjectedPastSLA), Tone: toneForCount(queue.ProjectedPastSLA)},
+		)
+		if queue.ClearancePlan != nil {
+			cards = append(cards, htmlCard{Label: "Clearance", Value: queue.ClearancePlan.Status})
+		}
+	}
+	return cards
+}
+
+func toneForCount(count int) string {
+	if count > 0 {
+		return "high"
+	}
+	return "low"
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"upper": strings.ToUpper,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Review Queue Report - {{.Report.GeneratedAt}}</title>
+<style>
+body { margin: 0; padding: 24px; background: #f8f9fa; color: #212529; font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; }
+main { max-width: 760px; margin: 0 auto; }
+h1 { margin: 0 0 4px; font-size: 24px; }
+h2 { margin: 0 0 4px; font-size: 17px; }
+.meta { color: #495057; font-size: 13px; margin-bottom: 20px; }
+.cards { display: grid; grid-template-columns: repeat(auto-fill, minmax(170px, 1fr)); gap: 10px; margin-bottom: 20px; }
+.card { background: #fff; border: 1px solid #dee2e6; border-radius: 6px; padding: 10px 12px; }
+.card .label { color: #868e96; font-size: 12px; text-transform: uppercase; letter-spacing: .04em; }
+.card .value { font-size: 20px; font-weight: 600; margin-top: 2px; }
+.card.high .value { color: #e03131; }
+.card.medium .value { color: #e8590c; }
+section { background: #fff; border: 1px solid #dee2e6; border-radius: 6px; padding: 14px 16px; margin-bottom: 16px; }
+.caption { color: #868e96; font-size: 12px; margin: 0 0 8px; }
+svg { width: 100%; height: auto; display: block; }
+svg text { font-size: 11px; fill: #495057; }
+svg text.label { font-size: 12px; fill: #212529; }
+svg text.inside { fill: #fff; font-weight: 600; }
+table { border-collapse: collapse; width: 100%; font-size: 13px; }
+th, td { text-align: left; padding: 5px 6px; border-bottom: 1px solid #e9ecef; }
+th { color: #868e96; font-weight: 600; font-size: 12px; }
+ul { margin: 0; padding-left: 18px; }
+li { margin: 4px 0; }
+.sev { font-size: 11px; font-weight: 700; }
+.sev.high { color: #e03131; }
+.sev.medium { color: #e8590c; }
+.sev.low { color: #2f9e44; }
+</style>
+</head>
+<body>
+<main>
+<h1>Review Queue Report</h1>
+<div class="meta">Generated {{.Report.GeneratedAt}} &middot; SLA {{.Report.SLADays}} days &middot; {{.Calendar}}{{if .Report.Queue}} &middot; Queue as of {{.Report.Queue.AsOf}}{{end}}</div>
+<div class="cards">
+{{- range .Cards}}
+<div class="card {{.Tone}}"><div class="label">{{.Label}}</div><div class="value">{{.Value}}</div></div>
+{{- end}}
+</div>
+{{- range .Charts}}
+<section>
+<h2>{{.Title}}</h2>
+<p class="caption">{{.Caption}}</p>
+{{.SVG}}
+</section>
+{{- end}}
+{{- if .Priority}}
+<section>
+<h2>Queue Priority</h2>
+<table>
+<tr><th>Application</th><th>Stage</th><th>Reviewer</th><th>Age (days)</th><th>Days to SLA</th><th>Status</th></tr>
+{{- range .Priority}}
+<tr><td>{{.ApplicationID}}</td><td>{{.Stage}}</td><td>{{.ReviewerID}}</td><td>{{printf "%.2f" .AgeDays}}</td><td>{{printf "%.2f" .DaysToSLA}}</td><td>{{.Status}}</td></tr>
+{{- end}}
+</table>
+</section>
+{{- end}}
+<section>
+<h2>Insights</h2>
+{{- if .Report.Insights}}
+<ul>
+{{- range .Report.Insights}}
+<li><span class="sev {{.Severity}}">{{upper .Severity}}</span> {{.Message}} ({{.Metric}})</li>
+{{- end}}
+</ul>
+{{- else}}
+<p class="caption">No critical insights flagged.</p>
+{{- end}}
+</section>
+</main>
+</body>
+</html>
+`))
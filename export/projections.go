@@ -0,0 +1,41 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+// WriteProjections writes per-item projected review dates as JSON (for .json
+// paths) or CSV.
+func WriteProjections(report forecast.Report, output string) error {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return errors.New("projections output path is empty")
+	}
+	if report.Queue == nil {
+		return errors.New("projections output requires --queue")
+	}
+	info, err := os.Stat(output)
+	if err == nil && info.IsDir() {
+		output = filepath.Join(output, "review-queue-projections.csv")
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if filepath.Ext(output) == "" {
+		output += ".csv"
+	}
+	if strings.EqualFold(filepath.Ext(output), ".json") {
+		payload, err := json.MarshalIndent(report.Queue.ItemProjections, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(output, payload, 0644)
+	}
+	return writeQueueProjectionCSV(output, report.Queue)
+}
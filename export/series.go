@@ -0,0 +1,96 @@
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+// WriteSeries renders a metric history for the console with the change from the
+// previous run and a bar scaled to the largest value.
+func WriteSeries(w io.Writer, series forecast.MetricSeries) {
+	fmt.Fprintf(w, "%s for %s %s (%d runs)\n", series.Metric, series.Scope, series.Key, len(series.Points))
+	if len(series.Points) == 0 {
+		fmt.Fprintln(w, "- No stored runs recorded this metric.")
+		return
+	}
+	maxValue := 0.0
+	for _, point := range series.Points {
+		if point.Value != nil && *point.Value > maxValue {
+			maxValue = *point.Value
+		}
+	}
+	var previous *float64
+	for _, point := range series.Points {
+		if point.Value == nil {
+			fmt.Fprintf(w, "- #%d | %s | -\n", point.RunID, point.GeneratedAt)
+			continue
+		}
+		change := ""
+		if previous != nil {
+			change = fmt.Sprintf(" (%s)", formatSigned(math.Round((*point.Value-*previous)*100)/100))
+		}
+		bar := ""
+		if maxValue > 0 && *point.Value > 0 {
+			bar = " " + strings.Repeat("#", int(*point.Value/maxValue*30+0.5))
+		}
+		fmt.Fprintf(w, "- #%d | %s | %s%s%s\n", point.RunID, point.GeneratedAt, formatNumber(*point.Value), change, bar)
+		previous = point.Value
+	}
+}
+
+// WriteSeriesCSV writes a metric history to output, a file path or directory.
+func WriteSeriesCSV(series forecast.MetricSeries, output string) error {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return errors.New("series output path is empty")
+	}
+	info, err := os.Stat(output)
+	if err == nil && info.IsDir() {
+		output = filepath.Join(output, fmt.Sprintf("review-queue-series-%s-%s-%s.csv", series.Scope, forecast.SanitizePath(series.Key), series.Metric))
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if filepath.Ext(output) == "" {
+		output += ".csv"
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{"run_id", "generated_at", "scope", "key", "metric", "value"}); err != nil {
+		return err
+	}
+	for _, point := range series.Points {
+		value := ""
+		if point.Value != nil {
+			value = strconv.FormatFloat(*point.Value, 'f', -1, 64)
+		}
+		record := []string{
+			strconv.FormatInt(point.RunID, 10),
+			point.GeneratedAt,
+			series.Scope,
+			series.Key,
+			series.Metric,
+			value,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,325 @@
+package export
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strings"
+
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+const (
+	chartWidth   = 680.0
+	labelWidth   = 150.0
+	chartPadding = 16.0
+	rowHeight    = 30.0
+	axisHeight   = 28.0
+	plotHeight   = 220.0
+
+	colorOnTime  = "#2f9e44"
+	colorAtRisk  = "#f59f00"
+	colorOverdue = "#e03131"
+	colorCurrent = "#1c7ed6"
+	colorPrior   = "#adb5bd"
+	colorTarget  = "#7048e8"
+	colorMuted   = "#868e96"
+)
+
+// svgBuilder accumulates SVG markup. Text passes through esc so stage and reviewer
+// labels from input files cannot inject markup.
+type svgBuilder struct {
+	strings.Builder
+}
+
+func newSVG(width float64, height float64, title string) *svgBuilder {
+	svg := &svgBuilder{}
+	svg.printf(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %.0f %.0f" role="img" aria-label="%s">`, width, height, esc(title))
+	return svg
+}
+
+func (s *svgBuilder) printf(format string, args ...any) {
+	fmt.Fprintf(&s.Builder, format, args...)
+}
+
+func (s *svgBuilder) text(x float64, y float64, anchor string, class string, value string) {
+	s.printf(`<text x="%.1f" y="%.1f" text-anchor="%s" class="%s">%s</text>`, x, y, anchor, class, esc(value))
+}
+
+func (s *svgBuilder) line(x1, y1, x2, y2 float64, color string, width float64, dashed bool) {
+	dash := ""
+	if dashed {
+		dash = ` stroke-dasharray="4 3"`
+	}
+	s.printf(`<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="%.1f"%s/>`, x1, y1, x2, y2, color, width, dash)
+}
+
+func (s *svgBuilder) rect(x, y, width, height float64, color string, title string) {
+	if width <= 0 || height <= 0 {
+		return
+	}
+	s.printf(`<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s</title></rect>`, x, y, width, height, color, esc(title))
+}
+
+func (s *svgBuilder) legend(x float64, y float64, items [][2]string) {
+	for _, item := range items {
+		s.rect(x, y-9, 10, 10, item[1], item[0])
+		s.text(x+14, y, "start", "legend", item[0])
+		x += 22 + float64(len(item[0]))*6.5
+	}
+}
+
+// xAxis draws ticks under a horizontal plot spanning [left, right] for 0..max.
+func (s *svgBuilder) xAxis(left float64, right float64, y float64, max float64, unit string) {
+	s.line(left, y, right, y, colorMuted, 1, false)
+	for _, tick := range axisTicks(max) {
+		x := left + (right-left)*tick/max
+		s.line(x, y, x, y+4, colorMuted, 1, false)
+		s.text(x, y+16, "middle", "axis", formatTick(tick)+unit)
+	}
+}
+
+// yAxis draws ticks and gridlines for a vertical plot spanning [bottom, top].
+func (s *svgBuilder) yAxis(left float64, right float64, top float64, bottom float64, max float64) {
+	for _, tick := range axisTicks(max) {
+		y := bottom - (bottom-top)*tick/max
+		s.line(left, y, right, y, "#e9ecef", 1, false)
+		s.text(left-6, y+4, "end", "axis", formatTick(tick))
+	}
+	s.line(left, bottom, right, bottom, colorMuted, 1, false)
+}
+
+func (s *svgBuilder) html() template.HTML {
+	s.WriteString("</svg>")
+	return template.HTML(s.String())
+}
+
+func esc(value string) string {
+	return template.HTMLEscapeString(value)
+}
+
+// niceMax rounds a maximum up to 1, 2, 2.5, or 5 times a power of ten so axis
+// ticks land on readable values.
+func niceMax(value float64) float64 {
+	if value <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(value)))
+	for _, step := range []float64{1, 2, 2.5, 5, 10} {
+		if value <= step*magnitude {
+			return step * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+func axisTicks(max float64) []float64 {
+	ticks := make([]float64, 0, 6)
+	for i := 0; i <= 5; i++ {
+		ticks = append(ticks, max*float64(i)/5)
+	}
+	return ticks
+}
+
+func formatTick(value float64) string {
+	if value == math.Trunc(value) {
+		return fmt.Sprintf("%.0f", value)
+	}
+	return fmt.Sprintf("%.1f", value)
+}
+
+// latencyChart draws one row per stage: the whisker spans 0 to max, the box spans
+// median to p90, the dot marks the average, and the red tick marks the SLA.
+func latencyChart(overall forecast.StageStats, stages []forecast.StageStats) template.HTML {
+	rows := append([]forecast.StageStats{overall}, stages...)
+	maxValue := 0.0
+	for _, row := range rows {
+		maxValue = math.Max(maxValue, math.Max(row.MaxDays, float64(row.SLADays)))
+	}
+	maxValue = niceMax(maxValue)
+	height := chartPadding*2 + float64(len(rows))*rowHeight + axisHeight + 20
+	left, right := labelWidth, chartWidth-chartPadding
+	scale := func(days float64) float64 { return left + (right-left)*days/maxValue }
+
+	svg := newSVG(chartWidth, height, "Latency distribution by stage")
+	svg.legend(left, chartPadding, [][2]string{{"median to p90", colorCurrent}, {"SLA", colorOverdue}})
+	for i, row := range rows {
+		y := chartPadding + 16 + float64(i)*rowHeight
+		mid := y + rowHeight/2
+		svg.text(left-8, mid+4, "end", "label", row.Stage)
+		if row.Count == 0 {
+			continue
+		}
+		svg.line(scale(0), mid, scale(row.MaxDays), mid, colorMuted, 1.5, false)
+		svg.rect(scale(row.MedianDays), mid-8, scale(row.P90Days)-scale(row.MedianDays), 16, colorCurrent,
+			fmt.Sprintf("%s: median %.2f, p90 %.2f, max %.2f days", row.Stage, row.MedianDays, row.P90Days, row.MaxDays))
+		svg.printf(`<circle cx="%.1f" cy="%.1f" r="4" fill="#fff" stroke="#212529" stroke-width="1.5"><title>avg %.2f days</title></circle>`, scale(row.AverageDays), mid, row.AverageDays)
+		if row.SLADays > 0 {
+			svg.line(scale(float64(row.SLADays)), mid-11, scale(float64(row.SLADays)), mid+11, colorOverdue, 2, false)
+		}
+	}
+	svg.xAxis(left, right, chartPadding+16+float64(len(rows))*rowHeight+4, maxValue, "d")
+	return svg.html()
+}
+
+// agingChart draws 100% stacked bars of on-time, at-risk, and overdue reviews.
+func agingChart(overall forecast.StageStats, stages []forecast.StageStats) template.HTML {
+	rows := append([]forecast.StageStats{overall}, stages...)
+	height := chartPadding*2 + float64(len(rows))*rowHeight + 20
+	left, right := labelWidth, chartWidth-chartPadding-50
+
+	svg := newSVG(chartWidth, height, "Aging buckets by stage")
+	svg.legend(left, chartPadding, [][2]string{{"on time", colorOnTime}, {"at risk", colorAtRisk}, {"overdue", colorOverdue}})
+	for i, row := range rows {
+		y := chartPadding + 16 + float64(i)*rowHeight
+		svg.text(left-8, y+rowHeight/2+4, "end", "label", row.Stage)
+		total := row.AgingBuckets.OnTime + row.AgingBuckets.AtRisk + row.AgingBuckets.Overdue
+		if total == 0 {
+			continue
+		}
+		x := left
+		for _, segment := range []struct {
+			name  string
+			count int
+			color string
+		}{
+			{"on time", row.AgingBuckets.OnTime, colorOnTime},
+			{"at risk", row.AgingBuckets.AtRisk, colorAtRisk},
+			{"overdue", row.AgingBuckets.Overdue, colorOverdue},
+		} {
+			width := (right - left) * float64(segment.count) / float64(total)
+			svg.rect(x, y+5, width, rowHeight-10, segment.color, fmt.Sprintf("%s %s: %d (%.1f%%)", row.Stage, segment.name, segment.count, forecast.Percent(segment.count, total)))
+			if width >= 24 {
+				svg.text(x+width/2, y+rowHeight/2+4, "middle", "inside", fmt.Sprintf("%d", segment.count))
+			}
+			x += width
+		}
+		svg.text(right+6, y+rowHeight/2+4, "start", "axis", fmt.Sprintf("n=%d", total))
+	}
+	return svg.html()
+}
+
+// throughputChart draws grouped bars comparing completed reviews in the current and
+// prior windows.
+func throughputChart(trends []forecast.ThroughputTrend) template.HTML {
+	maxValue := 0.0
+	for _, trend := range trends {
+		maxValue = math.Max(maxValue, float64(max(trend.CurrentCount, trend.PriorCount)))
+	}
+	maxValue = niceMax(math.Max(maxValue, 5))
+	left, right := 48.0, chartWidth-chartPadding
+	top, bottom := chartPadding+24, chartPadding+24+plotHeight
+	height := bottom + axisHeight + chartPadding
+	group := (right - left) / float64(max(len(trends), 1))
+	barWidth := math.Min(40, group*0.35)
+	scale := func(value float64) float64 { return (bottom - top) * value / maxValue }
+
+	svg := newSVG(chartWidth, height, "Throughput, current vs prior window")
+	svg.legend(left, chartPadding, [][2]string{{"prior window", colorPrior}, {"current window", colorCurrent}})
+	svg.yAxis(left, right, top, bottom, maxValue)
+	for i, trend := range trends {
+		center := left + group*float64(i) + group/2
+		prior, current := scale(float64(trend.PriorCount)), scale(float64(trend.CurrentCount))
+		svg.rect(center-barWidth-2, bottom-prior, barWidth, prior, colorPrior, fmt.Sprintf("%s prior: %d", trend.Label, trend.PriorCount))
+		svg.rect(center+2, bottom-current, barWidth, current, colorCurrent, fmt.Sprintf("%s current: %d (%+.1f%%)", trend.Label, trend.CurrentCount, trend.DeltaPercent))
+		svg.text(center, bottom+16, "middle", "label", trend.Label)
+	}
+	return svg.html()
+}
+
+// queueAgeChart bins pending item ages and marks the default SLA.
+func queueAgeChart(queue *forecast.QueueReport, slaDays int) template.HTML {
+	if queue == nil || len(queue.ItemProjections) == 0 {
+		return ""
+	}
+	maxAge := float64(slaDays)
+	for _, item := range queue.ItemProjections {
+		maxAge = math.Max(maxAge, item.AgeDays)
+	}
+	binWidth := niceMax(maxAge / 8)
+	bins := int(math.Floor(maxAge/binWidth)) + 1
+	counts := make([]int, bins)
+	for _, item := range queue.ItemProjections {
+		counts[min(int(item.AgeDays/binWidth), bins-1)]++
+	}
+	maxCount := 0
+	for _, count := range counts {
+		maxCount = max(maxCount, count)
+	}
+	yMax := niceMax(math.Max(float64(maxCount), 5))
+	left, right := 48.0, chartWidth-chartPadding
+	top, bottom := chartPadding+24, chartPadding+24+plotHeight
+	height := bottom + axisHeight + chartPadding
+	xMax := binWidth * float64(bins)
+	xScale := func(days float64) float64 { return left + (right-left)*days/xMax }
+
+	svg := newSVG(chartWidth, height, "Pending queue age histogram")
+	svg.legend(left, chartPadding, [][2]string{{"pending items", colorCurrent}, {fmt.Sprintf("SLA (%d days)", slaDays), colorOverdue}})
+	svg.yAxis(left, right, top, bottom, yMax)
+	for i, count := range counts {
+		start := binWidth * float64(i)
+		barHeight := (bottom - top) * float64(count) / yMax
+		color := colorCurrent
+		if slaDays > 0 && start >= float64(slaDays) {
+			color = colorOverdue
+		}
+		svg.rect(xScale(start)+1, bottom-barHeight, xScale(start+binWidth)-xScale(start)-2, barHeight, color,
+			fmt.Sprintf("%s-%s days: %d items", formatTick(start), formatTick(start+binWidth), count))
+		svg.text(xScale(start), bottom+16, "middle", "axis", formatTick(start))
+	}
+	svg.text(right, bottom+16, "end", "axis", formatTick(xMax)+"d")
+	if slaDays > 0 {
+		svg.line(xScale(float64(slaDays)), top, xScale(float64(slaDays)), bottom, colorOverdue, 1.5, true)
+	}
+	return svg.html()
+}
+
+// clearanceChart projects the backlog burning down at the current pace and at the
+// pace needed to hit the clearance target, with simulated P50/P80/P95 clear days.
+func clearanceChart(queue *forecast.QueueReport) template.HTML {
+	if queue == nil || queue.ClearancePlan == nil || queue.TotalPending == 0 {
+		return ""
+	}
+	plan := queue.ClearancePlan
+	pending := float64(queue.TotalPending)
+	horizon := float64(plan.TargetDays) * 2
+	currentClear := math.Inf(1)
+	if plan.CurrentDaily > 0 {
+		currentClear = pending / plan.CurrentDaily
+		horizon = math.Max(horizon, math.Min(currentClear*1.1, float64(plan.TargetDays)*6))
+	}
+	if queue.Simulation != nil && queue.Simulation.Trials > 0 {
+		horizon = math.Max(horizon, queue.Simulation.P95Days*1.1)
+	}
+	horizon = niceMax(horizon)
+	yMax := niceMax(math.Max(pending, 5))
+	left, right := 48.0, chartWidth-chartPadding
+	top, bottom := chartPadding+24, chartPadding+24+plotHeight
+	height := bottom + axisHeight + chartPadding
+	x := func(days float64) float64 { return left + (right-left)*math.Min(days, horizon)/horizon }
+	y := func(items float64) float64 { return bottom - (bottom-top)*math.Max(items, 0)/yMax }
+
+	svg := newSVG(chartWidth, height, "Queue clearance projection")
+	svg.legend(left, chartPadding, [][2]string{{"current pace", colorCurrent}, {fmt.Sprintf("target pace (%d days)", plan.TargetDays), colorTarget}, {"simulated P50/P80/P95", colorMuted}})
+	svg.yAxis(left, right, top, bottom, yMax)
+	svg.xAxis(left, right, bottom, horizon, "d")
+
+	if math.IsInf(currentClear, 1) {
+		svg.line(x(0), y(pending), x(horizon), y(pending), colorCurrent, 2.5, false)
+	} else {
+		end := math.Min(currentClear, horizon)
+		svg.line(x(0), y(pending), x(end), y(pending-plan.CurrentDaily*end), colorCurrent, 2.5, false)
+	}
+	svg.line(x(0), y(pending), x(float64(plan.TargetDays)), y(0), colorTarget, 2, true)
+
+	if sim := queue.Simulation; sim != nil && sim.Trials > 0 {
+		for _, marker := range []struct {
+			label string
+			days  float64
+		}{{"P50", sim.P50Days}, {"P80", sim.P80Days}, {"P95", sim.P95Days}} {
+			svg.line(x(marker.days), top, x(marker.days), bottom, colorMuted, 1, true)
+			svg.text(x(marker.days)+3, top+10, "start", "axis", marker.label)
+		}
+	}
+	return svg.html()
+}
@@ -0,0 +1,412 @@
+package forecast
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Aggregator builds a report from events added one at a time, so callers can
+// stream a history of any length. Lifetime stage and reviewer statistics use
+// incremental accumulators and quantile sketches; only events recent enough to
+// land in the current or prior throughput window are retained, and windows are
+// counted once the as-of date is known.
+type Aggregator struct {
+	opts      Options
+	count     int
+	overall   latencyAccumulator
+	stages    map[string]*stageAccumulator
+	reviewers map[string]*reviewerAccumulator
+	latest    time.Time
+	recent    []windowEvent
+	pruneAt   int
+	journeys  *journeyAccumulator
+	arrivals  *arrivalHistory
+}
+
+type latencyAccumulator struct {
+	count    int
+	sum      float64
+	max      float64
+	breaches int
+	buckets  AgingBuckets
+	sketch   quantileSketch
+}
+
+type stageAccumulator struct {
+	latencyAccumulator
+	name      string
+	reviewers map[string]struct{}
+}
+
+type reviewerAccumulator struct {
+	latencyAccumulator
+	name         string
+	lastReviewed time.Time
+}
+
+// windowEvent is the part of an event the throughput and latency windows need.
+// Stage and reviewer reuse the accumulators' names rather than each row's copy.
+type windowEvent struct {
+	stage      string
+	reviewer   string
+	reviewedAt time.Time
+	days       float64
+}
+
+const minPruneAt = 4096
+
+// NewAggregator starts an empty aggregation with the given options. With
+// opts.Journeys set it also keeps a compact step list per application, the one
+// part of the aggregation that grows with the history; opts.Arrivals adds a
+// count per stage and submission day.
+func NewAggregator(opts Options) *Aggregator {
+	a := &Aggregator{
+		opts:      opts.withDefaults(),
+		stages:    map[string]*stageAccumulator{},
+		reviewers: map[string]*reviewerAccumulator{},
+		pruneAt:   minPruneAt,
+	}
+	if opts.Journeys {
+		a.journeys = newJourneyAccumulator()
+	}
+	if opts.Arrivals {
+		a.arrivals = newArrivalHistory(a.opts.Calendar.location())
+	}
+	return a
+}
+
+// Count reports how many events have been added.
+func (a *Aggregator) Count() int {
+	return a.count
+}
+
+// Add folds one completed review into the aggregation.
+func (a *Aggregator) Add(event ReviewEvent) {
+	policy := a.opts.Policy
+	days := a.opts.Calendar.Days(event.SubmittedAt, event.ReviewedAt)
+	threshold := policy.Resolve(event.Stage, event.Program, event.Priority)
+	a.count++
+	a.overall.add(days, threshold)
+	if a.journeys != nil {
+		a.journeys.add(event)
+	}
+	if a.arrivals != nil {
+		a.arrivals.add(event.Stage, event.SubmittedAt)
+	}
+
+	stage, ok := a.stages[event.Stage]
+	if !ok {
+		stage = &stageAccumulator{name: event.Stage, reviewers: map[string]struct{}{}}
+		a.stages[event.Stage] = stage
+	}
+	stage.add(days, threshold)
+	if event.ReviewerID != "" {
+		stage.reviewers[event.ReviewerID] = struct{}{}
+	}
+
+	reviewerID := strings.TrimSpace(event.ReviewerID)
+	if reviewerID == "" {
+		reviewerID = "unassigned"
+	}
+	reviewer, ok := a.reviewers[reviewerID]
+	if !ok {
+		reviewer = &reviewerAccumulator{name: reviewerID, lastReviewed: event.ReviewedAt}
+		a.reviewers[reviewerID] = reviewer
+	}
+	reviewer.add(days, threshold)
+	if event.ReviewedAt.After(reviewer.lastReviewed) {
+		reviewer.lastReviewed = event.ReviewedAt
+	}
+
+	if a.count == 1 || event.ReviewedAt.After(a.latest) {
+		a.latest = event.ReviewedAt
+	}
+	if !a.inHorizon(event.ReviewedAt) {
+		return
+	}
+	a.recent = append(a.recent, windowEvent{
+		stage:      stage.name,
+		reviewer:   reviewer.name,
+		reviewedAt: event.ReviewedAt,
+		days:       days,
+	})
+	if len(a.recent) >= a.pruneAt {
+		a.prune()
+	}
+}
+
+// Report finishes the aggregation and builds the full report, forecasting
+// queueItems against the aggregated throughput.
+func (a *Aggregator) Report(ctx context.Context, queueItems []QueueItem) (Report, error) {
+	if err := ctx.Err(); err != nil {
+		return Report{}, err
+	}
+	opts := a.opts
+	policy, cal, throughputDays := opts.Policy, opts.Calendar, opts.ThroughputDays
+
+	asOf := opts.AsOf
+	if asOf.IsZero() {
+		if a.count == 0 {
+			return Report{}, errors.New("no events to resolve as-of date")
+		}
+		asOf = a.latest
+	}
+	// Windows and day boundaries are anchored in the calendar's zone, so a
+	// 28-day window or a simulated clear date follows local midnights.
+	location := cal.location()
+	asOf = asOf.In(location)
+	a.prune()
+
+	// Pending items are reviews still in progress; their ages feed the
+	// censored-aware survival estimates next to the completed-only stats.
+	censored := map[string][]float64{}
+	for _, item := range queueItems {
+		age := max(cal.Days(item.SubmittedAt, asOf), 0)
+		censored[item.Stage] = append(censored[item.Stage], age)
+		censored[""] = append(censored[""], age)
+	}
+
+	stages := make([]StageStats, 0, len(a.stages))
+	stageNames := make([]string, 0, len(a.stages))
+	for name, acc := range a.stages {
+		stageNames = append(stageNames, name)
+		stats := acc.stats(name, policy.StageThreshold(name).SLADays)
+		if len(queueItems) > 0 {
+			stats.Survival = buildSurvivalEstimate(&acc.latencyAccumulator, censored[name], stats.SLADays)
+		}
+		stages = append(stages, stats)
+	}
+	sort.Slice(stages, func(i, j int) bool {
+		return stages[i].AverageDays > stages[j].AverageDays
+	})
+	overall := (&stageAccumulator{latencyAccumulator: a.overall, reviewers: a.distinctReviewers()}).stats("overall", policy.Default.SLADays)
+	if len(queueItems) > 0 {
+		overall.Survival = buildSurvivalEstimate(&a.overall, censored[""], policy.Default.SLADays)
+	}
+
+	window := newThroughputWindow(asOf, throughputDays)
+	for _, event := range a.recent {
+		window.add(event.stage, event.reviewer, event.reviewedAt)
+	}
+	reviewers := make([]ReviewerStats, 0, len(a.reviewers))
+	for reviewerID, acc := range a.reviewers {
+		reviewers = append(reviewers, acc.stats(reviewerID, window.reviewers[reviewerID], throughputDays, policy.Default.SLADays, location))
+	}
+	sortReviewerStats(reviewers)
+
+	throughput := ThroughputSummary{
+		AsOf:              asOf.Format(time.RFC3339),
+		WindowDays:        throughputDays,
+		EventsInWindow:    window.total,
+		ThroughputPerWeek: Round(float64(window.total)/(float64(throughputDays)/7.0), 2),
+	}
+	trend := buildThroughputTrends(a.recent, asOf, throughputDays)
+	latencyTrend := buildLatencyTrends(a.recent, asOf, throughputDays)
+	queueReport, err := buildQueueReport(ctx, queueItems, window, a.arrivals.withQueue(queueItems), reviewers, asOf, opts)
+	if err != nil {
+		return Report{}, err
+	}
+	insights := BuildInsights(overall, stages, trend, latencyTrend, queueReport, policy.Default.SLADays)
+	var journeys *JourneyReport
+	if a.journeys != nil {
+		journeys = a.journeys.report(queueItems, asOf, opts)
+	}
+
+	return Report{
+		GeneratedAt:     time.Now().Format(time.RFC3339),
+		TotalEvents:     a.count,
+		Overall:         overall,
+		Stages:          stages,
+		Reviewers:       reviewers,
+		SLADays:         policy.Default.SLADays,
+		SLAPolicy:       policy.EffectivePolicies(collectStages(stageNames, queueItems)),
+		SLAPolicySource: policy.Source,
+		Timezone:        location.String(),
+		Calendar:        cal.Summary(),
+		Throughput:      throughput,
+		ThroughputTrend: trend,
+		LatencyTrend:    latencyTrend,
+		Insights:        insights,
+		Queue:           queueReport,
+		Journeys:        journeys,
+	}, nil
+}
+
+// horizon is the earliest reviewed_at that can still fall in the prior window.
+// Without a fixed as-of date it trails the latest event seen, which only moves
+// forward, so anything older can be dropped for good.
+func (a *Aggregator) horizon() time.Time {
+	reference := a.opts.AsOf
+	if reference.IsZero() {
+		reference = a.latest
+	}
+	return reference.In(a.opts.Calendar.location()).AddDate(0, 0, -2*a.opts.ThroughputDays)
+}
+
+func (a *Aggregator) inHorizon(reviewedAt time.Time) bool {
+	if !a.opts.AsOf.IsZero() && reviewedAt.After(a.opts.AsOf) {
+		return false
+	}
+	return !reviewedAt.Before(a.horizon())
+}
+
+// prune drops retained events that fell behind the horizon and grows the next
+// prune threshold with what is left, keeping the amortized cost per event flat.
+func (a *Aggregator) prune() {
+	horizon := a.horizon()
+	kept := a.recent[:0]
+	for _, event := range a.recent {
+		if !event.reviewedAt.Before(horizon) {
+			kept = append(kept, event)
+		}
+	}
+	clear(a.recent[len(kept):])
+	a.recent = kept
+	a.pruneAt = max(2*len(kept), minPruneAt)
+}
+
+func (a *Aggregator) distinctReviewers() map[string]struct{} {
+	reviewers := map[string]struct{}{}
+	for _, stage := range a.stages {
+		for reviewerID := range stage.reviewers {
+			reviewers[reviewerID] = struct{}{}
+		}
+	}
+	return reviewers
+}
+
+func (acc *latencyAccumulator) add(days float64, threshold SLAThreshold) {
+	acc.addDuration(days)
+	if addLatencyBuckets(&acc.buckets, days, threshold) {
+		acc.breaches++
+	}
+}
+
+// addDuration tracks count, mean, max, and quantiles without SLA buckets.
+func (acc *latencyAccumulator) addDuration(days float64) {
+	if acc.count == 0 || days > acc.max {
+		acc.max = days
+	}
+	acc.count++
+	acc.sum += days
+	acc.sketch.add(days)
+}
+
+func (acc *latencyAccumulator) average() float64 {
+	if acc.count == 0 {
+		return 0
+	}
+	return acc.sum / float64(acc.count)
+}
+
+func (acc *stageAccumulator) stats(stage string, slaDays int) StageStats {
+	if acc.count == 0 {
+		return StageStats{Stage: stage, SLADays: slaDays}
+	}
+	avg := acc.average()
+	breachRate := float64(acc.breaches) / float64(acc.count)
+	return StageStats{
+		Stage:             stage,
+		SLADays:           slaDays,
+		Count:             acc.count,
+		AverageDays:       Round(avg, 2),
+		MedianDays:        Round(acc.sketch.quantile(50), 2),
+		P90Days:           Round(acc.sketch.quantile(90), 2),
+		MaxDays:           Round(acc.max, 2),
+		SLABreachCount:    acc.breaches,
+		SLABreachRate:     Round(breachRate*100, 1),
+		DistinctReviewers: len(acc.reviewers),
+		AgingBuckets:      acc.buckets,
+		RiskTier:          classifyRisk(avg, breachRate, slaDays),
+	}
+}
+
+func (acc *reviewerAccumulator) stats(reviewerID string, windowCount int, throughputDays int, slaDays int, location *time.Location) ReviewerStats {
+	avg := acc.average()
+	breachRate := float64(acc.breaches) / float64(acc.count)
+	return ReviewerStats{
+		ReviewerID:        reviewerID,
+		Count:             acc.count,
+		AverageDays:       Round(avg, 2),
+		MedianDays:        Round(acc.sketch.quantile(50), 2),
+		P90Days:           Round(acc.sketch.quantile(90), 2),
+		MaxDays:           Round(acc.max, 2),
+		SLABreachCount:    acc.breaches,
+		SLABreachRate:     Round(breachRate*100, 1),
+		LastReviewedAt:    acc.lastReviewed.In(location).Format(time.RFC3339),
+		ThroughputPerWeek: Round(float64(windowCount)/(float64(throughputDays)/7.0), 2),
+		WindowCount:       windowCount,
+		AgingBuckets:      acc.buckets,
+		RiskTier:          classifyRisk(avg, breachRate, slaDays),
+	}
+}
+
+// throughputWindow counts completed reviews in [asOf-days, asOf] overall, by
+// stage, and by reviewer, along with per-day samples for the simulation. Days
+// are calendar dates in asOf's location, so samples split at local midnight.
+type throughputWindow struct {
+	start     time.Time
+	end       time.Time
+	days      int
+	total     int
+	stages    map[string]int
+	reviewers map[string]int
+	samples   map[string][]int
+}
+
+func newThroughputWindow(asOf time.Time, days int) *throughputWindow {
+	return &throughputWindow{
+		start:     asOf.AddDate(0, 0, -days),
+		end:       asOf,
+		days:      days,
+		stages:    map[string]int{},
+		reviewers: map[string]int{},
+		samples:   map[string][]int{},
+	}
+}
+
+// add counts a review; reviewerID is already trimmed, with blanks as unassigned.
+func (w *throughputWindow) add(stage string, reviewerID string, reviewedAt time.Time) {
+	if w.days <= 0 || reviewedAt.Before(w.start) || reviewedAt.After(w.end) {
+		return
+	}
+	w.total++
+	w.stages[stage]++
+	w.reviewers[reviewerID]++
+	day := daysBetween(reviewedAt, w.end)
+	if day >= w.days {
+		day = w.days - 1
+	}
+	for _, key := range []string{"", stage} {
+		samples, ok := w.samples[key]
+		if !ok {
+			samples = make([]int, w.days)
+			w.samples[key] = samples
+		}
+		samples[day]++
+	}
+}
+
+// dailySamples returns reviews per day across the window, newest day first. An
+// empty stage includes every stage.
+func (w *throughputWindow) dailySamples(stage string) []int {
+	if w.days <= 0 {
+		return nil
+	}
+	if samples, ok := w.samples[stage]; ok {
+		return samples
+	}
+	return make([]int, w.days)
+}
+
+// daysBetween counts calendar dates from start to end in end's location.
+func daysBetween(start time.Time, end time.Time) int {
+	startYear, startMonth, startDay := start.In(end.Location()).Date()
+	endYear, endMonth, endDay := end.Date()
+	from := time.Date(startYear, startMonth, startDay, 0, 0, 0, 0, time.UTC)
+	to := time.Date(endYear, endMonth, endDay, 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
+}
@@ -0,0 +1,280 @@
+package forecast
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	// arrivalForecastDays is how many forecast days a report lists.
+	arrivalForecastDays = 28
+	// arrivalWeekdayDays is how much recent history sets weekday factors.
+	arrivalWeekdayDays = 182
+	// arrivalSeasonDays is the half-width, in days, of the prior-year window
+	// compared against each forecast day.
+	arrivalSeasonDays = 3
+)
+
+// arrivalHistory counts submissions per local calendar day, overall and by
+// stage, from events and queue items. Days are numbered from the Unix epoch in
+// the calendar's zone, so the counts stay small however long the history.
+type arrivalHistory struct {
+	location *time.Location
+	counts   map[string]map[int]int
+	first    int
+	seen     bool
+}
+
+func newArrivalHistory(location *time.Location) *arrivalHistory {
+	return &arrivalHistory{location: location, counts: map[string]map[int]int{}}
+}
+
+func (h *arrivalHistory) add(stage string, submittedAt time.Time) {
+	day := dayNumber(submittedAt, h.location)
+	if !h.seen || day < h.first {
+		h.first = day
+		h.seen = true
+	}
+	for _, key := range []string{"", stage} {
+		counts, ok := h.counts[key]
+		if !ok {
+			counts = map[int]int{}
+			h.counts[key] = counts
+		}
+		counts[day]++
+	}
+}
+
+// withQueue returns a copy of the history that also counts the pending items,
+// leaving h untouched so a report can be built more than once.
+func (h *arrivalHistory) withQueue(queueItems []QueueItem) *arrivalHistory {
+	if h == nil {
+		return nil
+	}
+	clone := &arrivalHistory{location: h.location, counts: make(map[string]map[int]int, len(h.counts)), first: h.first, seen: h.seen}
+	for key, counts := range h.counts {
+		copied := make(map[int]int, len(counts))
+		for day, count := range counts {
+			copied[day] = count
+		}
+		clone.counts[key] = copied
+	}
+	for _, item := range queueItems {
+		clone.add(item.Stage, item.SubmittedAt)
+	}
+	return clone
+}
+
+// arrivalModel is a stage's arrival forecast: expected submissions for each
+// day after the as-of date, up to the simulation horizon.
+type arrivalModel struct {
+	daily   []float64
+	summary *ArrivalForecast
+}
+
+// forecast projects arrivals for a stage (empty for every stage). The level is
+// the mean daily count over the recentDays ending on the as-of date. Each
+// future day scales it by its weekday's share of recent arrivals and, where the
+// history reaches back a year or more, by how that time of year compared with
+// the same point in earlier years. It returns nil without any history.
+func (h *arrivalHistory) forecast(stage string, asOf time.Time, recentDays int, throughputDaily float64) *arrivalModel {
+	if h == nil || !h.seen {
+		return nil
+	}
+	counts := h.counts[stage]
+	end := dayNumber(asOf, h.location)
+	if recentDays <= 0 {
+		recentDays = arrivalForecastDays
+	}
+	level := h.mean(counts, end-recentDays+1, end)
+
+	var weekday [7]float64
+	weekdayStart := max(h.first, end-arrivalWeekdayDays+1)
+	weekdayMean := h.mean(counts, weekdayStart, end)
+	for i := range weekday {
+		weekday[i] = 1
+	}
+	if end-weekdayStart+1 >= 14 && weekdayMean > 0 {
+		var sums, days [7]float64
+		for day := weekdayStart; day <= end; day++ {
+			sums[weekdayOf(day)] += float64(counts[day])
+			days[weekdayOf(day)]++
+		}
+		for i := range weekday {
+			weekday[i] = sums[i] / days[i] / weekdayMean
+		}
+	}
+
+	daily := make([]float64, simulationHorizonDays)
+	seasonal := false
+	for offset := range daily {
+		day := end + 1 + offset
+		factor, ok := h.season(counts, day, end, recentDays)
+		seasonal = seasonal || ok
+		daily[offset] = level * weekday[weekdayOf(day)] * factor
+	}
+
+	summary := &ArrivalForecast{
+		HistoryDays:    end - h.first + 1,
+		RecentDaily:    Round(level, 2),
+		WeekdayFactors: make([]float64, 7),
+		Seasonal:       seasonal,
+		Daily:          make([]ArrivalDay, 0, arrivalForecastDays),
+	}
+	for i := range summary.WeekdayFactors {
+		// Monday first; time.Weekday counts from Sunday.
+		summary.WeekdayFactors[i] = Round(weekday[(i+1)%7], 2)
+	}
+	var next7, next28 float64
+	for offset := 0; offset < arrivalForecastDays; offset++ {
+		if offset < 7 {
+			next7 += daily[offset]
+		}
+		next28 += daily[offset]
+		summary.Daily = append(summary.Daily, ArrivalDay{
+			Date:     asOf.AddDate(0, 0, offset+1).Format("2006-01-02"),
+			Expected: Round(daily[offset], 2),
+		})
+	}
+	summary.Next7Days = Round(next7, 2)
+	summary.Next28Days = Round(next28, 2)
+	summary.ForecastDaily = Round(next28/arrivalForecastDays, 2)
+	summary.NetDaily = Round(next28/arrivalForecastDays-throughputDaily, 2)
+	return &arrivalModel{daily: daily, summary: summary}
+}
+
+// season compares arrivals around the same day in earlier years with the level
+// at the matching as-of date, averaged over every year the history covers.
+func (h *arrivalHistory) season(counts map[int]int, day int, end int, recentDays int) (float64, bool) {
+	total, years := 0.0, 0
+	for year := 1; ; year++ {
+		shift := year * 365
+		if day-shift-arrivalSeasonDays < h.first || end-shift-recentDays+1 < h.first {
+			break
+		}
+		baseline := h.mean(counts, end-shift-recentDays+1, end-shift)
+		if baseline == 0 {
+			continue
+		}
+		total += h.mean(counts, day-shift-arrivalSeasonDays, day-shift+arrivalSeasonDays) / baseline
+		years++
+	}
+	if years == 0 {
+		return 1, false
+	}
+	return total / float64(years), true
+}
+
+// mean is the average daily count over [from, to], ignoring days before the
+// history began.
+func (h *arrivalHistory) mean(counts map[int]int, from int, to int) float64 {
+	from = max(from, h.first)
+	if to < from {
+		return 0
+	}
+	total := 0
+	for day := from; day <= to; day++ {
+		total += counts[day]
+	}
+	return float64(total) / float64(to-from+1)
+}
+
+// days returns the daily forecast, or nil without a model.
+func (m *arrivalModel) days() []float64 {
+	if m == nil {
+		return nil
+	}
+	return m.daily
+}
+
+// expected totals the forecast over the next days, or zero without a model.
+func (m *arrivalModel) expected(days int) float64 {
+	if m == nil || days <= 0 {
+		return 0
+	}
+	return sumArrivals(m.daily, days)
+}
+
+func (m *arrivalModel) forecast() *ArrivalForecast {
+	if m == nil {
+		return nil
+	}
+	return m.summary
+}
+
+// estimateClearance returns the days to clear pending items at dailyThroughput
+// and the clearance status. With a model the backlog keeps growing by the
+// forecast arrivals, and one that never clears is capped at the horizon.
+func estimateClearance(pending int, dailyThroughput float64, model *arrivalModel) (float64, string) {
+	estimatedClear := float64(pending) / dailyThroughput
+	if model != nil {
+		days, cleared := clearDaysWithArrivals(pending, dailyThroughput, model.daily)
+		if !cleared {
+			return simulationHorizonDays, "backlog growing"
+		}
+		estimatedClear = days
+	}
+	switch {
+	case estimatedClear <= 7:
+		return estimatedClear, "healthy"
+	case estimatedClear <= 14:
+		return estimatedClear, "watch"
+	default:
+		return estimatedClear, "at risk"
+	}
+}
+
+// clearDaysWithArrivals steps the backlog forward a day at a time, adding
+// forecast arrivals and removing dailyThroughput reviews, and returns when it
+// first reaches zero. It reports false if the backlog outlasts the forecast.
+func clearDaysWithArrivals(pending int, dailyThroughput float64, arrivals []float64) (float64, bool) {
+	backlog := float64(pending)
+	for day, expected := range arrivals {
+		next := backlog + expected - dailyThroughput
+		if next <= 0 {
+			// Interpolate within the day so a fixed backlog matches
+			// pending / dailyThroughput.
+			return float64(day) + backlog/(backlog-next), true
+		}
+		backlog = next
+	}
+	return 0, false
+}
+
+// sumArrivals totals the first days of a forecast.
+func sumArrivals(arrivals []float64, days int) float64 {
+	total := 0.0
+	for _, expected := range arrivals[:min(days, len(arrivals))] {
+		total += expected
+	}
+	return total
+}
+
+// poisson draws a Poisson count with the given mean, switching to a normal
+// approximation for large means.
+func poisson(rng *rand.Rand, mean float64) int {
+	if mean <= 0 {
+		return 0
+	}
+	if mean > 30 {
+		return max(int(math.Round(mean+math.Sqrt(mean)*rng.NormFloat64())), 0)
+	}
+	limit := math.Exp(-mean)
+	count := 0
+	for product := rng.Float64(); product > limit; product *= rng.Float64() {
+		count++
+	}
+	return count
+}
+
+// dayNumber numbers local calendar dates from 1970-01-01.
+func dayNumber(value time.Time, location *time.Location) int {
+	year, month, day := value.In(location).Date()
+	return int(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
+
+// weekdayOf is the time.Weekday of a day number; 1970-01-01 was a Thursday.
+func weekdayOf(day int) int {
+	return ((day+4)%7 + 7) % 7
+}
@@ -0,0 +1,514 @@
+package forecast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Backtest sources: replayed event history or stored runs.
+const (
+	BacktestEvents = "events"
+	BacktestRuns   = "runs"
+)
+
+// Accuracy metrics. Clear days compare a stage or reviewer's estimated clear
+// time with when its last pending item was reviewed, or, for stages forecast
+// with arrivals, with when the stage first had nothing pending; item days
+// compare each item's projected review with its actual review.
+const (
+	MetricClearDays = "clear_days"
+	MetricItemDays  = "item_days"
+)
+
+// BacktestOptions picks the as-of dates a backtest replays. Start defaults to
+// one throughput window after the first review, so every replay has a full
+// window behind it. End defaults to SettleDays before the last review, which
+// leaves time for the items pending at the last as-of date to be reviewed; a
+// SettleDays of zero replays up to the last review, and a negative one uses
+// the default.
+type BacktestOptions struct {
+	Start      time.Time
+	End        time.Time
+	EveryDays  int
+	SettleDays int
+}
+
+// DefaultBacktestOptions mirrors the CLI defaults: an as-of date every 7 days
+// and a 28-day settle window.
+func DefaultBacktestOptions() BacktestOptions {
+	return BacktestOptions{EveryDays: 7, SettleDays: 28}
+}
+
+// BacktestReport scores past queue forecasts against what happened next.
+// Points lists each replayed as-of date (or stored run) and Accuracy the error
+// per scope, key, and metric.
+type BacktestReport struct {
+	Source         string             `json:"source"`
+	Start          string             `json:"start"`
+	End            string             `json:"end"`
+	EveryDays      int                `json:"every_days,omitempty"`
+	ThroughputDays int                `json:"throughput_days,omitempty"`
+	Points         []BacktestPoint    `json:"points"`
+	Accuracy       []ForecastAccuracy `json:"accuracy"`
+}
+
+// BacktestPoint is one forecast in a backtest. Resolved items were reviewed
+// later in the history; unresolved ones are still pending at its end and are
+// left out of the scores. ItemMAE and ItemBias cover this forecast's items.
+type BacktestPoint struct {
+	AsOf       string  `json:"as_of"`
+	RunID      int64   `json:"run_id,omitempty"`
+	Pending    int     `json:"pending"`
+	Resolved   int     `json:"resolved"`
+	Unresolved int     `json:"unresolved"`
+	ItemMAE    float64 `json:"item_mae"`
+	ItemBias   float64 `json:"item_bias"`
+}
+
+// ForecastAccuracy summarizes forecast error in days. Scope is overall, stage,
+// or reviewer; the overall clear-days row pools every stage sample. Bias is the
+// mean of projected minus actual, so a positive bias means forecasts ran long.
+// MAPE is a percent and skips samples whose actual was zero.
+type ForecastAccuracy struct {
+	Scope   string  `json:"scope"`
+	Key     string  `json:"key"`
+	Metric  string  `json:"metric"`
+	Samples int     `json:"samples"`
+	MAE     float64 `json:"mae"`
+	MAPE    float64 `json:"mape"`
+	Bias    float64 `json:"bias"`
+}
+
+// BacktestRun is a stored run's report for BacktestStoredRuns.
+type BacktestRun struct {
+	ID     int64
+	Report Report
+}
+
+// Backtest replays events at a series of past as-of dates. At each date the
+// queue is rebuilt from events submitted by then but reviewed after, with the
+// reviewer who eventually reviewed each item as its assignee, and forecast from
+// the reviews completed by then. queueItems, the queue still pending at the end
+// of the history, are added to every rebuilt queue they were submitted before;
+// they shape the forecasts but have no actual review to score.
+func Backtest(ctx context.Context, events []ReviewEvent, queueItems []QueueItem, opts Options, backtest BacktestOptions) (BacktestReport, error) {
+	if len(events) == 0 {
+		return BacktestReport{}, errors.New("no events to backtest")
+	}
+	opts = opts.withDefaults()
+	// Only the deterministic estimates are scored.
+	opts.Simulations = 0
+	opts.Journeys = false
+	location := opts.Calendar.location()
+	throughputDays := opts.ThroughputDays
+
+	first, last := events[0].ReviewedAt, events[0].ReviewedAt
+	for _, event := range events {
+		if event.ReviewedAt.Before(first) {
+			first = event.ReviewedAt
+		}
+		if event.ReviewedAt.After(last) {
+			last = event.ReviewedAt
+		}
+	}
+	defaults := DefaultBacktestOptions()
+	every := backtest.EveryDays
+	if every <= 0 {
+		every = defaults.EveryDays
+	}
+	settle := backtest.SettleDays
+	if settle < 0 {
+		settle = defaults.SettleDays
+	}
+	start := backtest.Start
+	if start.IsZero() {
+		start = startOfDay(first, location).AddDate(0, 0, throughputDays)
+	}
+	end := backtest.End
+	if end.IsZero() {
+		end = startOfDay(last, location).AddDate(0, 0, -settle)
+	}
+	start, end = start.In(location), end.In(location)
+	if end.Before(start) {
+		return BacktestReport{}, fmt.Errorf("no as-of dates to replay between %s and %s: the history needs %d days of throughput before the first and %d days of reviews after the last",
+			start.Format("2006-01-02"), end.Format("2006-01-02"), throughputDays, settle)
+	}
+
+	result := BacktestReport{
+		Source:         BacktestEvents,
+		Start:          start.Format(time.RFC3339),
+		End:            end.Format(time.RFC3339),
+		EveryDays:      every,
+		ThroughputDays: throughputDays,
+		Points:         []BacktestPoint{},
+	}
+	scores := newAccuracySet()
+	for asOf := start; !asOf.After(end); asOf = asOf.AddDate(0, 0, every) {
+		if err := ctx.Err(); err != nil {
+			return BacktestReport{}, err
+		}
+		window := newThroughputWindow(asOf, throughputDays)
+		var arrivals *arrivalHistory
+		if opts.Arrivals {
+			arrivals = newArrivalHistory(location)
+		}
+		var pending []QueueItem
+		var changes []backlogChange
+		actual := map[string]float64{}
+		for _, event := range events {
+			if event.ReviewedAt.After(asOf) {
+				changes = append(changes, backlogChange{stage: event.Stage, at: event.ReviewedAt, delta: -1})
+			}
+			if event.SubmittedAt.After(asOf) {
+				changes = append(changes, backlogChange{stage: event.Stage, at: event.SubmittedAt, delta: 1})
+				continue
+			}
+			if arrivals != nil {
+				arrivals.add(event.Stage, event.SubmittedAt)
+			}
+			if !event.ReviewedAt.After(asOf) {
+				window.add(event.Stage, reviewerKey(event.ReviewerID), event.ReviewedAt)
+				continue
+			}
+			item := QueueItem{
+				ApplicationID: event.ApplicationID,
+				Stage:         event.Stage,
+				SubmittedAt:   event.SubmittedAt,
+				ReviewerID:    event.ReviewerID,
+				Program:       event.Program,
+				Priority:      event.Priority,
+			}
+			pending = append(pending, item)
+			actual[backtestItemKey(item.ApplicationID, item.Stage, item.SubmittedAt.In(location).Format(time.RFC3339))] = event.ReviewedAt.Sub(asOf).Hours() / 24
+		}
+		for _, item := range queueItems {
+			if item.SubmittedAt.After(asOf) {
+				changes = append(changes, backlogChange{stage: item.Stage, at: item.SubmittedAt, delta: 1})
+				continue
+			}
+			if arrivals != nil {
+				arrivals.add(item.Stage, item.SubmittedAt)
+			}
+			pending = append(pending, item)
+		}
+
+		point := BacktestPoint{AsOf: asOf.Format(time.RFC3339), Pending: len(pending), Resolved: len(actual), Unresolved: len(pending) - len(actual)}
+		if len(pending) > 0 {
+			reviewers := make([]ReviewerStats, 0, len(window.reviewers))
+			for reviewerID, count := range window.reviewers {
+				reviewers = append(reviewers, ReviewerStats{
+					ReviewerID:        reviewerID,
+					ThroughputPerWeek: Round(float64(count)/(float64(throughputDays)/7.0), 2),
+					WindowCount:       count,
+				})
+			}
+			queue, err := buildQueueReport(ctx, pending, window, arrivals, reviewers, asOf, opts)
+			if err != nil {
+				return BacktestReport{}, err
+			}
+			var emptied map[string]float64
+			if arrivals != nil {
+				emptied = emptiedDays(pending, changes, asOf)
+			}
+			point.ItemMAE, point.ItemBias = scores.scoreQueue(queue, actual, emptied)
+		}
+		result.Points = append(result.Points, point)
+	}
+	result.Accuracy = scores.rows()
+	return result, nil
+}
+
+// backlogChange is a submission (+1) or review (-1) after a backtest as-of date.
+type backlogChange struct {
+	stage string
+	at    time.Time
+	delta int
+}
+
+// emptiedDays replays the changes after asOf and returns, per stage with
+// pending items, the days until its backlog first reached zero. Reviews sort
+// ahead of submissions at the same instant, so a hand-off on the day a stage
+// clears still counts as clearing it. Stages that never empty are left out.
+func emptiedDays(pending []QueueItem, changes []backlogChange, asOf time.Time) map[string]float64 {
+	backlog := map[string]int{}
+	for _, item := range pending {
+		backlog[item.Stage]++
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].at.Equal(changes[j].at) {
+			return changes[i].delta < changes[j].delta
+		}
+		return changes[i].at.Before(changes[j].at)
+	})
+	emptied := map[string]float64{}
+	for _, change := range changes {
+		count, ok := backlog[change.stage]
+		if !ok {
+			continue
+		}
+		if _, done := emptied[change.stage]; done {
+			continue
+		}
+		count += change.delta
+		backlog[change.stage] = count
+		if count <= 0 {
+			emptied[change.stage] = change.at.Sub(asOf).Hours() / 24
+		}
+	}
+	return emptied
+}
+
+// BacktestStoredRuns scores stored queue forecasts against the runs stored
+// after them. An item counts as reviewed midway between the last later run
+// that still listed it and the first that did not, so the actual days are
+// only as precise as the spacing of runs. Items still listed by the newest
+// run are unresolved, and runs without a queue are skipped. Runs should share
+// a queue scope (one profile), or items that simply fall outside a later run's
+// queue read as reviewed.
+func BacktestStoredRuns(runs []BacktestRun) BacktestReport {
+	type storedForecast struct {
+		id     int64
+		asOf   time.Time
+		queue  *QueueReport
+		keys   map[string]bool
+		stages map[string]bool
+	}
+	forecasts := make([]storedForecast, 0, len(runs))
+	for _, run := range runs {
+		queue := run.Report.Queue
+		if queue == nil {
+			continue
+		}
+		asOf, err := time.Parse(time.RFC3339, queue.AsOf)
+		if err != nil {
+			continue
+		}
+		keys := make(map[string]bool, len(queue.ItemProjections))
+		stages := map[string]bool{}
+		for _, projection := range queue.ItemProjections {
+			keys[backtestItemKey(projection.ApplicationID, projection.Stage, projection.SubmittedAt)] = true
+			stages[projection.Stage] = true
+		}
+		forecasts = append(forecasts, storedForecast{id: run.ID, asOf: asOf, queue: queue, keys: keys, stages: stages})
+	}
+	sort.Slice(forecasts, func(i, j int) bool {
+		if forecasts[i].asOf.Equal(forecasts[j].asOf) {
+			return forecasts[i].id < forecasts[j].id
+		}
+		return forecasts[i].asOf.Before(forecasts[j].asOf)
+	})
+
+	result := BacktestReport{Source: BacktestRuns, Points: []BacktestPoint{}}
+	if len(forecasts) > 0 {
+		result.Start = forecasts[0].asOf.Format(time.RFC3339)
+		result.End = forecasts[len(forecasts)-1].asOf.Format(time.RFC3339)
+	}
+	scores := newAccuracySet()
+	for i, current := range forecasts {
+		// gone finds the first later run that no longer lists something and
+		// returns the days to the midpoint since the last run that did.
+		gone := func(listed func(later storedForecast) bool) (float64, bool) {
+			lastSeen := current.asOf
+			for _, later := range forecasts[i+1:] {
+				if !later.asOf.After(current.asOf) {
+					continue
+				}
+				if !listed(later) {
+					resolvedAt := lastSeen.Add(later.asOf.Sub(lastSeen) / 2)
+					return resolvedAt.Sub(current.asOf).Hours() / 24, true
+				}
+				lastSeen = later.asOf
+			}
+			return 0, false
+		}
+		actual := map[string]float64{}
+		for key := range current.keys {
+			if days, ok := gone(func(later storedForecast) bool { return later.keys[key] }); ok {
+				actual[key] = days
+			}
+		}
+		emptied := map[string]float64{}
+		for stage := range current.stages {
+			if days, ok := gone(func(later storedForecast) bool { return later.stages[stage] }); ok {
+				emptied[stage] = days
+			}
+		}
+		point := BacktestPoint{
+			AsOf:       current.queue.AsOf,
+			RunID:      current.id,
+			Pending:    len(current.queue.ItemProjections),
+			Resolved:   len(actual),
+			Unresolved: len(current.queue.ItemProjections) - len(actual),
+		}
+		point.ItemMAE, point.ItemBias = scores.scoreQueue(current.queue, actual, emptied)
+		result.Points = append(result.Points, point)
+	}
+	result.Accuracy = scores.rows()
+	return result
+}
+
+// accuracySet accumulates forecast errors per scope, key, and metric.
+type accuracySet struct {
+	metrics map[[3]string]*accuracyAccumulator
+}
+
+type accuracyAccumulator struct {
+	samples    int
+	absolute   float64
+	signed     float64
+	percentN   int
+	percentSum float64
+}
+
+func newAccuracySet() *accuracySet {
+	return &accuracySet{metrics: map[[3]string]*accuracyAccumulator{}}
+}
+
+func (s *accuracySet) add(scope string, key string, metric string, projected float64, actual float64) {
+	id := [3]string{scope, key, metric}
+	acc, ok := s.metrics[id]
+	if !ok {
+		acc = &accuracyAccumulator{}
+		s.metrics[id] = acc
+	}
+	acc.add(projected, actual)
+}
+
+func (acc *accuracyAccumulator) add(projected float64, actual float64) {
+	diff := projected - actual
+	acc.samples++
+	acc.absolute += math.Abs(diff)
+	acc.signed += diff
+	if actual > 0 {
+		acc.percentN++
+		acc.percentSum += math.Abs(diff) / actual
+	}
+}
+
+func (acc *accuracyAccumulator) mae() float64 {
+	if acc.samples == 0 {
+		return 0
+	}
+	return acc.absolute / float64(acc.samples)
+}
+
+func (acc *accuracyAccumulator) bias() float64 {
+	if acc.samples == 0 {
+		return 0
+	}
+	return acc.signed / float64(acc.samples)
+}
+
+// scoreQueue compares one queue forecast with the actual days until review,
+// keyed by backtestItemKey, and returns the item MAE and bias for the forecast.
+// Stages and reviewers are scored on clear days only once every one of their
+// pending items has been reviewed; estimates without throughput are skipped.
+// Stages forecast with arrivals are scored against emptied, the days until the
+// stage first had nothing pending, since their estimate includes new work.
+func (s *accuracySet) scoreQueue(queue *QueueReport, actual map[string]float64, emptied map[string]float64) (float64, float64) {
+	if queue == nil {
+		return 0, 0
+	}
+	var items accuracyAccumulator
+	stageClear := map[string]float64{}
+	stageOpen := map[string]bool{}
+	reviewerClear := map[string]float64{}
+	reviewerOpen := map[string]bool{}
+	for _, projection := range queue.ItemProjections {
+		days, ok := actual[backtestItemKey(projection.ApplicationID, projection.Stage, projection.SubmittedAt)]
+		if !ok {
+			stageOpen[projection.Stage] = true
+			reviewerOpen[projection.ReviewerID] = true
+			continue
+		}
+		stageClear[projection.Stage] = max(stageClear[projection.Stage], days)
+		reviewerClear[projection.ReviewerID] = max(reviewerClear[projection.ReviewerID], days)
+		if projection.ProjectedReviewAt == "" {
+			continue
+		}
+		items.add(projection.ProjectedDays, days)
+		s.add("overall", "", MetricItemDays, projection.ProjectedDays, days)
+		s.add("stage", projection.Stage, MetricItemDays, projection.ProjectedDays, days)
+		s.add("reviewer", projection.ReviewerID, MetricItemDays, projection.ProjectedDays, days)
+	}
+	for _, stage := range queue.Stages {
+		days, ok := stageClear[stage.Stage]
+		if stageOpen[stage.Stage] {
+			ok = false
+		}
+		if stage.Arrivals != nil {
+			days, ok = emptied[stage.Stage]
+		}
+		if !ok || stage.ClearanceStatus == "no throughput data" {
+			continue
+		}
+		s.add("overall", "", MetricClearDays, stage.EstimatedClearDays, days)
+		s.add("stage", stage.Stage, MetricClearDays, stage.EstimatedClearDays, days)
+	}
+	for _, reviewer := range queue.Reviewers {
+		days, ok := reviewerClear[reviewer.ReviewerID]
+		if !ok || reviewerOpen[reviewer.ReviewerID] || reviewer.ClearanceStatus == "no throughput data" {
+			continue
+		}
+		s.add("reviewer", reviewer.ReviewerID, MetricClearDays, reviewer.EstimatedClearDays, days)
+	}
+	return Round(items.mae(), 2), Round(items.bias(), 2)
+}
+
+// rows lists the accuracy overall first, then by stage and reviewer, with
+// clear days ahead of item days for each key.
+func (s *accuracySet) rows() []ForecastAccuracy {
+	scopeOrder := map[string]int{"overall": 0, "stage": 1, "reviewer": 2}
+	rows := make([]ForecastAccuracy, 0, len(s.metrics))
+	for id, acc := range s.metrics {
+		row := ForecastAccuracy{
+			Scope:   id[0],
+			Key:     id[1],
+			Metric:  id[2],
+			Samples: acc.samples,
+			MAE:     Round(acc.mae(), 2),
+			Bias:    Round(acc.bias(), 2),
+		}
+		if acc.percentN > 0 {
+			row.MAPE = Round(acc.percentSum/float64(acc.percentN)*100, 1)
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		left, right := rows[i], rows[j]
+		if left.Scope != right.Scope {
+			return scopeOrder[left.Scope] < scopeOrder[right.Scope]
+		}
+		if left.Key != right.Key {
+			return left.Key < right.Key
+		}
+		return left.Metric < right.Metric
+	})
+	return rows
+}
+
+// backtestItemKey identifies a pending item across forecasts by application,
+// stage, and the RFC 3339 submitted_at used in item projections.
+func backtestItemKey(applicationID string, stage string, submittedAt string) string {
+	return strings.TrimSpace(applicationID) + "|" + stage + "|" + submittedAt
+}
+
+// reviewerKey trims a reviewer id, counting blanks as unassigned.
+func reviewerKey(reviewerID string) string {
+	reviewerID = strings.TrimSpace(reviewerID)
+	if reviewerID == "" {
+		return "unassigned"
+	}
+	return reviewerID
+}
+
+// startOfDay is local midnight on value's date.
+func startOfDay(value time.Time, location *time.Location) time.Time {
+	year, month, day := value.In(location).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, location)
+}
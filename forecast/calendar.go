@@ -0,0 +1,292 @@
+package forecast
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	ClockCalendar = "calendar"
+	ClockBusiness = "business"
+)
+
+// Calendar measures elapsed days for SLA math. In business mode only working days
+// in the calendar's timezone count; weekends and holidays are skipped.
+type Calendar struct {
+	Mode          string
+	Location      *time.Location
+	Weekend       map[time.Weekday]bool
+	Holidays      map[string]string
+	HolidaySource string
+}
+
+type CalendarSummary struct {
+	Mode          string   `json:"mode"`
+	Timezone      string   `json:"timezone"`
+	Weekend       []string `json:"weekend"`
+	HolidayCount  int      `json:"holiday_count"`
+	HolidaySource string   `json:"holiday_source,omitempty"`
+}
+
+// DefaultCalendar counts calendar days in UTC.
+func DefaultCalendar() Calendar {
+	return Calendar{
+		Mode:     ClockCalendar,
+		Location: time.UTC,
+		Weekend:  map[time.Weekday]bool{time.Saturday: true, time.Sunday: true},
+		Holidays: map[string]string{},
+	}
+}
+
+// NewCalendar builds a calendar or business-day clock. Weekend is a comma-separated
+// list of day names (or none) and holidayPath is an optional ICS or CSV file.
+func NewCalendar(mode string, timezone string, weekend string, holidayPath string) (Calendar, error) {
+	cal := DefaultCalendar()
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	switch mode {
+	case "", ClockCalendar:
+		cal.Mode = ClockCalendar
+	case ClockBusiness:
+		cal.Mode = ClockBusiness
+	default:
+		return Calendar{}, fmt.Errorf("unknown sla clock %q (use calendar or business)", mode)
+	}
+
+	if strings.TrimSpace(timezone) != "" {
+		location, err := time.LoadLocation(strings.TrimSpace(timezone))
+		if err != nil {
+			return Calendar{}, fmt.Errorf("invalid timezone: %w", err)
+		}
+		cal.Location = location
+	}
+
+	days, err := parseWeekend(weekend)
+	if err != nil {
+		return Calendar{}, err
+	}
+	cal.Weekend = days
+
+	if strings.TrimSpace(holidayPath) != "" {
+		holidays, err := loadHolidays(holidayPath)
+		if err != nil {
+			return Calendar{}, fmt.Errorf("failed to load holidays: %w", err)
+		}
+		cal.Holidays = holidays
+		cal.HolidaySource = SanitizePath(holidayPath)
+	}
+	return cal, nil
+}
+
+// Days returns the elapsed days between start and end under the calendar's clock.
+// Partial days count fractionally so same-day reviews keep sub-day precision.
+func (c Calendar) Days(start time.Time, end time.Time) float64 {
+	if c.Mode != ClockBusiness {
+		return end.Sub(start).Hours() / 24
+	}
+	if end.Before(start) {
+		return -c.Days(end, start)
+	}
+	location := c.location()
+	cursor := start.In(location)
+	end = end.In(location)
+	total := 0.0
+	for cursor.Before(end) {
+		dayStart := time.Date(cursor.Year(), cursor.Month(), cursor.Day(), 0, 0, 0, 0, location)
+		nextDay := dayStart.AddDate(0, 0, 1)
+		segmentEnd := nextDay
+		if end.Before(nextDay) {
+			segmentEnd = end
+		}
+		if c.IsBusinessDay(dayStart) {
+			total += segmentEnd.Sub(cursor).Hours() / nextDay.Sub(dayStart).Hours()
+		}
+		cursor = nextDay
+	}
+	return total
+}
+
+func (c Calendar) IsBusinessDay(value time.Time) bool {
+	value = value.In(c.location())
+	if c.Weekend[value.Weekday()] {
+		return false
+	}
+	_, holiday := c.Holidays[value.Format("2006-01-02")]
+	return !holiday
+}
+
+func (c Calendar) Summary() CalendarSummary {
+	weekend := make([]string, 0, len(c.Weekend))
+	for offset := 1; offset <= 7; offset++ {
+		day := time.Weekday(offset % 7)
+		if c.Weekend[day] {
+			weekend = append(weekend, strings.ToLower(day.String()[:3]))
+		}
+	}
+	mode := c.Mode
+	if mode == "" {
+		mode = ClockCalendar
+	}
+	return CalendarSummary{
+		Mode:          mode,
+		Timezone:      c.location().String(),
+		Weekend:       weekend,
+		HolidayCount:  len(c.Holidays),
+		HolidaySource: c.HolidaySource,
+	}
+}
+
+func (c Calendar) location() *time.Location {
+	if c.Location == nil {
+		return time.UTC
+	}
+	return c.Location
+}
+
+func parseWeekend(value string) (map[time.Weekday]bool, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	days := map[time.Weekday]bool{}
+	if value == "none" {
+		return days, nil
+	}
+	if value == "" {
+		value = "sat,sun"
+	}
+	names := map[string]time.Weekday{
+		"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+		"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+	}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) > 3 {
+			part = part[:3]
+		}
+		day, ok := names[part]
+		if !ok {
+			return nil, fmt.Errorf("unknown weekend day %q", part)
+		}
+		days[day] = true
+	}
+	return days, nil
+}
+
+// loadHolidays reads org holidays from an ICS calendar or a CSV with date and
+// optional name columns. Dates are keyed as YYYY-MM-DD.
+func loadHolidays(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".ics") {
+		return parseICSHolidays(file)
+	}
+	return parseCSVHolidays(file)
+}
+
+func parseCSVHolidays(reader io.Reader) (map[string]string, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	holidays := map[string]string{}
+	for rowIndex, row := range records {
+		if len(row) == 0 || strings.TrimSpace(row[0]) == "" {
+			continue
+		}
+		if rowIndex == 0 && strings.EqualFold(strings.TrimSpace(row[0]), "date") {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(row[0]))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid holiday date: %w", rowIndex+1, err)
+		}
+		name := ""
+		if len(row) > 1 {
+			name = strings.TrimSpace(row[1])
+		}
+		holidays[date.Format("2006-01-02")] = name
+	}
+	return holidays, nil
+}
+
+func parseICSHolidays(reader io.Reader) (map[string]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	holidays := map[string]string{}
+	inEvent := false
+	var start, end time.Time
+	name := ""
+	for _, line := range lines {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		property := strings.ToUpper(strings.SplitN(key, ";", 2)[0])
+		switch {
+		case property == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			inEvent = true
+			start, end, name = time.Time{}, time.Time{}, ""
+		case property == "END" && strings.EqualFold(value, "VEVENT"):
+			if inEvent && !start.IsZero() {
+				if end.IsZero() || !end.After(start) {
+					end = start.AddDate(0, 0, 1)
+				}
+				for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+					holidays[day.Format("2006-01-02")] = name
+				}
+			}
+			inEvent = false
+		case !inEvent:
+			continue
+		case property == "DTSTART":
+			parsed, err := parseICSDate(value)
+			if err != nil {
+				return nil, err
+			}
+			start = parsed
+		case property == "DTEND":
+			parsed, err := parseICSDate(value)
+			if err != nil {
+				return nil, err
+			}
+			end = parsed
+		case property == "SUMMARY":
+			name = strings.TrimSpace(value)
+		}
+	}
+	return holidays, nil
+}
+
+func parseICSDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid ICS date: %s", value)
+	}
+	parsed, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, errors.New("invalid ICS date: " + value)
+	}
+	return parsed, nil
+}
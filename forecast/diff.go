@@ -0,0 +1,366 @@
+package forecast
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	PresenceBoth    = "both"
+	PresenceAdded   = "added"
+	PresenceRemoved = "removed"
+)
+
+// MetricDelta compares one value between a base and a target report.
+type MetricDelta struct {
+	Base   float64 `json:"base"`
+	Target float64 `json:"target"`
+	Delta  float64 `json:"delta"`
+}
+
+// StatusChange compares a categorical value such as a risk tier or clearance
+// status.
+type StatusChange struct {
+	Base    string `json:"base"`
+	Target  string `json:"target"`
+	Changed bool   `json:"changed"`
+}
+
+type StageDiff struct {
+	Stage           string       `json:"stage"`
+	Presence        string       `json:"presence"`
+	Count           MetricDelta  `json:"count"`
+	AverageDays     MetricDelta  `json:"average_days"`
+	MedianDays      MetricDelta  `json:"median_days"`
+	P90Days         MetricDelta  `json:"p90_days"`
+	SLABreachRate   MetricDelta  `json:"sla_breach_rate"`
+	RiskTier        StatusChange `json:"risk_tier"`
+	Pending         MetricDelta  `json:"pending"`
+	Overdue         MetricDelta  `json:"overdue"`
+	ClearanceStatus StatusChange `json:"clearance_status"`
+}
+
+type ReviewerDiff struct {
+	ReviewerID        string       `json:"reviewer_id"`
+	Presence          string       `json:"presence"`
+	Count             MetricDelta  `json:"count"`
+	AverageDays       MetricDelta  `json:"average_days"`
+	SLABreachRate     MetricDelta  `json:"sla_breach_rate"`
+	ThroughputPerWeek MetricDelta  `json:"throughput_per_week"`
+	Pending           MetricDelta  `json:"pending"`
+	Overdue           MetricDelta  `json:"overdue"`
+	ClearanceStatus   StatusChange `json:"clearance_status"`
+}
+
+type QueueDiff struct {
+	Pending          MetricDelta  `json:"pending"`
+	Unassigned       MetricDelta  `json:"unassigned"`
+	DueSoon          MetricDelta  `json:"due_soon"`
+	Overdue          MetricDelta  `json:"overdue"`
+	AvgAgeDays       MetricDelta  `json:"avg_age_days"`
+	ProjectedPastSLA MetricDelta  `json:"projected_past_sla"`
+	ClearanceStatus  StatusChange `json:"clearance_status"`
+}
+
+// DiffSide identifies one of the compared reports.
+type DiffSide struct {
+	Label       string `json:"label"`
+	GeneratedAt string `json:"generated_at"`
+	TotalEvents int    `json:"total_events"`
+	QueueAsOf   string `json:"queue_as_of,omitempty"`
+}
+
+// ReportDiff compares two reports stage by stage and reviewer by reviewer.
+// Deltas are target minus base.
+type ReportDiff struct {
+	Base             DiffSide       `json:"base"`
+	Target           DiffSide       `json:"target"`
+	Overall          StageDiff      `json:"overall"`
+	Stages           []StageDiff    `json:"stages"`
+	Reviewers        []ReviewerDiff `json:"reviewers"`
+	Queue            *QueueDiff     `json:"queue,omitempty"`
+	InsightsAdded    []Insight      `json:"insights_added"`
+	InsightsResolved []Insight      `json:"insights_resolved"`
+}
+
+// DiffReports compares base and target. Stages and reviewers follow the target
+// report's order, followed by any that only appear in base.
+func DiffReports(base Report, baseLabel string, target Report, targetLabel string) ReportDiff {
+	diff := ReportDiff{
+		Base:             diffSide(base, baseLabel),
+		Target:           diffSide(target, targetLabel),
+		Overall:          diffStage(&base.Overall, &target.Overall, nil, nil),
+		InsightsAdded:    []Insight{},
+		InsightsResolved: []Insight{},
+	}
+	diff.Overall.Stage = "overall"
+	diff.Overall.Presence = PresenceBoth
+
+	baseStages := indexStages(base.Stages)
+	targetStages := indexStages(target.Stages)
+	baseQueueStages := indexQueueStages(base.Queue)
+	targetQueueStages := indexQueueStages(target.Queue)
+	for _, stage := range unionKeys(stageOrder(target), stageOrder(base)) {
+		entry := diffStage(baseStages[stage], targetStages[stage], baseQueueStages[stage], targetQueueStages[stage])
+		entry.Stage = stage
+		entry.Presence = presence(baseStages[stage] != nil || baseQueueStages[stage] != nil, targetStages[stage] != nil || targetQueueStages[stage] != nil)
+		diff.Stages = append(diff.Stages, entry)
+	}
+
+	baseReviewers := indexReviewers(base.Reviewers)
+	targetReviewers := indexReviewers(target.Reviewers)
+	baseQueueReviewers := indexQueueReviewers(base.Queue)
+	targetQueueReviewers := indexQueueReviewers(target.Queue)
+	for _, reviewer := range unionKeys(reviewerOrder(target), reviewerOrder(base)) {
+		entry := diffReviewer(baseReviewers[reviewer], targetReviewers[reviewer], baseQueueReviewers[reviewer], targetQueueReviewers[reviewer])
+		entry.ReviewerID = reviewer
+		entry.Presence = presence(baseReviewers[reviewer] != nil || baseQueueReviewers[reviewer] != nil, targetReviewers[reviewer] != nil || targetQueueReviewers[reviewer] != nil)
+		diff.Reviewers = append(diff.Reviewers, entry)
+	}
+
+	if base.Queue != nil || target.Queue != nil {
+		diff.Queue = diffQueue(base.Queue, target.Queue)
+		diff.Overall.Pending = diff.Queue.Pending
+		diff.Overall.Overdue = diff.Queue.Overdue
+		diff.Overall.ClearanceStatus = diff.Queue.ClearanceStatus
+	}
+
+	baseInsights := map[string]bool{}
+	for _, insight := range base.Insights {
+		baseInsights[insightKey(insight)] = true
+	}
+	targetInsights := map[string]bool{}
+	for _, insight := range target.Insights {
+		targetInsights[insightKey(insight)] = true
+		if !baseInsights[insightKey(insight)] {
+			diff.InsightsAdded = append(diff.InsightsAdded, insight)
+		}
+	}
+	for _, insight := range base.Insights {
+		if !targetInsights[insightKey(insight)] {
+			diff.InsightsResolved = append(diff.InsightsResolved, insight)
+		}
+	}
+	return diff
+}
+
+// Changed reports whether any compared value moved.
+func (d StageDiff) Changed() bool {
+	return d.Presence != PresenceBoth || d.RiskTier.Changed || d.ClearanceStatus.Changed ||
+		anyMoved(d.Count, d.AverageDays, d.MedianDays, d.P90Days, d.SLABreachRate, d.Pending, d.Overdue)
+}
+
+// Changed reports whether any compared value moved.
+func (d ReviewerDiff) Changed() bool {
+	return d.Presence != PresenceBoth || d.ClearanceStatus.Changed ||
+		anyMoved(d.Count, d.AverageDays, d.SLABreachRate, d.ThroughputPerWeek, d.Pending, d.Overdue)
+}
+
+func anyMoved(deltas ...MetricDelta) bool {
+	for _, delta := range deltas {
+		if delta.Delta != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func diffSide(report Report, label string) DiffSide {
+	side := DiffSide{Label: label, GeneratedAt: report.GeneratedAt, TotalEvents: report.TotalEvents}
+	if report.Queue != nil {
+		side.QueueAsOf = report.Queue.AsOf
+	}
+	return side
+}
+
+func diffStage(base *StageStats, target *StageStats, baseQueue *QueueStageForecast, targetQueue *QueueStageForecast) StageDiff {
+	var b, t StageStats
+	if base != nil {
+		b = *base
+	}
+	if target != nil {
+		t = *target
+	}
+	var bq, tq QueueStageForecast
+	if baseQueue != nil {
+		bq = *baseQueue
+	}
+	if targetQueue != nil {
+		tq = *targetQueue
+	}
+	return StageDiff{
+		Count:           delta(float64(b.Count), float64(t.Count)),
+		AverageDays:     delta(b.AverageDays, t.AverageDays),
+		MedianDays:      delta(b.MedianDays, t.MedianDays),
+		P90Days:         delta(b.P90Days, t.P90Days),
+		SLABreachRate:   delta(b.SLABreachRate, t.SLABreachRate),
+		RiskTier:        statusChange(b.RiskTier, t.RiskTier),
+		Pending:         delta(float64(bq.PendingCount), float64(tq.PendingCount)),
+		Overdue:         delta(float64(bq.OverdueCount), float64(tq.OverdueCount)),
+		ClearanceStatus: statusChange(bq.ClearanceStatus, tq.ClearanceStatus),
+	}
+}
+
+func diffReviewer(base *ReviewerStats, target *ReviewerStats, baseQueue *QueueReviewerForecast, targetQueue *QueueReviewerForecast) ReviewerDiff {
+	var b, t ReviewerStats
+	if base != nil {
+		b = *base
+	}
+	if target != nil {
+		t = *target
+	}
+	var bq, tq QueueReviewerForecast
+	if baseQueue != nil {
+		bq = *baseQueue
+	}
+	if targetQueue != nil {
+		tq = *targetQueue
+	}
+	return ReviewerDiff{
+		Count:             delta(float64(b.Count), float64(t.Count)),
+		AverageDays:       delta(b.AverageDays, t.AverageDays),
+		SLABreachRate:     delta(b.SLABreachRate, t.SLABreachRate),
+		ThroughputPerWeek: delta(b.ThroughputPerWeek, t.ThroughputPerWeek),
+		Pending:           delta(float64(bq.PendingCount), float64(tq.PendingCount)),
+		Overdue:           delta(float64(bq.OverdueCount), float64(tq.OverdueCount)),
+		ClearanceStatus:   statusChange(bq.ClearanceStatus, tq.ClearanceStatus),
+	}
+}
+
+func diffQueue(base *QueueReport, target *QueueReport) *QueueDiff {
+	var b, t QueueReport
+	if base != nil {
+		b = *base
+	}
+	if target != nil {
+		t = *target
+	}
+	return &QueueDiff{
+		Pending:          delta(float64(b.TotalPending), float64(t.TotalPending)),
+		Unassigned:       delta(float64(b.UnassignedCount), float64(t.UnassignedCount)),
+		DueSoon:          delta(float64(b.DueSoonCount), float64(t.DueSoonCount)),
+		Overdue:          delta(float64(b.OverdueCount), float64(t.OverdueCount)),
+		AvgAgeDays:       delta(b.AvgAgeDays, t.AvgAgeDays),
+		ProjectedPastSLA: delta(float64(b.ProjectedPastSLA), float64(t.ProjectedPastSLA)),
+		ClearanceStatus:  statusChange(clearancePlanStatus(b.ClearancePlan), clearancePlanStatus(t.ClearancePlan)),
+	}
+}
+
+func clearancePlanStatus(plan *QueueClearancePlan) string {
+	if plan == nil {
+		return ""
+	}
+	return plan.Status
+}
+
+// delta rounds half away from zero; Round truncates negative values toward zero,
+// which would shrink every decrease.
+func delta(base float64, target float64) MetricDelta {
+	return MetricDelta{Base: base, Target: target, Delta: math.Round((target-base)*100) / 100}
+}
+
+func statusChange(base string, target string) StatusChange {
+	return StatusChange{Base: base, Target: target, Changed: base != target}
+}
+
+func presence(inBase bool, inTarget bool) string {
+	switch {
+	case inBase && inTarget:
+		return PresenceBoth
+	case inTarget:
+		return PresenceAdded
+	default:
+		return PresenceRemoved
+	}
+}
+
+// insightKey matches insights across runs by area and message; the metric text
+// carries the run's numbers and is expected to differ.
+func insightKey(insight Insight) string {
+	return insight.Area + "\x00" + insight.Message
+}
+
+func indexStages(stages []StageStats) map[string]*StageStats {
+	index := make(map[string]*StageStats, len(stages))
+	for i := range stages {
+		index[stages[i].Stage] = &stages[i]
+	}
+	return index
+}
+
+func indexReviewers(reviewers []ReviewerStats) map[string]*ReviewerStats {
+	index := make(map[string]*ReviewerStats, len(reviewers))
+	for i := range reviewers {
+		index[reviewers[i].ReviewerID] = &reviewers[i]
+	}
+	return index
+}
+
+func indexQueueStages(queue *QueueReport) map[string]*QueueStageForecast {
+	index := map[string]*QueueStageForecast{}
+	if queue == nil {
+		return index
+	}
+	for i := range queue.Stages {
+		index[queue.Stages[i].Stage] = &queue.Stages[i]
+	}
+	return index
+}
+
+func indexQueueReviewers(queue *QueueReport) map[string]*QueueReviewerForecast {
+	index := map[string]*QueueReviewerForecast{}
+	if queue == nil {
+		return index
+	}
+	for i := range queue.Reviewers {
+		index[queue.Reviewers[i].ReviewerID] = &queue.Reviewers[i]
+	}
+	return index
+}
+
+func stageOrder(report Report) []string {
+	names := make([]string, 0, len(report.Stages))
+	for _, stage := range report.Stages {
+		names = append(names, stage.Stage)
+	}
+	if report.Queue != nil {
+		extra := []string{}
+		for _, stage := range report.Queue.Stages {
+			extra = append(extra, stage.Stage)
+		}
+		sort.Strings(extra)
+		names = append(names, extra...)
+	}
+	return names
+}
+
+func reviewerOrder(report Report) []string {
+	names := make([]string, 0, len(report.Reviewers))
+	for _, reviewer := range report.Reviewers {
+		names = append(names, reviewer.ReviewerID)
+	}
+	if report.Queue != nil {
+		extra := []string{}
+		for _, reviewer := range report.Queue.Reviewers {
+			extra = append(extra, reviewer.ReviewerID)
+		}
+		sort.Strings(extra)
+		names = append(names, extra...)
+	}
+	return names
+}
+
+func unionKeys(lists ...[]string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, list := range lists {
+		for _, key := range list {
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			out = append(out, key)
+		}
+	}
+	return out
+}
@@ -0,0 +1,704 @@
+package forecast
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func findInsight(insights []Insight, area string) *Insight {
+	for i := range insights {
+		if insights[i].Area == area {
+			return &insights[i]
+		}
+	}
+	return nil
+}
+
+func TestBuildInsightsOverallRisk(t *testing.T) {
+	overall := StageStats{
+		Count:          10,
+		AverageDays:    12.4,
+		SLABreachRate:  35.0,
+		RiskTier:       "high",
+		Stage:          "overall",
+		SLABreachCount: 3,
+	}
+	insights := BuildInsights(overall, nil, ThroughputTrendSummary{}, LatencyTrendSummary{}, nil, 10)
+	insight := findInsight(insights, "overall")
+	if insight == nil {
+		t.Fatalf("expected overall insight")
+	}
+	if insight.Severity != "high" {
+		t.Fatalf("expected high severity, got %s", insight.Severity)
+	}
+}
+
+func TestBuildInsightsQueueCoverage(t *testing.T) {
+	queue := &QueueReport{
+		TotalPending:    10,
+		AssignedCount:   4,
+		UnassignedCount: 6,
+		OverdueCount:    0,
+		DueSoonCount:    0,
+	}
+	insights := BuildInsights(StageStats{}, nil, ThroughputTrendSummary{}, LatencyTrendSummary{}, queue, 10)
+	insight := findInsight(insights, "coverage")
+	if insight == nil {
+		t.Fatalf("expected coverage insight")
+	}
+	if insight.Severity != "medium" {
+		t.Fatalf("expected medium severity, got %s", insight.Severity)
+	}
+}
+
+func TestSimulateClearanceConstantThroughput(t *testing.T) {
+	asOf := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	samples := []int{2, 2, 2, 2, 2, 2, 2}
+	simulation, err := simulateClearance(context.Background(), "review", 10, samples, nil, 200, 7, 14, asOf)
+	if err != nil || simulation == nil {
+		t.Fatalf("expected simulation result, got %v", err)
+	}
+	if simulation.P50Days != 5 || simulation.P95Days != 5 {
+		t.Fatalf("expected 5 clear days, got p50 %.2f p95 %.2f", simulation.P50Days, simulation.P95Days)
+	}
+	if simulation.P50Date != "2026-02-06" {
+		t.Fatalf("expected p50 date 2026-02-06, got %s", simulation.P50Date)
+	}
+	if simulation.ProbClearWithinTarget != 100 {
+		t.Fatalf("expected 100%% probability, got %.1f", simulation.ProbClearWithinTarget)
+	}
+}
+
+func TestSimulateClearanceWithoutThroughput(t *testing.T) {
+	simulation, err := simulateClearance(context.Background(), "review", 4, []int{0, 0, 0}, nil, 100, 1, 14, time.Now())
+	if err != nil || simulation == nil || simulation.Status != "no throughput data" {
+		t.Fatalf("expected no throughput status, got %+v (%v)", simulation, err)
+	}
+}
+
+func TestSimulateClearanceStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	simulation, err := simulateClearance(ctx, "review", 10, []int{1, 2, 3}, nil, 1000000, 1, 14, time.Now())
+	if !errors.Is(err, context.Canceled) || simulation != nil {
+		t.Fatalf("expected the cancelled simulation to stop, got %+v (%v)", simulation, err)
+	}
+}
+
+func TestBuildQueueItemProjectionsUsesReviewerBacklog(t *testing.T) {
+	asOf := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	items := []QueueItem{
+		{ApplicationID: "A-2", Stage: "review", SubmittedAt: asOf.AddDate(0, 0, -3), ReviewerID: "rev-1"},
+		{ApplicationID: "A-1", Stage: "review", SubmittedAt: asOf.AddDate(0, 0, -9), ReviewerID: "rev-1"},
+		{ApplicationID: "A-3", Stage: "review", SubmittedAt: asOf.AddDate(0, 0, -1)},
+	}
+	reviewers := []ReviewerStats{{ReviewerID: "rev-1", ThroughputPerWeek: 7}}
+	projections := buildQueueItemProjections(items, reviewers, map[string]float64{"review": 0.5}, NewSLAPolicy(10, 0.8), asOf, DefaultCalendar())
+	if len(projections) != 3 {
+		t.Fatalf("expected 3 projections, got %d", len(projections))
+	}
+	byID := map[string]QueueItemProjection{}
+	for _, projection := range projections {
+		byID[projection.ApplicationID] = projection
+	}
+	if got := byID["A-1"]; got.BacklogPosition != 1 || got.ProjectedDays != 1 || !got.PastSLA {
+		t.Fatalf("unexpected projection for oldest reviewer item: %+v", got)
+	}
+	if got := byID["A-2"]; got.BacklogPosition != 2 || got.ProjectedReviewAt != "2026-02-03T00:00:00Z" || got.PastSLA {
+		t.Fatalf("unexpected projection for second reviewer item: %+v", got)
+	}
+	if got := byID["A-3"]; got.ThroughputSource != "stage" || got.ProjectedDays != 2 {
+		t.Fatalf("unexpected projection for unassigned item: %+v", got)
+	}
+}
+
+func TestCalendarBusinessDaysSkipWeekendAndHolidays(t *testing.T) {
+	holidays, err := parseICSHolidays(strings.NewReader("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20260119\r\nDTEND;VALUE=DATE:20260120\r\nSUMMARY:MLK Day\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"))
+	if err != nil {
+		t.Fatalf("parseICSHolidays failed: %v", err)
+	}
+	cal := DefaultCalendar()
+	cal.Mode = ClockBusiness
+	cal.Holidays = holidays
+
+	// Friday 2026-01-16 to Wednesday 2026-01-21 spans a weekend and a Monday holiday.
+	start := time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 1, 21, 12, 0, 0, 0, time.UTC)
+	if got := cal.Days(start, end); got != 2.5 {
+		t.Fatalf("expected 2.5 business days, got %.2f", got)
+	}
+	if got := cal.Days(end, start); got != -2.5 {
+		t.Fatalf("expected -2.5 business days, got %.2f", got)
+	}
+	if got := DefaultCalendar().Days(start, end); got != 5.5 {
+		t.Fatalf("expected 5.5 calendar days, got %.2f", got)
+	}
+}
+
+func TestParseCSVHolidays(t *testing.T) {
+	holidays, err := parseCSVHolidays(strings.NewReader("date,name\n2026-07-03,Independence Day (observed)\n2026-12-25,Winter Break\n"))
+	if err != nil {
+		t.Fatalf("parseCSVHolidays failed: %v", err)
+	}
+	if len(holidays) != 2 || holidays["2026-12-25"] != "Winter Break" {
+		t.Fatalf("unexpected holidays: %+v", holidays)
+	}
+}
+
+func TestSLAPolicyResolvesMostSpecificRule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	payload := `{"rules": [
+		{"stage": "committee_review", "sla_days": 15, "due_soon_ratio": 0.7},
+		{"stage": "committee_review", "priority": "expedited", "sla_days": 8},
+		{"program": "stem", "sla_days": 12}
+	]}`
+	if err := os.WriteFile(path, []byte(payload), 0644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	policy, err := LoadSLAPolicy(path, 10, 0.8)
+	if err != nil {
+		t.Fatalf("LoadSLAPolicy(%s) failed: %v", path, err)
+	}
+	if got := policy.Resolve("committee_review", "arts", ""); got.SLADays != 15 || got.DueSoonRatio != 0.7 {
+		t.Fatalf("expected stage rule, got %+v", got)
+	}
+	if got := policy.Resolve("committee_review", "arts", "Expedited"); got.SLADays != 8 || got.DueSoonRatio != 0.8 {
+		t.Fatalf("expected priority override with default ratio, got %+v", got)
+	}
+	if got := policy.Resolve("initial_review", "stem", ""); got.SLADays != 12 {
+		t.Fatalf("expected program rule, got %+v", got)
+	}
+	if got := policy.Resolve("initial_review", "arts", ""); got.SLADays != 10 {
+		t.Fatalf("expected default threshold, got %+v", got)
+	}
+
+	effective := policy.EffectivePolicies([]string{"initial_review", "committee_review"})
+	if len(effective) != 2 || effective[0].Stage != "committee_review" || effective[0].Source != "stage rule" || effective[0].Overrides != 2 {
+		t.Fatalf("unexpected effective policies: %+v", effective)
+	}
+}
+
+func TestSLAPolicyAppliesWildcardOnlyRule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	payload := `{"rules": [
+		{"stage": "*", "sla_days": 20},
+		{"stage": "committee_review", "sla_days": 15}
+	]}`
+	if err := os.WriteFile(path, []byte(payload), 0644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	policy, err := LoadSLAPolicy(path, 10, 0.8)
+	if err != nil {
+		t.Fatalf("LoadSLAPolicy(%s) failed: %v", path, err)
+	}
+	if got := policy.Resolve("initial_review", "", ""); got.SLADays != 20 {
+		t.Fatalf("expected wildcard rule to apply, got %+v", got)
+	}
+	if got := policy.Resolve("committee_review", "", ""); got.SLADays != 15 {
+		t.Fatalf("expected the stage rule to beat the wildcard, got %+v", got)
+	}
+	effective := policy.EffectivePolicies([]string{"committee_review", "initial_review"})
+	if len(effective) != 2 || effective[0].Source != "stage rule" || effective[1].Source != "wildcard rule" {
+		t.Fatalf("expected the wildcard match to be labeled as such, got %+v", effective)
+	}
+}
+
+func TestDiffReportsTracksStagesQueueAndInsights(t *testing.T) {
+	base := Report{
+		Overall: StageStats{AverageDays: 8, SLABreachRate: 60},
+		Stages:  []StageStats{{Stage: "initial", AverageDays: 6, RiskTier: "medium"}, {Stage: "legacy", AverageDays: 3}},
+		Queue: &QueueReport{
+			TotalPending:  4,
+			Stages:        []QueueStageForecast{{Stage: "initial", PendingCount: 4, ClearanceStatus: "on track"}},
+			ClearancePlan: &QueueClearancePlan{Status: "on track"},
+		},
+		Insights: []Insight{{Area: "latency", Message: "Latency is worsening.", Metric: "avg +2.00"}},
+	}
+	target := Report{
+		Overall: StageStats{AverageDays: 7.5, SLABreachRate: 50},
+		Stages:  []StageStats{{Stage: "initial", AverageDays: 6, RiskTier: "high"}, {Stage: "final", AverageDays: 9}},
+		Queue: &QueueReport{
+			TotalPending:  6,
+			OverdueCount:  2,
+			Stages:        []QueueStageForecast{{Stage: "initial", PendingCount: 6, OverdueCount: 2, ClearanceStatus: "at risk"}},
+			ClearancePlan: &QueueClearancePlan{Status: "behind"},
+		},
+		Insights: []Insight{{Area: "queue", Message: "There are overdue items.", Metric: "overdue 2"}},
+	}
+
+	diff := DiffReports(base, "run #1", target, "run #2")
+	if diff.Overall.AverageDays.Delta != -0.5 || diff.Overall.Pending.Delta != 2 {
+		t.Fatalf("unexpected overall diff: %+v", diff.Overall)
+	}
+	if len(diff.Stages) != 3 || diff.Stages[0].Stage != "initial" || diff.Stages[1].Presence != PresenceAdded || diff.Stages[2].Presence != PresenceRemoved {
+		t.Fatalf("unexpected stage order or presence: %+v", diff.Stages)
+	}
+	initial := diff.Stages[0]
+	if !initial.RiskTier.Changed || initial.Overdue.Delta != 2 || initial.ClearanceStatus.Target != "at risk" {
+		t.Fatalf("unexpected initial stage diff: %+v", initial)
+	}
+	if diff.Queue == nil || !diff.Queue.ClearanceStatus.Changed {
+		t.Fatalf("expected queue clearance status change, got %+v", diff.Queue)
+	}
+	if len(diff.InsightsAdded) != 1 || diff.InsightsAdded[0].Area != "queue" || len(diff.InsightsResolved) != 1 || diff.InsightsResolved[0].Area != "latency" {
+		t.Fatalf("unexpected insight changes: added %+v resolved %+v", diff.InsightsAdded, diff.InsightsResolved)
+	}
+}
+
+func TestQuantileSketchStaysWithinRelativeError(t *testing.T) {
+	var sketch quantileSketch
+	values := make([]float64, 0, 50000)
+	for i := 0; i < 50000; i++ {
+		// Skewed durations between 0 and ~60 days, with some same-day reviews.
+		value := float64((i*7919)%60000) / 1000
+		value = value * value / 60
+		values = append(values, value)
+		sketch.add(value)
+	}
+	sort.Float64s(values)
+	for _, p := range []float64{10, 50, 90, 99} {
+		exact := percentile(values, p)
+		got := sketch.quantile(p)
+		if math.Abs(got-exact) > exact*0.01+0.001 {
+			t.Fatalf("p%.0f: sketch %.4f, exact %.4f", p, got, exact)
+		}
+	}
+	if sketch.quantile(100) != values[len(values)-1] || sketch.quantile(0) != values[0] {
+		t.Fatalf("expected exact min and max, got %.4f and %.4f", sketch.quantile(0), sketch.quantile(100))
+	}
+}
+
+func TestAggregatorCountsWindowsFromOutOfOrderStream(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	const total = 20000
+	aggregator := NewAggregator(Options{ThroughputDays: 14})
+	latest := start
+	var reviewed []time.Time
+	for i := 0; i < total; i++ {
+		// Walk through 300 days in a scrambled order so pruning sees late arrivals.
+		day := (i * 7919) % 300
+		reviewedAt := start.AddDate(0, 0, day).Add(time.Duration(i%24) * time.Hour)
+		if reviewedAt.After(latest) {
+			latest = reviewedAt
+		}
+		reviewed = append(reviewed, reviewedAt)
+		aggregator.Add(ReviewEvent{
+			ApplicationID: "app",
+			Stage:         []string{"initial", "final"}[i%2],
+			SubmittedAt:   reviewedAt.AddDate(0, 0, -(i % 20)),
+			ReviewedAt:    reviewedAt,
+			ReviewerID:    []string{"r1", "r2", "r3"}[i%3],
+		})
+	}
+	if len(aggregator.recent) >= total {
+		t.Fatalf("expected old events to be pruned, kept %d", len(aggregator.recent))
+	}
+
+	report, err := aggregator.Report(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	windowStart := latest.AddDate(0, 0, -14)
+	want := 0
+	for _, reviewedAt := range reviewed {
+		if !reviewedAt.Before(windowStart) && !reviewedAt.After(latest) {
+			want++
+		}
+	}
+	if report.TotalEvents != total || report.Throughput.EventsInWindow != want {
+		t.Fatalf("expected %d events with %d in window, got %d with %d", total, want, report.TotalEvents, report.Throughput.EventsInWindow)
+	}
+	if report.Overall.MedianDays != 9.5 || report.Overall.MaxDays != 19 {
+		t.Fatalf("unexpected overall latency: %+v", report.Overall)
+	}
+}
+
+// BenchmarkAggregatorMillionEvents streams a 1.2 million event history through
+// the aggregator; memory stays bounded by the throughput window.
+func BenchmarkAggregatorMillionEvents(b *testing.B) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	stages := []string{"intake", "initial", "committee", "final"}
+	reviewers := make([]string, 200)
+	for i := range reviewers {
+		reviewers[i] = "reviewer-" + strconv.Itoa(i)
+	}
+	const events = 1_200_000
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		aggregator := NewAggregator(Options{})
+		for i := 0; i < events; i++ {
+			reviewedAt := start.Add(time.Duration(i) * 2 * time.Minute)
+			aggregator.Add(ReviewEvent{
+				ApplicationID: "app",
+				Stage:         stages[i%len(stages)],
+				SubmittedAt:   reviewedAt.Add(-time.Duration(i%(30*24)) * time.Hour),
+				ReviewedAt:    reviewedAt,
+				ReviewerID:    reviewers[i%len(reviewers)],
+			})
+		}
+		if _, err := aggregator.Report(context.Background(), nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(events, "events/op")
+}
+
+func TestAggregatorAnchorsWindowsInCalendarZone(t *testing.T) {
+	pacific, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skipf("zone database unavailable: %v", err)
+	}
+	cal := DefaultCalendar()
+	cal.Location = pacific
+	aggregator := NewAggregator(Options{Calendar: cal, ThroughputDays: 7, AsOf: time.Date(2026, 3, 10, 0, 0, 0, 0, pacific)})
+	for _, reviewedAt := range []time.Time{
+		// 23:30 Pacific on March 2 is already March 3 in UTC; only the second
+		// review falls inside the window that opens at Pacific midnight on March 3.
+		time.Date(2026, 3, 2, 23, 30, 0, 0, pacific),
+		time.Date(2026, 3, 3, 0, 30, 0, 0, pacific),
+		time.Date(2026, 3, 9, 23, 0, 0, 0, pacific),
+	} {
+		aggregator.Add(ReviewEvent{ApplicationID: "a", Stage: "initial", SubmittedAt: reviewedAt.AddDate(0, 0, -1), ReviewedAt: reviewedAt, ReviewerID: "r1"})
+	}
+	report, err := aggregator.Report(context.Background(), []QueueItem{{ApplicationID: "q", Stage: "initial", SubmittedAt: time.Date(2026, 3, 1, 0, 0, 0, 0, pacific)}})
+	if err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	if report.Timezone != "America/Los_Angeles" || report.Throughput.AsOf != "2026-03-10T00:00:00-07:00" {
+		t.Fatalf("unexpected zone or as-of: %s %s", report.Timezone, report.Throughput.AsOf)
+	}
+	if report.Throughput.EventsInWindow != 2 || report.ThroughputTrend.CurrentWindowStart != "2026-03-03T00:00:00-08:00" {
+		t.Fatalf("expected the window to open at Pacific midnight, got %d events from %s", report.Throughput.EventsInWindow, report.ThroughputTrend.CurrentWindowStart)
+	}
+	// The spring-forward switch on March 8 makes these nine calendar days only
+	// 215 hours long, but queue age is still measured in elapsed time.
+	if got := report.Queue.AvgAgeDays; got != 8.96 {
+		t.Fatalf("unexpected queue age: %v", got)
+	}
+}
+
+func TestJourneysStitchEventsAndQueueByApplication(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+	events := []ReviewEvent{
+		// Listed out of order, with final_decision seen first, so the stage
+		// order has to come from the journeys rather than the stream.
+		{ApplicationID: "J-6", Stage: "final_decision", SubmittedAt: day(20), ReviewedAt: day(22), ReviewerID: "r5"},
+		{ApplicationID: "J-1", Stage: "committee_review", SubmittedAt: day(8), ReviewedAt: day(15), ReviewerID: "r3"},
+		{ApplicationID: "J-1", Stage: "initial_review", SubmittedAt: day(2), ReviewedAt: day(6), ReviewerID: "r1"},
+		{ApplicationID: "J-1", Stage: "final_decision", SubmittedAt: day(16), ReviewedAt: day(19), ReviewerID: "r5"},
+		{ApplicationID: "J-2", Stage: "initial_review", SubmittedAt: day(3), ReviewedAt: day(9), ReviewerID: "r2"},
+		{ApplicationID: "J-2", Stage: "committee_review", SubmittedAt: day(13), ReviewedAt: day(24), ReviewerID: "r4"},
+		{ApplicationID: "J-2", Stage: "final_decision", SubmittedAt: day(27), ReviewedAt: day(30), ReviewerID: "r5"},
+		{ApplicationID: "J-3", Stage: "initial_review", SubmittedAt: day(5), ReviewedAt: day(8), ReviewerID: "r1"},
+		{ApplicationID: "J-5", Stage: "initial_review", SubmittedAt: day(12), ReviewedAt: day(16), ReviewerID: "r1"},
+	}
+	queue := []QueueItem{
+		{ApplicationID: "J-5", Stage: "committee_review", SubmittedAt: day(19), ReviewerID: "r3"},
+		{ApplicationID: "J-7", Stage: "initial_review", SubmittedAt: day(29)},
+	}
+	report, err := BuildReport(context.Background(), events, queue, Options{Journeys: true, JourneyTop: 3})
+	if err != nil {
+		t.Fatalf("build report: %v", err)
+	}
+	journeys := report.Journeys
+	if journeys == nil {
+		t.Fatal("expected a journey section")
+	}
+	if got := strings.Join(journeys.StageOrder, ","); got != "initial_review,committee_review,final_decision" {
+		t.Fatalf("unexpected stage order: %s", got)
+	}
+	if journeys.Applications != 6 || journeys.Completed != 3 || journeys.InFlight != 2 || journeys.Incomplete != 1 {
+		t.Fatalf("unexpected status counts: %+v", journeys)
+	}
+	// J-6 started mid-pipeline, so only J-1 (17 days) and J-2 (27 days) count.
+	if journeys.CycleTime.Count != 2 || journeys.CycleTime.AverageDays != 22 || journeys.CycleTime.MaxDays != 27 {
+		t.Fatalf("unexpected cycle time: %+v", journeys.CycleTime)
+	}
+	handoff := journeys.Handoffs[0]
+	if handoff.From != "initial_review" || handoff.To != "committee_review" || handoff.Count != 3 || handoff.AverageWaitDays != 3 {
+		t.Fatalf("unexpected first hand-off: %+v", handoff)
+	}
+	conversion := journeys.Conversions[0]
+	if conversion.Reviewed != 4 || conversion.Entered != 3 || conversion.ConversionRate != 75 {
+		t.Fatalf("unexpected conversion: %+v", conversion)
+	}
+	if len(journeys.Slowest) != 3 || journeys.Slowest[0].ApplicationID != "J-2" || journeys.Slowest[1].Status != JourneyInFlight {
+		t.Fatalf("unexpected slowest journeys: %+v", journeys.Slowest)
+	}
+	progress := journeys.Progress[0]
+	if progress.ApplicationID != "J-5" || progress.Step != 2 || progress.Steps != 3 || progress.StagesCompleted != 1 || progress.JourneyAgeDays != 18 {
+		t.Fatalf("unexpected in-flight progress: %+v", progress)
+	}
+
+	withoutJourneys, err := BuildReport(context.Background(), events, queue, Options{})
+	if err != nil {
+		t.Fatalf("build report: %v", err)
+	}
+	if withoutJourneys.Journeys != nil {
+		t.Fatal("expected journeys to stay off by default")
+	}
+}
+
+func TestArrivalForecastAddsWeekdayAndSeasonalSubmissionsToClearance(t *testing.T) {
+	var events []ReviewEvent
+	for day := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC); day.Before(time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			continue
+		}
+		submissions := 2
+		// A yearly deadline brings a rush of submissions in late February.
+		if day.Month() == time.February && day.Day() >= 20 || day.Month() == time.March && day.Day() == 1 {
+			submissions += 10
+		}
+		for i := 0; i < submissions; i++ {
+			events = append(events, ReviewEvent{ApplicationID: "A", Stage: "review", SubmittedAt: day, ReviewedAt: day.AddDate(0, 0, 1), ReviewerID: "r1"})
+		}
+	}
+	queue := make([]QueueItem, 20)
+	for i := range queue {
+		queue[i] = QueueItem{ApplicationID: "Q", Stage: "review", SubmittedAt: time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC)}
+	}
+	asOf := time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)
+
+	fixed, err := BuildReport(context.Background(), events, queue, Options{AsOf: asOf, TargetClearDays: 14})
+	if err != nil {
+		t.Fatalf("build report: %v", err)
+	}
+	growing, err := BuildReport(context.Background(), events, queue, Options{AsOf: asOf, TargetClearDays: 14, Arrivals: true})
+	if err != nil {
+		t.Fatalf("build report: %v", err)
+	}
+	if fixed.Queue.Arrivals != nil {
+		t.Fatal("expected arrivals to stay off by default")
+	}
+	arrivals := growing.Queue.Stages[0].Arrivals
+	if arrivals == nil || !arrivals.Seasonal {
+		t.Fatalf("expected a seasonal arrival forecast, got %+v", arrivals)
+	}
+	if arrivals.WeekdayFactors[5] != 0 || arrivals.WeekdayFactors[6] != 0 || arrivals.WeekdayFactors[0] <= 1 {
+		t.Fatalf("expected weekends to get no arrivals, got %v", arrivals.WeekdayFactors)
+	}
+	expected := map[string]float64{}
+	for _, day := range arrivals.Daily {
+		expected[day.Date] = day.Expected
+	}
+	if expected["2026-02-21"] != 0 || expected["2026-02-24"] <= 2*expected["2026-02-17"] {
+		t.Fatalf("expected the deadline rush to be forecast, got %v", expected)
+	}
+
+	fixedStage, growingStage := fixed.Queue.Stages[0], growing.Queue.Stages[0]
+	if growingStage.EstimatedClearDays <= fixedStage.EstimatedClearDays {
+		t.Fatalf("expected arrivals to lengthen clearance: %.2f vs %.2f days", growingStage.EstimatedClearDays, fixedStage.EstimatedClearDays)
+	}
+	if growing.Queue.ClearancePlan.ExpectedArrivals <= 0 || growing.Queue.ClearancePlan.RequiredDaily <= fixed.Queue.ClearancePlan.RequiredDaily {
+		t.Fatalf("expected arrivals in the clearance plan: %+v", growing.Queue.ClearancePlan)
+	}
+
+	if days, cleared := clearDaysWithArrivals(10, 4, make([]float64, 5)); !cleared || days != 2.5 {
+		t.Fatalf("expected a fixed backlog to clear in 2.5 days, got %v", days)
+	}
+}
+
+func TestSurvivalEstimateCensorsPendingItems(t *testing.T) {
+	asOf := time.Date(2026, 1, 27, 0, 0, 0, 0, time.UTC)
+	reviewed := func(stage string, days int) ReviewEvent {
+		return ReviewEvent{ApplicationID: "A", Stage: stage, SubmittedAt: asOf.AddDate(0, 0, -30-days), ReviewedAt: asOf.AddDate(0, 0, -30), ReviewerID: "r1"}
+	}
+	pending := func(stage string, age int) QueueItem {
+		return QueueItem{ApplicationID: "Q", Stage: stage, SubmittedAt: asOf.AddDate(0, 0, -age)}
+	}
+	events := []ReviewEvent{reviewed("initial", 4), reviewed("initial", 5), reviewed("initial", 10), reviewed("committee", 1), reviewed("committee", 2)}
+	queue := []QueueItem{pending("initial", 4), pending("initial", 5), pending("committee", 20), pending("committee", 20), pending("committee", 20)}
+
+	report, err := BuildReport(context.Background(), events, queue, Options{AsOf: asOf})
+	if err != nil {
+		t.Fatalf("build report: %v", err)
+	}
+	byStage := map[string]StageStats{}
+	for _, stage := range report.Stages {
+		byStage[stage.Stage] = stage
+	}
+
+	// Survival drops to 0.8 at 4 days (5 at risk), to 0.53 at 5 days (3 at
+	// risk once the item pending 4 days is censored), and to 0 at 10 days.
+	initial := byStage["initial"]
+	if initial.MedianDays != 5 || initial.Survival == nil {
+		t.Fatalf("expected completed-only stats alongside a survival estimate, got %+v", initial)
+	}
+	if survival := initial.Survival; survival.Censored != 2 || *survival.MedianDays != 10 || *survival.P90Days != 10 || survival.ProbWithinSLA != 46.7 {
+		t.Fatalf("unexpected initial survival: %+v", survival)
+	}
+	// Three items pending for 20 days keep the curve above one half.
+	committee := byStage["committee"].Survival
+	if committee.MedianDays != nil || committee.P90Days != nil || committee.ProbWithinSLA != 40 {
+		t.Fatalf("expected the committee median to be unreached, got %+v", committee)
+	}
+	if report.Overall.Survival == nil || report.Overall.Survival.Censored != 5 {
+		t.Fatalf("unexpected overall survival: %+v", report.Overall.Survival)
+	}
+
+	withoutQueue, err := BuildReport(context.Background(), events, nil, Options{AsOf: asOf})
+	if err != nil {
+		t.Fatalf("build report: %v", err)
+	}
+	if withoutQueue.Overall.Survival != nil {
+		t.Fatal("expected no survival estimate without a queue")
+	}
+}
+
+func TestBacktestScoresReplayedAndStoredForecasts(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+	var events []ReviewEvent
+	// One review a day in the week before the as-of date sets a pace of 1/day.
+	for d := 3; d <= 9; d++ {
+		events = append(events, ReviewEvent{ApplicationID: "H", Stage: "s", SubmittedAt: day(1), ReviewedAt: day(d), ReviewerID: "r1"})
+	}
+	events = append(events,
+		ReviewEvent{ApplicationID: "A", Stage: "s", SubmittedAt: day(8), ReviewedAt: day(12), ReviewerID: "r1"},
+		ReviewEvent{ApplicationID: "B", Stage: "s", SubmittedAt: day(9), ReviewedAt: day(15), ReviewerID: "r1"},
+	)
+	queue := []QueueItem{{ApplicationID: "C", Stage: "t", SubmittedAt: day(9)}}
+
+	result, err := Backtest(context.Background(), events, queue, Options{ThroughputDays: 7}, BacktestOptions{Start: day(10), End: day(10)})
+	if err != nil {
+		t.Fatalf("backtest: %v", err)
+	}
+	if len(result.Points) != 1 {
+		t.Fatalf("expected one as-of date, got %+v", result.Points)
+	}
+	point := result.Points[0]
+	// A and B are projected 1 and 2 days out but took 2 and 5.
+	if point.Pending != 3 || point.Resolved != 2 || point.Unresolved != 1 || point.ItemMAE != 2 || point.ItemBias != -2 {
+		t.Fatalf("unexpected point %+v", point)
+	}
+	want := map[[3]string]ForecastAccuracy{
+		{"overall", "", MetricItemDays}:     {Samples: 2, MAE: 2, MAPE: 55, Bias: -2},
+		{"stage", "s", MetricClearDays}:     {Samples: 1, MAE: 3, MAPE: 60, Bias: -3},
+		{"reviewer", "r1", MetricClearDays}: {Samples: 1, MAE: 3, MAPE: 60, Bias: -3},
+	}
+	for _, row := range result.Accuracy {
+		if row.Key == "t" {
+			t.Fatalf("stage without throughput or reviews should not be scored: %+v", row)
+		}
+		expected, ok := want[[3]string{row.Scope, row.Key, row.Metric}]
+		if !ok {
+			continue
+		}
+		if row.Samples != expected.Samples || row.MAE != expected.MAE || row.MAPE != expected.MAPE || row.Bias != expected.Bias {
+			t.Fatalf("unexpected %s %s %s accuracy %+v", row.Scope, row.Key, row.Metric, row)
+		}
+		delete(want, [3]string{row.Scope, row.Key, row.Metric})
+	}
+	if len(want) > 0 {
+		t.Fatalf("missing accuracy rows %v", want)
+	}
+
+	// Stored runs: A leaves between the first two runs and B between the last
+	// two, so they count as reviewed after 1 and 4 days.
+	projection := func(applicationID string, projected float64) QueueItemProjection {
+		return QueueItemProjection{ApplicationID: applicationID, Stage: "s", ReviewerID: "r1", SubmittedAt: "2026-01-08T00:00:00Z", ProjectedDays: projected, ProjectedReviewAt: "set"}
+	}
+	run := func(id int64, asOf time.Time, projections ...QueueItemProjection) BacktestRun {
+		return BacktestRun{ID: id, Report: Report{Queue: &QueueReport{AsOf: asOf.Format(time.RFC3339), ItemProjections: projections}}}
+	}
+	stored := BacktestStoredRuns([]BacktestRun{
+		run(3, day(16)),
+		run(1, day(10), projection("A", 1), projection("B", 2)),
+		run(2, day(12), projection("B", 1)),
+		run(4, day(18), projection("D", 1)),
+	})
+	if len(stored.Points) != 4 || stored.Points[0].RunID != 1 {
+		t.Fatalf("expected runs ordered by as-of, got %+v", stored.Points)
+	}
+	if first := stored.Points[0]; first.Resolved != 2 || first.ItemMAE != 1 || first.ItemBias != -1 {
+		t.Fatalf("unexpected first run score %+v", first)
+	}
+	if last := stored.Points[3]; last.Resolved != 0 || last.Unresolved != 1 {
+		t.Fatalf("items in the newest run should be unresolved, got %+v", last)
+	}
+}
+
+func TestRoundHalfUpIncludingNegatives(t *testing.T) {
+	cases := []struct {
+		value  float64
+		places int
+		want   float64
+	}{
+		{1.25, 1, 1.3},
+		{-1.25, 1, -1.2},
+		{-0.05, 1, 0},
+		{-2, 2, -2},
+		{-1.999, 2, -2},
+		{0.125, 2, 0.13},
+		{-0.125, 2, -0.12},
+		{12.5, 0, 13},
+		{-12.5, 0, -12},
+		{-12.6, 0, -13},
+	}
+	for _, tc := range cases {
+		if got := Round(tc.value, tc.places); got != tc.want {
+			t.Errorf("Round(%v, %d) = %v, want %v", tc.value, tc.places, got, tc.want)
+		}
+	}
+}
+
+func TestQueueReportKeepsWholeNegativeGaps(t *testing.T) {
+	asOf := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	var queue []QueueItem
+	for i := 0; i < 14; i++ {
+		queue = append(queue, QueueItem{ApplicationID: "Q-" + strconv.Itoa(i), Stage: "s", SubmittedAt: asOf.AddDate(0, 0, -2)})
+	}
+	// Three reviews a day against a target of one a day leaves a surplus of 2.
+	var events []ReviewEvent
+	for d := 1; d <= 7; d++ {
+		for i := 0; i < 3; i++ {
+			events = append(events, ReviewEvent{ApplicationID: "E", Stage: "s", SubmittedAt: asOf.AddDate(0, 0, -10), ReviewedAt: asOf.AddDate(0, 0, -d), ReviewerID: "r1"})
+		}
+	}
+	queueReport, err := BuildQueueReport(context.Background(), queue, events, nil, asOf, Options{ThroughputDays: 7, TargetClearDays: 14})
+	if err != nil {
+		t.Fatalf("build queue report: %v", err)
+	}
+	stage := queueReport.Stages[0]
+	if stage.ThroughputGapDaily != -2 || stage.ThroughputGapWeekly != -14 {
+		t.Fatalf("expected gaps of -2 daily and -14 weekly, got %v and %v", stage.ThroughputGapDaily, stage.ThroughputGapWeekly)
+	}
+	if plan := queueReport.ClearancePlan; plan == nil || plan.GapDaily != -2 || plan.GapWeekly != -14 {
+		t.Fatalf("expected the clearance plan to keep the same gaps, got %+v", plan)
+	}
+}
+
+func TestBacktestZeroSettleDaysReplaysUpToTheLastReview(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+	var events []ReviewEvent
+	for d := 3; d <= 15; d++ {
+		events = append(events, ReviewEvent{ApplicationID: "A-" + strconv.Itoa(d), Stage: "s", SubmittedAt: day(1), ReviewedAt: day(d).Add(9 * time.Hour), ReviewerID: "r1"})
+	}
+	for _, tc := range []struct {
+		settle int
+		end    time.Time
+	}{
+		{0, day(15)},
+		{1, day(14)},
+	} {
+		result, err := Backtest(context.Background(), events, nil, Options{ThroughputDays: 7}, BacktestOptions{Start: day(10), SettleDays: tc.settle})
+		if err != nil {
+			t.Fatalf("backtest with %d settle days: %v", tc.settle, err)
+		}
+		if result.End != tc.end.Format(time.RFC3339) {
+			t.Fatalf("with %d settle days expected end %s, got %s", tc.settle, tc.end.Format(time.RFC3339), result.End)
+		}
+	}
+	// A negative value falls back to the 28-day default, which leaves no dates.
+	if _, err := Backtest(context.Background(), events, nil, Options{ThroughputDays: 7}, BacktestOptions{Start: day(10), SettleDays: -1}); err == nil {
+		t.Fatal("expected the default settle window to leave no as-of dates")
+	}
+}
@@ -0,0 +1,370 @@
+package forecast
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Journey statuses. An application is in flight while it has a pending queue
+// item, complete once the last stage in the order has reviewed it, and
+// incomplete otherwise (it left the pipeline or awaits its next queue entry).
+const (
+	JourneyComplete   = "complete"
+	JourneyInFlight   = "in_flight"
+	JourneyIncomplete = "incomplete"
+)
+
+const defaultJourneyTop = 10
+
+// journeyAccumulator stitches streamed events into per-application journeys.
+// Steps keep a stage index and Unix nanoseconds rather than whole events, so a
+// journey costs a few words per review.
+type journeyAccumulator struct {
+	stageIndex map[string]int32
+	stageNames []string
+	apps       map[string][]journeyStep
+}
+
+type journeyStep struct {
+	stage     int32
+	submitted int64
+	reviewed  int64
+}
+
+func newJourneyAccumulator() *journeyAccumulator {
+	return &journeyAccumulator{
+		stageIndex: map[string]int32{},
+		apps:       map[string][]journeyStep{},
+	}
+}
+
+func (j *journeyAccumulator) add(event ReviewEvent) {
+	applicationID := strings.TrimSpace(event.ApplicationID)
+	if applicationID == "" {
+		return
+	}
+	j.apps[applicationID] = append(j.apps[applicationID], journeyStep{
+		stage:     j.stage(event.Stage),
+		submitted: event.SubmittedAt.UnixNano(),
+		reviewed:  event.ReviewedAt.UnixNano(),
+	})
+}
+
+// stage interns a stage name, remembering the order stages were first seen.
+func (j *journeyAccumulator) stage(name string) int32 {
+	if index, ok := j.stageIndex[name]; ok {
+		return index
+	}
+	index := int32(len(j.stageNames))
+	j.stageIndex[name] = index
+	j.stageNames = append(j.stageNames, name)
+	return index
+}
+
+// journey is one application's reviewed steps, oldest submission first, plus
+// any pending queue items.
+type journey struct {
+	applicationID string
+	steps         []journeyStep
+	pending       []QueueItem
+}
+
+func (s journeyStep) submittedAt() time.Time {
+	return time.Unix(0, s.submitted)
+}
+
+func (s journeyStep) reviewedAt() time.Time {
+	return time.Unix(0, s.reviewed)
+}
+
+// report builds the journey section as of asOf. Queue items are matched to
+// journeys by application_id; items for unseen applications start new ones.
+func (j *journeyAccumulator) report(queueItems []QueueItem, asOf time.Time, opts Options) *JourneyReport {
+	for _, item := range queueItems {
+		j.stage(item.Stage)
+	}
+	journeys := make(map[string]*journey, len(j.apps))
+	for applicationID, steps := range j.apps {
+		sort.Slice(steps, func(a, b int) bool {
+			if steps[a].submitted == steps[b].submitted {
+				return steps[a].reviewed < steps[b].reviewed
+			}
+			return steps[a].submitted < steps[b].submitted
+		})
+		journeys[applicationID] = &journey{applicationID: applicationID, steps: steps}
+	}
+	for _, item := range queueItems {
+		applicationID := strings.TrimSpace(item.ApplicationID)
+		if applicationID == "" {
+			continue
+		}
+		entry, ok := journeys[applicationID]
+		if !ok {
+			entry = &journey{applicationID: applicationID}
+			journeys[applicationID] = entry
+		}
+		entry.pending = append(entry.pending, item)
+	}
+
+	order := j.stageOrder(journeys, opts.StageOrder)
+	position := make(map[string]int, len(order))
+	for i, stage := range order {
+		position[stage] = i
+	}
+	first, last := order[0], order[len(order)-1]
+	cal := opts.Calendar
+	location := cal.location()
+
+	report := &JourneyReport{
+		StageOrder:   order,
+		Applications: len(journeys),
+		Handoffs:     []JourneyHandoff{},
+		Conversions:  []StageConversion{},
+		Slowest:      []JourneySummary{},
+		Progress:     []JourneyProgress{},
+	}
+	var cycle latencyAccumulator
+	handoffs := map[[2]string]*latencyAccumulator{}
+	reviewedIn := map[string]int{}
+	entered := map[[2]string]int{}
+	candidates := make([]JourneySummary, 0, len(journeys))
+
+	for _, entry := range journeys {
+		reviewed := map[string]bool{}
+		reached := map[string]bool{}
+		var previous string
+		var previousReviewed time.Time
+		var waitDays float64
+		var finished time.Time
+		addHandoff := func(stage string, submittedAt time.Time) {
+			if previous == "" {
+				return
+			}
+			wait := cal.Days(previousReviewed, submittedAt)
+			if wait < 0 {
+				wait = 0
+			}
+			waitDays += wait
+			key := [2]string{previous, stage}
+			acc, ok := handoffs[key]
+			if !ok {
+				acc = &latencyAccumulator{}
+				handoffs[key] = acc
+			}
+			acc.addDuration(wait)
+		}
+		for _, step := range entry.steps {
+			stage := j.stageNames[step.stage]
+			addHandoff(stage, step.submittedAt())
+			reviewed[stage] = true
+			reached[stage] = true
+			previous, previousReviewed = stage, step.reviewedAt()
+			if stage == last && previousReviewed.After(finished) {
+				finished = previousReviewed
+			}
+		}
+		sort.Slice(entry.pending, func(a, b int) bool {
+			return entry.pending[a].SubmittedAt.Before(entry.pending[b].SubmittedAt)
+		})
+		for _, item := range entry.pending {
+			addHandoff(item.Stage, item.SubmittedAt)
+			reached[item.Stage] = true
+		}
+
+		for stage := range reviewed {
+			reviewedIn[stage]++
+		}
+		for i := 0; i+1 < len(order); i++ {
+			if reviewed[order[i]] && reached[order[i+1]] {
+				entered[[2]string{order[i], order[i+1]}]++
+			}
+		}
+
+		var startedAt time.Time
+		var startStage string
+		if len(entry.steps) > 0 {
+			startedAt, startStage = entry.steps[0].submittedAt(), j.stageNames[entry.steps[0].stage]
+		}
+		if len(entry.pending) > 0 && (startedAt.IsZero() || entry.pending[0].SubmittedAt.Before(startedAt)) {
+			startedAt, startStage = entry.pending[0].SubmittedAt, entry.pending[0].Stage
+		}
+		completed := 0
+		for _, stage := range order {
+			if reviewed[stage] {
+				completed++
+			}
+		}
+
+		summary := JourneySummary{
+			ApplicationID:   entry.applicationID,
+			Path:            journeyPath(entry, j.stageNames),
+			StartedAt:       startedAt.In(location).Format(time.RFC3339),
+			StagesCompleted: completed,
+			WaitDays:        Round(waitDays, 2),
+		}
+		switch {
+		case len(entry.pending) > 0:
+			summary.Status = JourneyInFlight
+			summary.EndedAt = asOf.Format(time.RFC3339)
+			summary.CycleDays = Round(max(cal.Days(startedAt, asOf), 0), 2)
+			report.InFlight++
+			for _, item := range entry.pending {
+				report.Progress = append(report.Progress, JourneyProgress{
+					ApplicationID:   entry.applicationID,
+					Stage:           item.Stage,
+					Step:            position[item.Stage] + 1,
+					Steps:           len(order),
+					StagesCompleted: completed,
+					ProgressPercent: Percent(completed, len(order)),
+					StartedAt:       summary.StartedAt,
+					JourneyAgeDays:  summary.CycleDays,
+				})
+			}
+		case reviewed[last]:
+			summary.Status = JourneyComplete
+			summary.EndedAt = finished.In(location).Format(time.RFC3339)
+			summary.CycleDays = Round(max(cal.Days(startedAt, finished), 0), 2)
+			report.Completed++
+			// Journeys first seen past the opening stage began before the
+			// history did, so they would understate cycle time.
+			if startStage == first {
+				cycle.addDuration(max(cal.Days(startedAt, finished), 0))
+			}
+		default:
+			summary.Status = JourneyIncomplete
+			report.Incomplete++
+			continue
+		}
+		candidates = append(candidates, summary)
+	}
+
+	report.CycleTime = JourneyDuration{
+		Count:       cycle.count,
+		AverageDays: Round(cycle.average(), 2),
+		MedianDays:  Round(cycle.sketch.quantile(50), 2),
+		P90Days:     Round(cycle.sketch.quantile(90), 2),
+		MaxDays:     Round(cycle.max, 2),
+	}
+
+	for key, acc := range handoffs {
+		report.Handoffs = append(report.Handoffs, JourneyHandoff{
+			From:            key[0],
+			To:              key[1],
+			Count:           acc.count,
+			AverageWaitDays: Round(acc.average(), 2),
+			MedianWaitDays:  Round(acc.sketch.quantile(50), 2),
+			P90WaitDays:     Round(acc.sketch.quantile(90), 2),
+			MaxWaitDays:     Round(acc.max, 2),
+		})
+	}
+	sort.Slice(report.Handoffs, func(a, b int) bool {
+		left, right := report.Handoffs[a], report.Handoffs[b]
+		if position[left.From] != position[right.From] {
+			return position[left.From] < position[right.From]
+		}
+		return position[left.To] < position[right.To]
+	})
+
+	for i := 0; i+1 < len(order); i++ {
+		key := [2]string{order[i], order[i+1]}
+		report.Conversions = append(report.Conversions, StageConversion{
+			From:           key[0],
+			To:             key[1],
+			Reviewed:       reviewedIn[key[0]],
+			Entered:        entered[key],
+			ConversionRate: Percent(entered[key], reviewedIn[key[0]]),
+		})
+	}
+
+	sort.Slice(candidates, func(a, b int) bool {
+		if candidates[a].CycleDays == candidates[b].CycleDays {
+			return candidates[a].ApplicationID < candidates[b].ApplicationID
+		}
+		return candidates[a].CycleDays > candidates[b].CycleDays
+	})
+	top := opts.JourneyTop
+	if top <= 0 {
+		top = defaultJourneyTop
+	}
+	report.Slowest = append(report.Slowest, candidates[:min(top, len(candidates))]...)
+
+	sort.Slice(report.Progress, func(a, b int) bool {
+		left, right := report.Progress[a], report.Progress[b]
+		if left.Step != right.Step {
+			return left.Step > right.Step
+		}
+		if left.JourneyAgeDays != right.JourneyAgeDays {
+			return left.JourneyAgeDays > right.JourneyAgeDays
+		}
+		return left.ApplicationID < right.ApplicationID
+	})
+	return report
+}
+
+// stageOrder returns the explicit order followed by any other stages, which
+// are ranked by how often they precede the rest within a journey. Ties keep
+// the order stages were first seen in, which follows the pipeline for most
+// chronological exports.
+func (j *journeyAccumulator) stageOrder(journeys map[string]*journey, explicit []string) []string {
+	order := make([]string, 0, len(j.stageNames))
+	listed := map[string]bool{}
+	for _, stage := range explicit {
+		stage = strings.TrimSpace(stage)
+		if stage == "" || listed[stage] {
+			continue
+		}
+		listed[stage] = true
+		order = append(order, stage)
+	}
+
+	score := make([]int, len(j.stageNames))
+	for _, entry := range journeys {
+		seen := map[int32]bool{}
+		sequence := make([]int32, 0, len(entry.steps)+len(entry.pending))
+		for _, step := range entry.steps {
+			if !seen[step.stage] {
+				seen[step.stage] = true
+				sequence = append(sequence, step.stage)
+			}
+		}
+		for _, item := range entry.pending {
+			if index := j.stageIndex[item.Stage]; !seen[index] {
+				seen[index] = true
+				sequence = append(sequence, index)
+			}
+		}
+		for i, stage := range sequence {
+			score[stage] += len(sequence) - 1 - 2*i
+		}
+	}
+	rest := make([]int, 0, len(j.stageNames))
+	for index, stage := range j.stageNames {
+		if !listed[stage] {
+			rest = append(rest, index)
+		}
+	}
+	sort.SliceStable(rest, func(a, b int) bool {
+		return score[rest[a]] > score[rest[b]]
+	})
+	for _, index := range rest {
+		order = append(order, j.stageNames[index])
+	}
+	if len(order) == 0 {
+		order = append(order, "")
+	}
+	return order
+}
+
+// journeyPath joins the stages an application passed through, ending with any
+// pending stage in brackets.
+func journeyPath(entry *journey, stageNames []string) string {
+	parts := make([]string, 0, len(entry.steps)+len(entry.pending))
+	for _, step := range entry.steps {
+		parts = append(parts, stageNames[step.stage])
+	}
+	for _, item := range entry.pending {
+		parts = append(parts, "["+item.Stage+"]")
+	}
+	return strings.Join(parts, " > ")
+}
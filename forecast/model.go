@@ -0,0 +1,379 @@
+// Package forecast computes review latency, SLA risk, throughput trends, queue
+// forecasts, and insights from completed review events and a pending queue.
+package forecast
+
+import (
+	"time"
+)
+
+type ReviewEvent struct {
+	ApplicationID string
+	Stage         string
+	SubmittedAt   time.Time
+	ReviewedAt    time.Time
+	ReviewerID    string
+	Program       string
+	Priority      string
+}
+
+type QueueItem struct {
+	ApplicationID string
+	Stage         string
+	SubmittedAt   time.Time
+	ReviewerID    string
+	Program       string
+	Priority      string
+}
+
+type StageStats struct {
+	Stage             string       `json:"stage"`
+	SLADays           int          `json:"sla_days"`
+	Count             int          `json:"count"`
+	AverageDays       float64      `json:"average_days"`
+	MedianDays        float64      `json:"median_days"`
+	P90Days           float64      `json:"p90_days"`
+	MaxDays           float64      `json:"max_days"`
+	SLABreachCount    int          `json:"sla_breach_count"`
+	SLABreachRate     float64      `json:"sla_breach_rate"`
+	DistinctReviewers int          `json:"distinct_reviewers"`
+	AgingBuckets      AgingBuckets `json:"aging_buckets"`
+	RiskTier          string       `json:"risk_tier"`
+	// Survival is set when the report has a pending queue.
+	Survival *SurvivalEstimate `json:"survival,omitempty"`
+}
+
+// SurvivalEstimate is a Kaplan–Meier latency estimate that counts pending queue
+// items as reviews still in progress (right-censored at their current age)
+// rather than leaving them out. MedianDays and P90Days are nil when too many
+// items are still pending for the estimate to reach them. ProbWithinSLA is the
+// chance, in percent, that an item is reviewed within the stage SLA.
+type SurvivalEstimate struct {
+	Completed     int      `json:"completed"`
+	Censored      int      `json:"censored"`
+	MedianDays    *float64 `json:"median_days"`
+	P90Days       *float64 `json:"p90_days"`
+	ProbWithinSLA float64  `json:"prob_within_sla"`
+}
+
+type AgingBuckets struct {
+	OnTime  int `json:"on_time"`
+	AtRisk  int `json:"at_risk"`
+	Overdue int `json:"overdue"`
+}
+
+type ReviewerStats struct {
+	ReviewerID        string       `json:"reviewer_id"`
+	Count             int          `json:"count"`
+	AverageDays       float64      `json:"average_days"`
+	MedianDays        float64      `json:"median_days"`
+	P90Days           float64      `json:"p90_days"`
+	MaxDays           float64      `json:"max_days"`
+	SLABreachCount    int          `json:"sla_breach_count"`
+	SLABreachRate     float64      `json:"sla_breach_rate"`
+	LastReviewedAt    string       `json:"last_reviewed_at"`
+	ThroughputPerWeek float64      `json:"throughput_per_week"`
+	WindowCount       int          `json:"window_count"`
+	AgingBuckets      AgingBuckets `json:"aging_buckets"`
+	RiskTier          string       `json:"risk_tier"`
+}
+
+type ThroughputSummary struct {
+	AsOf              string  `json:"as_of"`
+	WindowDays        int     `json:"window_days"`
+	EventsInWindow    int     `json:"events_in_window"`
+	ThroughputPerWeek float64 `json:"throughput_per_week"`
+}
+
+type ThroughputTrend struct {
+	Label          string  `json:"label"`
+	CurrentCount   int     `json:"current_count"`
+	PriorCount     int     `json:"prior_count"`
+	Delta          int     `json:"delta"`
+	DeltaPercent   float64 `json:"delta_percent"`
+	CurrentPerWeek float64 `json:"current_per_week"`
+	PriorPerWeek   float64 `json:"prior_per_week"`
+	Trend          string  `json:"trend"`
+}
+
+type ThroughputTrendSummary struct {
+	CurrentWindowStart string            `json:"current_window_start"`
+	CurrentWindowEnd   string            `json:"current_window_end"`
+	PriorWindowStart   string            `json:"prior_window_start"`
+	PriorWindowEnd     string            `json:"prior_window_end"`
+	WindowDays         int               `json:"window_days"`
+	Trends             []ThroughputTrend `json:"trends"`
+}
+
+type LatencyTrend struct {
+	Label             string  `json:"label"`
+	CurrentCount      int     `json:"current_count"`
+	PriorCount        int     `json:"prior_count"`
+	CurrentAvgDays    float64 `json:"current_avg_days"`
+	PriorAvgDays      float64 `json:"prior_avg_days"`
+	AvgDeltaDays      float64 `json:"avg_delta_days"`
+	AvgDeltaPercent   float64 `json:"avg_delta_percent"`
+	CurrentMedianDays float64 `json:"current_median_days"`
+	PriorMedianDays   float64 `json:"prior_median_days"`
+	MedianDeltaDays   float64 `json:"median_delta_days"`
+	MedianDeltaPct    float64 `json:"median_delta_pct"`
+	Trend             string  `json:"trend"`
+}
+
+type LatencyTrendSummary struct {
+	CurrentWindowStart string         `json:"current_window_start"`
+	CurrentWindowEnd   string         `json:"current_window_end"`
+	PriorWindowStart   string         `json:"prior_window_start"`
+	PriorWindowEnd     string         `json:"prior_window_end"`
+	WindowDays         int            `json:"window_days"`
+	Trends             []LatencyTrend `json:"trends"`
+}
+
+type QueueStageForecast struct {
+	Stage                    string               `json:"stage"`
+	SLADays                  int                  `json:"sla_days"`
+	DueSoonRatio             float64              `json:"due_soon_ratio"`
+	PendingCount             int                  `json:"pending_count"`
+	AvgAgeDays               float64              `json:"avg_age_days"`
+	OverdueCount             int                  `json:"overdue_count"`
+	DueSoonCount             int                  `json:"due_soon_count"`
+	OnTrackCount             int                  `json:"on_track_count"`
+	DailyThroughput          float64              `json:"daily_throughput"`
+	EstimatedClearDays       float64              `json:"estimated_clear_days"`
+	ClearanceStatus          string               `json:"clearance_status"`
+	RequiredDailyThroughput  float64              `json:"required_daily_throughput"`
+	RequiredWeeklyThroughput float64              `json:"required_weekly_throughput"`
+	ThroughputGapDaily       float64              `json:"throughput_gap_daily"`
+	ThroughputGapWeekly      float64              `json:"throughput_gap_weekly"`
+	CapacityStatus           string               `json:"capacity_status"`
+	Simulation               *ClearanceSimulation `json:"simulation,omitempty"`
+	Arrivals                 *ArrivalForecast     `json:"arrivals,omitempty"`
+}
+
+// ArrivalForecast projects new submissions from the daily arrival history.
+// RecentDaily is the mean over the throughput window ending at the as-of date;
+// WeekdayFactors scale it Monday first, and Seasonal reports whether earlier
+// years of history shaped the forecast. NetDaily is ForecastDaily minus the
+// daily throughput, so a positive value means the backlog is growing.
+type ArrivalForecast struct {
+	HistoryDays    int          `json:"history_days"`
+	RecentDaily    float64      `json:"recent_daily"`
+	ForecastDaily  float64      `json:"forecast_daily"`
+	Next7Days      float64      `json:"next_7_days"`
+	Next28Days     float64      `json:"next_28_days"`
+	NetDaily       float64      `json:"net_daily"`
+	WeekdayFactors []float64    `json:"weekday_factors"`
+	Seasonal       bool         `json:"seasonal"`
+	Daily          []ArrivalDay `json:"daily"`
+}
+
+type ArrivalDay struct {
+	Date     string  `json:"date"`
+	Expected float64 `json:"expected"`
+}
+
+type QueueReviewerForecast struct {
+	ReviewerID         string  `json:"reviewer_id"`
+	PendingCount       int     `json:"pending_count"`
+	AvgAgeDays         float64 `json:"avg_age_days"`
+	OverdueCount       int     `json:"overdue_count"`
+	DueSoonCount       int     `json:"due_soon_count"`
+	OnTrackCount       int     `json:"on_track_count"`
+	ThroughputPerWeek  float64 `json:"throughput_per_week"`
+	EstimatedClearDays float64 `json:"estimated_clear_days"`
+	ClearanceStatus    string  `json:"clearance_status"`
+}
+
+type QueueReport struct {
+	AsOf             string                  `json:"as_of"`
+	TotalPending     int                     `json:"total_pending"`
+	AssignedCount    int                     `json:"assigned_count"`
+	UnassignedCount  int                     `json:"unassigned_count"`
+	OverdueCount     int                     `json:"overdue_count"`
+	DueSoonCount     int                     `json:"due_soon_count"`
+	OnTrackCount     int                     `json:"on_track_count"`
+	AvgAgeDays       float64                 `json:"avg_age_days"`
+	Stages           []QueueStageForecast    `json:"stages"`
+	Reviewers        []QueueReviewerForecast `json:"reviewers"`
+	PriorityItems    []QueuePriorityItem     `json:"priority_items"`
+	ItemProjections  []QueueItemProjection   `json:"item_projections"`
+	ProjectedPastSLA int                     `json:"projected_past_sla"`
+	ThroughputDays   int                     `json:"throughput_days"`
+	DueSoonRatio     float64                 `json:"due_soon_ratio"`
+	ClearancePlan    *QueueClearancePlan     `json:"clearance_plan,omitempty"`
+	Simulation       *ClearanceSimulation    `json:"simulation,omitempty"`
+	Arrivals         *ArrivalForecast        `json:"arrivals,omitempty"`
+}
+
+type QueueClearancePlan struct {
+	TargetDays     int     `json:"target_days"`
+	RequiredDaily  float64 `json:"required_daily"`
+	RequiredWeekly float64 `json:"required_weekly"`
+	CurrentDaily   float64 `json:"current_daily"`
+	CurrentWeekly  float64 `json:"current_weekly"`
+	GapDaily       float64 `json:"gap_daily"`
+	GapWeekly      float64 `json:"gap_weekly"`
+	Status         string  `json:"status"`
+	// ExpectedArrivals is the forecast of new submissions within the target,
+	// already included in the required pace.
+	ExpectedArrivals float64 `json:"expected_arrivals,omitempty"`
+}
+
+type QueuePriorityItem struct {
+	ApplicationID string  `json:"application_id"`
+	Stage         string  `json:"stage"`
+	ReviewerID    string  `json:"reviewer_id"`
+	SubmittedAt   string  `json:"submitted_at"`
+	AgeDays       float64 `json:"age_days"`
+	DaysToSLA     float64 `json:"days_to_sla"`
+	UrgencyScore  float64 `json:"urgency_score"`
+	Status        string  `json:"status"`
+}
+
+type Report struct {
+	GeneratedAt     string                 `json:"generated_at"`
+	TotalEvents     int                    `json:"total_events"`
+	Overall         StageStats             `json:"overall"`
+	Stages          []StageStats           `json:"stages"`
+	Reviewers       []ReviewerStats        `json:"reviewers"`
+	SLADays         int                    `json:"sla_days"`
+	SLAPolicy       []StagePolicy          `json:"sla_policy"`
+	SLAPolicySource string                 `json:"sla_policy_source,omitempty"`
+	Timezone        string                 `json:"timezone"`
+	Calendar        CalendarSummary        `json:"calendar"`
+	Throughput      ThroughputSummary      `json:"throughput"`
+	ThroughputTrend ThroughputTrendSummary `json:"throughput_trend"`
+	LatencyTrend    LatencyTrendSummary    `json:"latency_trend"`
+	Insights        []Insight              `json:"insights"`
+	Queue           *QueueReport           `json:"queue,omitempty"`
+	Journeys        *JourneyReport         `json:"journeys,omitempty"`
+	DataQuality     []DataQualityInput     `json:"data_quality,omitempty"`
+	RunConfig       *RunConfig             `json:"run_config,omitempty"`
+}
+
+// DataQualityInput tallies what a lenient load skipped or flagged in one input.
+type DataQualityInput struct {
+	Input        string             `json:"input"`
+	Source       string             `json:"source"`
+	RowsRead     int                `json:"rows_read"`
+	RowsAccepted int                `json:"rows_accepted"`
+	RowsSkipped  int                `json:"rows_skipped"`
+	Issues       []DataQualityIssue `json:"issues"`
+}
+
+// DataQualityIssue counts one kind of problem. Action is "skipped" when the rows
+// were dropped and "kept" when they were only flagged.
+type DataQualityIssue struct {
+	Issue      string `json:"issue"`
+	Action     string `json:"action"`
+	Count      int    `json:"count"`
+	SampleRows []int  `json:"sample_rows"`
+}
+
+// JourneyReport follows applications across stages by stitching review events
+// and queue items on application_id. Stages are listed in pipeline order.
+type JourneyReport struct {
+	StageOrder   []string          `json:"stage_order"`
+	Applications int               `json:"applications"`
+	Completed    int               `json:"completed"`
+	InFlight     int               `json:"in_flight"`
+	Incomplete   int               `json:"incomplete"`
+	CycleTime    JourneyDuration   `json:"cycle_time"`
+	Handoffs     []JourneyHandoff  `json:"handoffs"`
+	Conversions  []StageConversion `json:"conversions"`
+	Slowest      []JourneySummary  `json:"slowest"`
+	Progress     []JourneyProgress `json:"progress"`
+}
+
+// JourneyDuration summarizes end-to-end cycle time for complete journeys that
+// were seen from the first stage: first submission to the last stage's review.
+type JourneyDuration struct {
+	Count       int     `json:"count"`
+	AverageDays float64 `json:"average_days"`
+	MedianDays  float64 `json:"median_days"`
+	P90Days     float64 `json:"p90_days"`
+	MaxDays     float64 `json:"max_days"`
+}
+
+// JourneyHandoff is the wait between a review finishing in From and the
+// application being submitted to To, pending queue entries included.
+type JourneyHandoff struct {
+	From            string  `json:"from"`
+	To              string  `json:"to"`
+	Count           int     `json:"count"`
+	AverageWaitDays float64 `json:"average_wait_days"`
+	MedianWaitDays  float64 `json:"median_wait_days"`
+	P90WaitDays     float64 `json:"p90_wait_days"`
+	MaxWaitDays     float64 `json:"max_wait_days"`
+}
+
+// StageConversion counts applications reviewed in From and how many of them
+// went on to To, whether reviewed there or still pending.
+type StageConversion struct {
+	From           string  `json:"from"`
+	To             string  `json:"to"`
+	Reviewed       int     `json:"reviewed"`
+	Entered        int     `json:"entered"`
+	ConversionRate float64 `json:"conversion_rate"`
+}
+
+// JourneySummary is one application's journey. In-flight journeys run to the
+// as-of date; WaitDays is the time spent between stages.
+type JourneySummary struct {
+	ApplicationID   string  `json:"application_id"`
+	Status          string  `json:"status"`
+	Path            string  `json:"path"`
+	StartedAt       string  `json:"started_at"`
+	EndedAt         string  `json:"ended_at"`
+	StagesCompleted int     `json:"stages_completed"`
+	CycleDays       float64 `json:"cycle_days"`
+	WaitDays        float64 `json:"wait_days"`
+}
+
+// JourneyProgress places a pending queue item within its application's journey:
+// Step is the queued stage's position in the stage order.
+type JourneyProgress struct {
+	ApplicationID   string  `json:"application_id"`
+	Stage           string  `json:"stage"`
+	Step            int     `json:"step"`
+	Steps           int     `json:"steps"`
+	StagesCompleted int     `json:"stages_completed"`
+	ProgressPercent float64 `json:"progress_percent"`
+	StartedAt       string  `json:"started_at"`
+	JourneyAgeDays  float64 `json:"journey_age_days"`
+}
+
+type Insight struct {
+	Severity string `json:"severity"`
+	Area     string `json:"area"`
+	Message  string `json:"message"`
+	Metric   string `json:"metric"`
+}
+
+// RunConfig records the resolved settings for a run and where each value came
+// from, so stored runs can be reproduced.
+type RunConfig struct {
+	ConfigPath string                   `json:"config_path,omitempty"`
+	Profile    string                   `json:"profile,omitempty"`
+	Settings   map[string]ConfigSetting `json:"settings"`
+}
+
+type ConfigSetting struct {
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// MetricSeries is the history of one metric across stored runs, oldest first.
+// Scope is stage, reviewer, or queue; Key names the stage or reviewer.
+type MetricSeries struct {
+	Metric string        `json:"metric"`
+	Scope  string        `json:"scope"`
+	Key    string        `json:"key"`
+	Points []MetricPoint `json:"points"`
+}
+
+// MetricPoint is one run's value. Value is nil when the run did not record it.
+type MetricPoint struct {
+	RunID       int64    `json:"run_id"`
+	GeneratedAt string   `json:"generated_at"`
+	Value       *float64 `json:"value"`
+}
@@ -0,0 +1,208 @@
+package forecast
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+type SLAThreshold struct {
+	SLADays      int     `json:"sla_days"`
+	DueSoonRatio float64 `json:"due_soon_ratio"`
+}
+
+type SLARule struct {
+	Stage        string  `json:"stage,omitempty"`
+	Program      string  `json:"program,omitempty"`
+	Priority     string  `json:"priority,omitempty"`
+	SLADays      int     `json:"sla_days"`
+	DueSoonRatio float64 `json:"due_soon_ratio,omitempty"`
+}
+
+// SLAPolicy resolves SLA thresholds for a stage, optionally narrowed by program
+// and priority. The most specific matching rule wins; ties go to the earlier rule.
+type SLAPolicy struct {
+	Default SLAThreshold `json:"default"`
+	Rules   []SLARule    `json:"rules"`
+	Source  string       `json:"-"`
+}
+
+type StagePolicy struct {
+	Stage        string  `json:"stage"`
+	SLADays      int     `json:"sla_days"`
+	DueSoonRatio float64 `json:"due_soon_ratio"`
+	Source       string  `json:"source"`
+	Overrides    int     `json:"overrides"`
+}
+
+// NewSLAPolicy returns a policy with a default threshold and no rules.
+func NewSLAPolicy(slaDays int, dueSoonRatio float64) SLAPolicy {
+	return SLAPolicy{Default: SLAThreshold{SLADays: slaDays, DueSoonRatio: normalizeDueSoonRatio(dueSoonRatio)}}
+}
+
+// LoadSLAPolicy reads a JSON policy file. Values missing from the file's default
+// block fall back to the --sla-days and --due-soon-ratio flags.
+func LoadSLAPolicy(path string, slaDays int, dueSoonRatio float64) (SLAPolicy, error) {
+	policy := NewSLAPolicy(slaDays, dueSoonRatio)
+	if strings.TrimSpace(path) == "" {
+		return policy, nil
+	}
+	payload, err := os.ReadFile(path)
+	if err != nil {
+		return SLAPolicy{}, err
+	}
+	var file SLAPolicy
+	if err := json.Unmarshal(payload, &file); err != nil {
+		return SLAPolicy{}, fmt.Errorf("invalid SLA policy: %w", err)
+	}
+	if file.Default.SLADays > 0 {
+		policy.Default.SLADays = file.Default.SLADays
+	}
+	if file.Default.DueSoonRatio > 0 {
+		policy.Default.DueSoonRatio = normalizeDueSoonRatio(file.Default.DueSoonRatio)
+	}
+	for i, rule := range file.Rules {
+		if rule.SLADays <= 0 {
+			return SLAPolicy{}, fmt.Errorf("rule %d: sla_days must be positive", i+1)
+		}
+		if rule.DueSoonRatio < 0 || rule.DueSoonRatio >= 1 {
+			return SLAPolicy{}, fmt.Errorf("rule %d: due_soon_ratio must be between 0 and 1", i+1)
+		}
+		if strings.TrimSpace(rule.Stage) == "" && strings.TrimSpace(rule.Program) == "" && strings.TrimSpace(rule.Priority) == "" {
+			return SLAPolicy{}, fmt.Errorf("rule %d: needs a stage, program, or priority", i+1)
+		}
+		policy.Rules = append(policy.Rules, rule)
+	}
+	policy.Source = SanitizePath(path)
+	return policy, nil
+}
+
+func (p SLAPolicy) Resolve(stage string, program string, priority string) SLAThreshold {
+	threshold, _ := p.resolve(stage, program, priority)
+	return threshold
+}
+
+// StageThreshold is the stage-level threshold used for stage risk tiers and
+// insights, ignoring program and priority overrides.
+func (p SLAPolicy) StageThreshold(stage string) SLAThreshold {
+	return p.Resolve(stage, "", "")
+}
+
+func (p SLAPolicy) resolve(stage string, program string, priority string) (SLAThreshold, int) {
+	best := -1
+	bestScore := 0
+	for i, rule := range p.Rules {
+		score := 0
+		if !ruleFieldMatches(rule.Stage, stage, &score) ||
+			!ruleFieldMatches(rule.Program, program, &score) ||
+			!ruleFieldMatches(rule.Priority, priority, &score) {
+			continue
+		}
+		// A wildcard-only rule scores zero but still applies when nothing
+		// more specific matches; on a tie the earlier rule wins.
+		if best < 0 || score > bestScore {
+			best = i
+			bestScore = score
+		}
+	}
+	threshold := p.Default
+	if threshold.DueSoonRatio <= 0 {
+		threshold.DueSoonRatio = normalizeDueSoonRatio(0)
+	}
+	if best < 0 {
+		return threshold, -1
+	}
+	rule := p.Rules[best]
+	threshold.SLADays = rule.SLADays
+	if rule.DueSoonRatio > 0 {
+		threshold.DueSoonRatio = rule.DueSoonRatio
+	}
+	return threshold, best
+}
+
+// RuleStages lists the stages named by rules, ignoring wildcards.
+func (p SLAPolicy) RuleStages() []string {
+	var stages []string
+	seen := map[string]bool{}
+	for _, rule := range p.Rules {
+		stage := strings.TrimSpace(rule.Stage)
+		key := strings.ToLower(stage)
+		if stage == "" || stage == "*" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		stages = append(stages, stage)
+	}
+	return stages
+}
+
+func ruleFieldMatches(ruleValue string, value string, score *int) bool {
+	ruleValue = strings.TrimSpace(ruleValue)
+	if ruleValue == "" || ruleValue == "*" {
+		return true
+	}
+	if !strings.EqualFold(ruleValue, strings.TrimSpace(value)) {
+		return false
+	}
+	*score = *score + 1
+	return true
+}
+
+// EffectivePolicies echoes the stage-level threshold for every stage seen in the
+// run, with a count of program/priority rules that can override it.
+func (p SLAPolicy) EffectivePolicies(stages []string) []StagePolicy {
+	out := make([]StagePolicy, 0, len(stages))
+	for _, stage := range stages {
+		threshold, rule := p.resolve(stage, "", "")
+		source := "default"
+		if rule >= 0 {
+			source = "stage rule"
+			if ruleStage := strings.TrimSpace(p.Rules[rule].Stage); ruleStage == "" || ruleStage == "*" {
+				source = "wildcard rule"
+			}
+		}
+		overrides := 0
+		for _, candidate := range p.Rules {
+			if strings.TrimSpace(candidate.Program) == "" && strings.TrimSpace(candidate.Priority) == "" {
+				continue
+			}
+			stageValue := strings.TrimSpace(candidate.Stage)
+			if stageValue == "" || stageValue == "*" || strings.EqualFold(stageValue, stage) {
+				overrides++
+			}
+		}
+		out = append(out, StagePolicy{
+			Stage:        stage,
+			SLADays:      threshold.SLADays,
+			DueSoonRatio: threshold.DueSoonRatio,
+			Source:       source,
+			Overrides:    overrides,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Stage < out[j].Stage
+	})
+	return out
+}
+
+func normalizeDueSoonRatio(ratio float64) float64 {
+	if ratio <= 0 || ratio >= 1 {
+		return 0.8
+	}
+	return ratio
+}
+
+// classifyAge buckets a pending item's age against its SLA threshold.
+func classifyAge(age float64, threshold SLAThreshold) string {
+	sla := float64(threshold.SLADays)
+	switch {
+	case age >= sla:
+		return "overdue"
+	case age >= sla*threshold.DueSoonRatio:
+		return "due soon"
+	default:
+		return "on track"
+	}
+}
@@ -0,0 +1,135 @@
+package forecast
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+type QueueItemProjection struct {
+	ApplicationID     string  `json:"application_id"`
+	Stage             string  `json:"stage"`
+	ReviewerID        string  `json:"reviewer_id"`
+	SubmittedAt       string  `json:"submitted_at"`
+	AgeDays           float64 `json:"age_days"`
+	BacklogPosition   int     `json:"backlog_position"`
+	BacklogSize       int     `json:"backlog_size"`
+	DailyThroughput   float64 `json:"daily_throughput"`
+	ThroughputSource  string  `json:"throughput_source"`
+	ProjectedDays     float64 `json:"projected_days"`
+	ProjectedReviewAt string  `json:"projected_review_at"`
+	ProjectedAgeDays  float64 `json:"projected_age_days"`
+	PastSLA           bool    `json:"past_sla"`
+	Status            string  `json:"status"`
+}
+
+// buildQueueItemProjections projects a review date for every pending item. Assigned
+// items queue FIFO behind their reviewer's backlog at that reviewer's historical
+// pace; unassigned items queue behind the stage's unassigned pool at stage pace.
+func buildQueueItemProjections(queueItems []QueueItem, reviewers []ReviewerStats, stageDaily map[string]float64, policy SLAPolicy, asOf time.Time, cal Calendar) []QueueItemProjection {
+	if len(queueItems) == 0 {
+		return nil
+	}
+	reviewerDaily := map[string]float64{}
+	for _, reviewer := range reviewers {
+		reviewerDaily[reviewer.ReviewerID] = reviewer.ThroughputPerWeek / 7.0
+	}
+
+	backlogs := map[string][]QueueItem{}
+	for _, item := range queueItems {
+		backlogs[projectionBacklogKey(item)] = append(backlogs[projectionBacklogKey(item)], item)
+	}
+
+	projections := make([]QueueItemProjection, 0, len(queueItems))
+	for _, backlog := range backlogs {
+		sort.Slice(backlog, func(i, j int) bool {
+			if backlog[i].SubmittedAt.Equal(backlog[j].SubmittedAt) {
+				return backlog[i].ApplicationID < backlog[j].ApplicationID
+			}
+			return backlog[i].SubmittedAt.Before(backlog[j].SubmittedAt)
+		})
+		for position, item := range backlog {
+			reviewerID := strings.TrimSpace(item.ReviewerID)
+			daily := 0.0
+			source := "none"
+			if reviewerID != "" && reviewerDaily[reviewerID] > 0 {
+				daily = reviewerDaily[reviewerID]
+				source = "reviewer"
+			} else if stageDaily[item.Stage] > 0 {
+				daily = stageDaily[item.Stage]
+				source = "stage"
+			}
+			if reviewerID == "" {
+				reviewerID = "unassigned"
+			}
+
+			sla := float64(policy.Resolve(item.Stage, item.Program, item.Priority).SLADays)
+			ageDays := cal.Days(item.SubmittedAt, asOf)
+			if ageDays < 0 {
+				ageDays = 0
+			}
+			projection := QueueItemProjection{
+				ApplicationID:    item.ApplicationID,
+				Stage:            item.Stage,
+				ReviewerID:       reviewerID,
+				SubmittedAt:      item.SubmittedAt.In(cal.location()).Format(time.RFC3339),
+				AgeDays:          Round(ageDays, 2),
+				BacklogPosition:  position + 1,
+				BacklogSize:      len(backlog),
+				DailyThroughput:  Round(daily, 2),
+				ThroughputSource: source,
+				PastSLA:          ageDays >= sla,
+				Status:           "no throughput data",
+			}
+			if daily > 0 {
+				projectedDays := float64(position+1) / daily
+				projectedAt := asOf.Add(time.Duration(projectedDays * 24 * float64(time.Hour)))
+				projectedAge := cal.Days(item.SubmittedAt, projectedAt)
+				projection.ProjectedDays = Round(projectedDays, 2)
+				projection.ProjectedReviewAt = projectedAt.Format(time.RFC3339)
+				projection.ProjectedAgeDays = Round(projectedAge, 2)
+				projection.PastSLA = projectedAge >= sla
+				projection.Status = "within sla"
+			}
+			if projection.PastSLA {
+				projection.Status = "past sla"
+			}
+			projections = append(projections, projection)
+		}
+	}
+
+	sort.Slice(projections, func(i, j int) bool {
+		left, right := projectionSortDays(projections[i]), projectionSortDays(projections[j])
+		if left == right {
+			return projections[i].ApplicationID < projections[j].ApplicationID
+		}
+		return left < right
+	})
+	return projections
+}
+
+func projectionBacklogKey(item QueueItem) string {
+	reviewerID := strings.TrimSpace(item.ReviewerID)
+	if reviewerID == "" {
+		return "unassigned|" + item.Stage
+	}
+	return "reviewer|" + reviewerID
+}
+
+func projectionSortDays(projection QueueItemProjection) float64 {
+	if projection.ProjectedReviewAt == "" {
+		return math.MaxFloat64
+	}
+	return projection.ProjectedDays
+}
+
+func countProjectedPastSLA(projections []QueueItemProjection) int {
+	count := 0
+	for _, projection := range projections {
+		if projection.PastSLA {
+			count++
+		}
+	}
+	return count
+}
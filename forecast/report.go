@@ -0,0 +1,924 @@
+package forecast
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Options configures BuildReport. A zero Policy, Calendar, or ThroughputDays falls
+// back to the values from DefaultOptions.
+type Options struct {
+	Policy          SLAPolicy
+	Calendar        Calendar
+	ThroughputDays  int
+	AsOf            time.Time // zero means the latest reviewed_at in the events
+	TargetClearDays int
+	PriorityTop     int
+	Simulations     int
+	SimulationSeed  int64
+	// Journeys adds the application journey section; StageOrder fixes the
+	// pipeline order (other stages are inferred) and JourneyTop caps the
+	// slowest journeys listed.
+	Journeys   bool
+	StageOrder []string
+	JourneyTop int
+	// Arrivals forecasts new submissions from submitted_at history and adds
+	// them to the backlog in clear-day estimates and simulations.
+	Arrivals bool
+}
+
+// DefaultOptions mirrors the CLI defaults: a 10-day SLA with a 0.8 due-soon ratio,
+// calendar days in UTC, a 28-day throughput window, a 14-day clearance target,
+// and the top 10 priority items. Simulations are off.
+func DefaultOptions() Options {
+	return Options{
+		Policy:          NewSLAPolicy(10, 0.8),
+		Calendar:        DefaultCalendar(),
+		ThroughputDays:  28,
+		TargetClearDays: 14,
+		PriorityTop:     10,
+		SimulationSeed:  1,
+	}
+}
+
+func (opts Options) withDefaults() Options {
+	defaults := DefaultOptions()
+	if opts.Policy.Default.SLADays <= 0 {
+		opts.Policy.Default.SLADays = defaults.Policy.Default.SLADays
+	}
+	if opts.Policy.Default.DueSoonRatio <= 0 {
+		opts.Policy.Default.DueSoonRatio = defaults.Policy.Default.DueSoonRatio
+	}
+	if opts.Calendar.Mode == "" && opts.Calendar.Location == nil {
+		opts.Calendar = defaults.Calendar
+	}
+	if opts.ThroughputDays <= 0 {
+		opts.ThroughputDays = defaults.ThroughputDays
+	}
+	return opts
+}
+
+// BuildReport computes stage, reviewer, trend, queue, and insight metrics for a set
+// of completed review events and an optional pending queue. Use an Aggregator to
+// stream events instead of holding them in a slice.
+func BuildReport(ctx context.Context, events []ReviewEvent, queueItems []QueueItem, opts Options) (Report, error) {
+	if err := ctx.Err(); err != nil {
+		return Report{}, err
+	}
+	aggregator := NewAggregator(opts)
+	for _, event := range events {
+		aggregator.Add(event)
+	}
+	return aggregator.Report(ctx, queueItems)
+}
+
+func buildThroughputTrends(events []windowEvent, asOf time.Time, throughputDays int) ThroughputTrendSummary {
+	if throughputDays <= 0 {
+		return ThroughputTrendSummary{}
+	}
+	currentStart := asOf.AddDate(0, 0, -throughputDays)
+	currentEnd := asOf
+	priorEnd := currentStart
+	priorStart := priorEnd.AddDate(0, 0, -throughputDays)
+
+	stageCurrent := map[string]int{}
+	stagePrior := map[string]int{}
+	currentTotal := 0
+	priorTotal := 0
+
+	for _, event := range events {
+		switch {
+		case inWindow(event.reviewedAt, currentStart, currentEnd, true):
+			stageCurrent[event.stage]++
+			currentTotal++
+		case inWindow(event.reviewedAt, priorStart, priorEnd, false):
+			stagePrior[event.stage]++
+			priorTotal++
+		}
+	}
+
+	trends := []ThroughputTrend{BuildTrend("overall", currentTotal, priorTotal, throughputDays)}
+
+	stages := map[string]struct{}{}
+	for stage := range stageCurrent {
+		stages[stage] = struct{}{}
+	}
+	for stage := range stagePrior {
+		stages[stage] = struct{}{}
+	}
+
+	stageTrends := make([]ThroughputTrend, 0, len(stages))
+	for stage := range stages {
+		stageTrends = append(stageTrends, BuildTrend(stage, stageCurrent[stage], stagePrior[stage], throughputDays))
+	}
+
+	sort.Slice(stageTrends, func(i, j int) bool {
+		if stageTrends[i].CurrentCount == stageTrends[j].CurrentCount {
+			return stageTrends[i].Delta > stageTrends[j].Delta
+		}
+		return stageTrends[i].CurrentCount > stageTrends[j].CurrentCount
+	})
+
+	trends = append(trends, stageTrends...)
+
+	return ThroughputTrendSummary{
+		CurrentWindowStart: currentStart.Format(time.RFC3339),
+		CurrentWindowEnd:   currentEnd.Format(time.RFC3339),
+		PriorWindowStart:   priorStart.Format(time.RFC3339),
+		PriorWindowEnd:     priorEnd.Format(time.RFC3339),
+		WindowDays:         throughputDays,
+		Trends:             trends,
+	}
+}
+
+func buildLatencyTrends(events []windowEvent, asOf time.Time, windowDays int) LatencyTrendSummary {
+	if windowDays <= 0 {
+		return LatencyTrendSummary{}
+	}
+	currentStart := asOf.AddDate(0, 0, -windowDays)
+	currentEnd := asOf
+	priorEnd := currentStart
+	priorStart := priorEnd.AddDate(0, 0, -windowDays)
+
+	current := map[string]*latencyAccumulator{"overall": {}}
+	prior := map[string]*latencyAccumulator{"overall": {}}
+	add := func(windows map[string]*latencyAccumulator, stage string, days float64) {
+		for _, label := range []string{"overall", stage} {
+			acc, ok := windows[label]
+			if !ok {
+				acc = &latencyAccumulator{}
+				windows[label] = acc
+			}
+			acc.addDuration(days)
+		}
+	}
+	for _, event := range events {
+		switch {
+		case inWindow(event.reviewedAt, currentStart, currentEnd, true):
+			add(current, event.stage, event.days)
+		case inWindow(event.reviewedAt, priorStart, priorEnd, false):
+			add(prior, event.stage, event.days)
+		}
+	}
+
+	labels := map[string]struct{}{}
+	for stage := range current {
+		labels[stage] = struct{}{}
+	}
+	for stage := range prior {
+		labels[stage] = struct{}{}
+	}
+
+	trends := make([]LatencyTrend, 0, len(labels))
+	for stage := range labels {
+		currentAcc, priorAcc := current[stage], prior[stage]
+		if currentAcc == nil {
+			currentAcc = &latencyAccumulator{}
+		}
+		if priorAcc == nil {
+			priorAcc = &latencyAccumulator{}
+		}
+		trends = append(trends, buildLatencyTrend(stage, currentAcc, priorAcc))
+	}
+
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].Label == "overall" {
+			return true
+		}
+		if trends[j].Label == "overall" {
+			return false
+		}
+		if trends[i].CurrentAvgDays == trends[j].CurrentAvgDays {
+			return trends[i].AvgDeltaDays > trends[j].AvgDeltaDays
+		}
+		return trends[i].CurrentAvgDays > trends[j].CurrentAvgDays
+	})
+
+	return LatencyTrendSummary{
+		CurrentWindowStart: currentStart.Format(time.RFC3339),
+		CurrentWindowEnd:   currentEnd.Format(time.RFC3339),
+		PriorWindowStart:   priorStart.Format(time.RFC3339),
+		PriorWindowEnd:     priorEnd.Format(time.RFC3339),
+		WindowDays:         windowDays,
+		Trends:             trends,
+	}
+}
+
+func buildLatencyTrend(label string, current *latencyAccumulator, prior *latencyAccumulator) LatencyTrend {
+	currentCount := current.count
+	priorCount := prior.count
+
+	currentAvg := current.average()
+	priorAvg := prior.average()
+	currentMedian := current.sketch.quantile(50)
+	priorMedian := prior.sketch.quantile(50)
+
+	avgDelta := currentAvg - priorAvg
+	medianDelta := currentMedian - priorMedian
+
+	avgDeltaPct := 0.0
+	if priorAvg > 0 {
+		avgDeltaPct = (avgDelta / priorAvg) * 100
+	}
+	medianDeltaPct := 0.0
+	if priorMedian > 0 {
+		medianDeltaPct = (medianDelta / priorMedian) * 100
+	}
+
+	trend := "flat"
+	switch {
+	case avgDelta > 0.5:
+		trend = "up"
+	case avgDelta < -0.5:
+		trend = "down"
+	}
+
+	return LatencyTrend{
+		Label:             label,
+		CurrentCount:      currentCount,
+		PriorCount:        priorCount,
+		CurrentAvgDays:    Round(currentAvg, 2),
+		PriorAvgDays:      Round(priorAvg, 2),
+		AvgDeltaDays:      Round(avgDelta, 2),
+		AvgDeltaPercent:   Round(avgDeltaPct, 1),
+		CurrentMedianDays: Round(currentMedian, 2),
+		PriorMedianDays:   Round(priorMedian, 2),
+		MedianDeltaDays:   Round(medianDelta, 2),
+		MedianDeltaPct:    Round(medianDeltaPct, 1),
+		Trend:             trend,
+	}
+}
+
+// BuildQueueReport forecasts the pending queue as of asOf, using the completed events
+// and reviewer stats for throughput. It returns nil when the queue is empty.
+func BuildQueueReport(ctx context.Context, queueItems []QueueItem, events []ReviewEvent, reviewerStats []ReviewerStats, asOf time.Time, opts Options) (*QueueReport, error) {
+	if len(queueItems) == 0 {
+		return nil, nil
+	}
+	opts = opts.withDefaults()
+	asOf = asOf.In(opts.Calendar.location())
+	window := newThroughputWindow(asOf, opts.ThroughputDays)
+	for _, event := range events {
+		reviewerID := strings.TrimSpace(event.ReviewerID)
+		if reviewerID == "" {
+			reviewerID = "unassigned"
+		}
+		window.add(event.Stage, reviewerID, event.ReviewedAt)
+	}
+	var arrivals *arrivalHistory
+	if opts.Arrivals {
+		arrivals = newArrivalHistory(opts.Calendar.location())
+		for _, event := range events {
+			arrivals.add(event.Stage, event.SubmittedAt)
+		}
+		arrivals = arrivals.withQueue(queueItems)
+	}
+	return buildQueueReport(ctx, queueItems, window, arrivals, reviewerStats, asOf, opts)
+}
+
+// buildQueueReport forecasts the queue from the throughput window. With an
+// arrival history (which must already count queueItems) clear days, capacity
+// targets, and simulations also absorb forecast submissions.
+func buildQueueReport(ctx context.Context, queueItems []QueueItem, window *throughputWindow, arrivals *arrivalHistory, reviewerStats []ReviewerStats, asOf time.Time, opts Options) (*QueueReport, error) {
+	if len(queueItems) == 0 {
+		return nil, nil
+	}
+	policy, cal, throughputDays := opts.Policy, opts.Calendar, opts.ThroughputDays
+	targetClearDays, queuePriorityTop := opts.TargetClearDays, opts.PriorityTop
+	simulations, simulationSeed := opts.Simulations, opts.SimulationSeed
+	if targetClearDays < 0 {
+		targetClearDays = 0
+	}
+	if queuePriorityTop < 0 {
+		queuePriorityTop = 0
+	}
+
+	stageBuckets := map[string][]QueueItem{}
+	reviewerBuckets := map[string][]QueueItem{}
+	totalPending := len(queueItems)
+	var totalAge float64
+	overdue := 0
+	dueSoon := 0
+	onTrack := 0
+	assignedCount := 0
+	unassignedCount := 0
+
+	for _, item := range queueItems {
+		stageBuckets[item.Stage] = append(stageBuckets[item.Stage], item)
+		reviewerID := strings.TrimSpace(item.ReviewerID)
+		if reviewerID == "" {
+			reviewerID = "unassigned"
+			unassignedCount++
+		} else {
+			assignedCount++
+		}
+		reviewerBuckets[reviewerID] = append(reviewerBuckets[reviewerID], item)
+		age := cal.Days(item.SubmittedAt, asOf)
+		if age < 0 {
+			age = 0
+		}
+		totalAge += age
+		switch classifyAge(age, policy.Resolve(item.Stage, item.Program, item.Priority)) {
+		case "overdue":
+			overdue++
+		case "due soon":
+			dueSoon++
+		default:
+			onTrack++
+		}
+	}
+
+	stages := make([]QueueStageForecast, 0, len(stageBuckets))
+	stageDaily := map[string]float64{}
+	for stage, items := range stageBuckets {
+		pending := len(items)
+		var ageSum float64
+		stageOverdue := 0
+		stageDueSoon := 0
+		stageOnTrack := 0
+		for _, item := range items {
+			age := cal.Days(item.SubmittedAt, asOf)
+			if age < 0 {
+				age = 0
+			}
+			ageSum += age
+			switch classifyAge(age, policy.Resolve(item.Stage, item.Program, item.Priority)) {
+			case "overdue":
+				stageOverdue++
+			case "due soon":
+				stageDueSoon++
+			default:
+				stageOnTrack++
+			}
+		}
+
+		dailyThroughput := 0.0
+		if throughputDays > 0 {
+			dailyThroughput = float64(window.stages[stage]) / float64(throughputDays)
+		}
+		stageDaily[stage] = dailyThroughput
+		model := arrivals.forecast(stage, asOf, throughputDays, dailyThroughput)
+
+		estimatedClear := 0.0
+		clearanceStatus := "no throughput data"
+		if dailyThroughput > 0 {
+			estimatedClear, clearanceStatus = estimateClearance(pending, dailyThroughput, model)
+		}
+
+		avgAge := 0.0
+		if pending > 0 {
+			avgAge = ageSum / float64(pending)
+		}
+
+		requiredDaily := 0.0
+		requiredWeekly := 0.0
+		capacityStatus := "no target set"
+		if targetClearDays > 0 {
+			requiredDaily = (float64(pending) + model.expected(targetClearDays)) / float64(targetClearDays)
+			requiredWeekly = requiredDaily * 7.0
+			capacityStatus = classifyCapacity(dailyThroughput, requiredDaily)
+		}
+
+		simulation, err := simulateClearance(ctx, stage, pending, window.dailySamples(stage), model.days(), simulations, simulationSeed, targetClearDays, asOf)
+		if err != nil {
+			return nil, err
+		}
+		stageThreshold := policy.StageThreshold(stage)
+		stages = append(stages, QueueStageForecast{
+			Stage:                    stage,
+			SLADays:                  stageThreshold.SLADays,
+			DueSoonRatio:             stageThreshold.DueSoonRatio,
+			PendingCount:             pending,
+			AvgAgeDays:               Round(avgAge, 2),
+			OverdueCount:             stageOverdue,
+			DueSoonCount:             stageDueSoon,
+			OnTrackCount:             stageOnTrack,
+			DailyThroughput:          Round(dailyThroughput, 2),
+			EstimatedClearDays:       Round(estimatedClear, 2),
+			ClearanceStatus:          clearanceStatus,
+			RequiredDailyThroughput:  Round(requiredDaily, 2),
+			RequiredWeeklyThroughput: Round(requiredWeekly, 2),
+			ThroughputGapDaily:       Round(requiredDaily-dailyThroughput, 2),
+			ThroughputGapWeekly:      Round(requiredWeekly-(dailyThroughput*7.0), 2),
+			CapacityStatus:           capacityStatus,
+			Simulation:               simulation,
+			Arrivals:                 model.forecast(),
+		})
+	}
+
+	sort.Slice(stages, func(i, j int) bool {
+		if stages[i].PendingCount == stages[j].PendingCount {
+			return stages[i].AvgAgeDays > stages[j].AvgAgeDays
+		}
+		return stages[i].PendingCount > stages[j].PendingCount
+	})
+
+	avgAge := 0.0
+	if totalPending > 0 {
+		avgAge = totalAge / float64(totalPending)
+	}
+
+	reviewers := buildQueueReviewerForecasts(reviewerBuckets, window.reviewers, policy, throughputDays, asOf, cal)
+	priorityItems := buildQueuePriorityItems(queueItems, policy, asOf, queuePriorityTop, cal)
+	projections := buildQueueItemProjections(queueItems, reviewerStats, stageDaily, policy, asOf, cal)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	overallModel := arrivals.forecast("", asOf, throughputDays, float64(window.total)/float64(throughputDays))
+	clearancePlan := buildClearancePlan(totalPending, window.total, throughputDays, targetClearDays, overallModel.expected(targetClearDays))
+	simulation, err := simulateClearance(ctx, "overall", totalPending, window.dailySamples(""), overallModel.days(), simulations, simulationSeed, targetClearDays, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QueueReport{
+		AsOf:             asOf.Format(time.RFC3339),
+		TotalPending:     totalPending,
+		AssignedCount:    assignedCount,
+		UnassignedCount:  unassignedCount,
+		OverdueCount:     overdue,
+		DueSoonCount:     dueSoon,
+		OnTrackCount:     onTrack,
+		AvgAgeDays:       Round(avgAge, 2),
+		Stages:           stages,
+		Reviewers:        reviewers,
+		PriorityItems:    priorityItems,
+		ItemProjections:  projections,
+		ProjectedPastSLA: countProjectedPastSLA(projections),
+		ThroughputDays:   throughputDays,
+		DueSoonRatio:     policy.Default.DueSoonRatio,
+		ClearancePlan:    clearancePlan,
+		Simulation:       simulation,
+		Arrivals:         overallModel.forecast(),
+	}, nil
+}
+
+func buildQueueReviewerForecasts(reviewerBuckets map[string][]QueueItem, reviewerThroughput map[string]int, policy SLAPolicy, throughputDays int, asOf time.Time, cal Calendar) []QueueReviewerForecast {
+	if len(reviewerBuckets) == 0 {
+		return nil
+	}
+	reviewers := make([]QueueReviewerForecast, 0, len(reviewerBuckets))
+	for reviewerID, items := range reviewerBuckets {
+		pending := len(items)
+		var ageSum float64
+		overdue := 0
+		dueSoon := 0
+		onTrack := 0
+		for _, item := range items {
+			age := cal.Days(item.SubmittedAt, asOf)
+			if age < 0 {
+				age = 0
+			}
+			ageSum += age
+			switch classifyAge(age, policy.Resolve(item.Stage, item.Program, item.Priority)) {
+			case "overdue":
+				overdue++
+			case "due soon":
+				dueSoon++
+			default:
+				onTrack++
+			}
+		}
+
+		avgAge := 0.0
+		if pending > 0 {
+			avgAge = ageSum / float64(pending)
+		}
+
+		throughputPerWeek := 0.0
+		if throughputDays > 0 {
+			throughputPerWeek = float64(reviewerThroughput[reviewerID]) / (float64(throughputDays) / 7.0)
+		}
+
+		estimatedClear := 0.0
+		clearanceStatus := "no throughput data"
+		if throughputPerWeek > 0 {
+			dailyThroughput := throughputPerWeek / 7.0
+			estimatedClear = float64(pending) / dailyThroughput
+			switch {
+			case estimatedClear <= 7:
+				clearanceStatus = "healthy"
+			case estimatedClear <= 14:
+				clearanceStatus = "watch"
+			default:
+				clearanceStatus = "at risk"
+			}
+		}
+
+		reviewers = append(reviewers, QueueReviewerForecast{
+			ReviewerID:         reviewerID,
+			PendingCount:       pending,
+			AvgAgeDays:         Round(avgAge, 2),
+			OverdueCount:       overdue,
+			DueSoonCount:       dueSoon,
+			OnTrackCount:       onTrack,
+			ThroughputPerWeek:  Round(throughputPerWeek, 2),
+			EstimatedClearDays: Round(estimatedClear, 2),
+			ClearanceStatus:    clearanceStatus,
+		})
+	}
+
+	sort.Slice(reviewers, func(i, j int) bool {
+		if reviewers[i].PendingCount == reviewers[j].PendingCount {
+			return reviewers[i].AvgAgeDays > reviewers[j].AvgAgeDays
+		}
+		return reviewers[i].PendingCount > reviewers[j].PendingCount
+	})
+	return reviewers
+}
+
+func buildQueuePriorityItems(queueItems []QueueItem, policy SLAPolicy, asOf time.Time, top int, cal Calendar) []QueuePriorityItem {
+	if len(queueItems) == 0 {
+		return nil
+	}
+	if top <= 0 {
+		top = 10
+	}
+	items := make([]QueuePriorityItem, 0, len(queueItems))
+	for _, item := range queueItems {
+		threshold := policy.Resolve(item.Stage, item.Program, item.Priority)
+		sla := float64(threshold.SLADays)
+		dueSoonThreshold := sla * threshold.DueSoonRatio
+		ageDays := cal.Days(item.SubmittedAt, asOf)
+		if ageDays < 0 {
+			ageDays = 0
+		}
+		daysToSLA := sla - ageDays
+		score := 0.0
+		status := "on track"
+		switch {
+		case ageDays >= sla:
+			score = 2.0 + (ageDays-sla)/sla
+			status = "overdue"
+		case ageDays >= dueSoonThreshold:
+			score = 1.0 + (ageDays / sla)
+			status = "due soon"
+		default:
+			score = ageDays / sla
+		}
+		reviewerID := strings.TrimSpace(item.ReviewerID)
+		if reviewerID == "" {
+			reviewerID = "unassigned"
+			score += 0.3
+		}
+		items = append(items, QueuePriorityItem{
+			ApplicationID: item.ApplicationID,
+			Stage:         item.Stage,
+			ReviewerID:    reviewerID,
+			SubmittedAt:   item.SubmittedAt.In(cal.location()).Format(time.RFC3339),
+			AgeDays:       Round(ageDays, 2),
+			DaysToSLA:     Round(daysToSLA, 2),
+			UrgencyScore:  Round(score, 2),
+			Status:        status,
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].UrgencyScore == items[j].UrgencyScore {
+			return items[i].AgeDays > items[j].AgeDays
+		}
+		return items[i].UrgencyScore > items[j].UrgencyScore
+	})
+
+	if len(items) > top {
+		items = items[:top]
+	}
+	return items
+}
+
+func sortReviewerStats(stats []ReviewerStats) {
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].ThroughputPerWeek == stats[j].ThroughputPerWeek {
+			if stats[i].AverageDays == stats[j].AverageDays {
+				return stats[i].Count > stats[j].Count
+			}
+			return stats[i].AverageDays > stats[j].AverageDays
+		}
+		return stats[i].ThroughputPerWeek > stats[j].ThroughputPerWeek
+	})
+}
+
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	if len(values) == 1 {
+		return values[0]
+	}
+	if p <= 0 {
+		return values[0]
+	}
+	if p >= 100 {
+		return values[len(values)-1]
+	}
+
+	rank := (p / 100) * float64(len(values)-1)
+	lower := int(rank)
+	upper := lower + 1
+	if upper >= len(values) {
+		return values[lower]
+	}
+	weight := rank - float64(lower)
+	return values[lower] + (values[upper]-values[lower])*weight
+}
+
+// Round rounds half up to the given number of decimal places.
+func Round(value float64, places int) float64 {
+	factor := mathPow10(places)
+	return math.Floor(value*factor+0.5) / factor
+}
+
+// Percent returns part as a percentage of total, rounded to one decimal.
+func Percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return Round(float64(part)/float64(total)*100, 1)
+}
+
+func inWindow(value time.Time, start time.Time, end time.Time, includeEnd bool) bool {
+	if value.Before(start) {
+		return false
+	}
+	if includeEnd {
+		return !value.After(end)
+	}
+	return value.Before(end)
+}
+
+// BuildTrend compares event counts between the current and prior windows.
+func BuildTrend(label string, current int, prior int, windowDays int) ThroughputTrend {
+	delta := current - prior
+	deltaPercent := 0.0
+	if prior > 0 {
+		deltaPercent = (float64(delta) / float64(prior)) * 100
+	}
+	currentPerWeek := 0.0
+	priorPerWeek := 0.0
+	if windowDays > 0 {
+		currentPerWeek = float64(current) / (float64(windowDays) / 7.0)
+		priorPerWeek = float64(prior) / (float64(windowDays) / 7.0)
+	}
+	trend := "flat"
+	switch {
+	case delta > 0:
+		trend = "up"
+	case delta < 0:
+		trend = "down"
+	}
+	return ThroughputTrend{
+		Label:          label,
+		CurrentCount:   current,
+		PriorCount:     prior,
+		Delta:          delta,
+		DeltaPercent:   Round(deltaPercent, 1),
+		CurrentPerWeek: Round(currentPerWeek, 2),
+		PriorPerWeek:   Round(priorPerWeek, 2),
+		Trend:          trend,
+	}
+}
+
+func mathPow10(places int) float64 {
+	if places <= 0 {
+		return 1
+	}
+	result := 1.0
+	for i := 0; i < places; i++ {
+		result *= 10
+	}
+	return result
+}
+
+// SelectStageRisks returns up to max stages that are not comfortably low risk.
+func SelectStageRisks(stages []StageStats, slaDays int, max int) []StageStats {
+	if len(stages) == 0 {
+		return nil
+	}
+	if max <= 0 {
+		max = 3
+	}
+	var out []StageStats
+	for _, stage := range stages {
+		if stage.Count == 0 {
+			continue
+		}
+		if stage.RiskTier == "low" && stage.SLABreachRate < 20 && stage.AverageDays < float64(stageSLADays(stage, slaDays)) {
+			continue
+		}
+		out = append(out, stage)
+		if len(out) >= max {
+			break
+		}
+	}
+	return out
+}
+
+// addLatencyBuckets files a completed review into the aging buckets for its SLA
+// threshold and reports whether it breached the SLA.
+func addLatencyBuckets(buckets *AgingBuckets, days float64, threshold SLAThreshold) bool {
+	sla := float64(threshold.SLADays)
+	switch {
+	case days <= sla:
+		buckets.OnTime++
+	case days <= sla*2:
+		buckets.AtRisk++
+	default:
+		buckets.Overdue++
+	}
+	return days >= sla
+}
+
+func stageSLADays(stage StageStats, fallback int) int {
+	if stage.SLADays > 0 {
+		return stage.SLADays
+	}
+	return fallback
+}
+
+func collectStages(eventStages []string, queueItems []QueueItem) []string {
+	seen := map[string]struct{}{}
+	for _, stage := range eventStages {
+		seen[stage] = struct{}{}
+	}
+	for _, item := range queueItems {
+		seen[item.Stage] = struct{}{}
+	}
+	stages := make([]string, 0, len(seen))
+	for stage := range seen {
+		stages = append(stages, stage)
+	}
+	sort.Strings(stages)
+	return stages
+}
+
+func classifyRisk(avgDays float64, breachRate float64, slaDays int) string {
+	sla := float64(slaDays)
+	switch {
+	case breachRate >= 0.4 || avgDays >= sla:
+		return "high"
+	case breachRate >= 0.2 || avgDays >= sla*0.8:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+func classifyCapacity(currentDaily float64, requiredDaily float64) string {
+	if requiredDaily <= 0 {
+		return "no target set"
+	}
+	if currentDaily >= requiredDaily {
+		return "on track"
+	}
+	if currentDaily >= requiredDaily*0.75 {
+		return "needs support"
+	}
+	return "critical"
+}
+
+// buildClearancePlan compares the current pace with the pace needed to clear
+// the backlog, plus expectedArrivals within the target, in targetClearDays.
+func buildClearancePlan(totalPending int, windowCount int, throughputDays int, targetClearDays int, expectedArrivals float64) *QueueClearancePlan {
+	if targetClearDays <= 0 {
+		return nil
+	}
+	currentDaily := 0.0
+	if throughputDays > 0 {
+		currentDaily = float64(windowCount) / float64(throughputDays)
+	}
+	currentWeekly := currentDaily * 7.0
+	requiredDaily := (float64(totalPending) + expectedArrivals) / float64(targetClearDays)
+	requiredWeekly := requiredDaily * 7.0
+	gapDaily := requiredDaily - currentDaily
+	gapWeekly := requiredWeekly - currentWeekly
+	status := classifyCapacity(currentDaily, requiredDaily)
+
+	return &QueueClearancePlan{
+		TargetDays:       targetClearDays,
+		RequiredDaily:    Round(requiredDaily, 2),
+		RequiredWeekly:   Round(requiredWeekly, 2),
+		CurrentDaily:     Round(currentDaily, 2),
+		CurrentWeekly:    Round(currentWeekly, 2),
+		GapDaily:         Round(gapDaily, 2),
+		GapWeekly:        Round(gapWeekly, 2),
+		Status:           status,
+		ExpectedArrivals: Round(expectedArrivals, 2),
+	}
+}
+
+// BuildInsights flags SLA, throughput, latency, and queue risks worth raising in
+// a weekly review.
+func BuildInsights(overall StageStats, stages []StageStats, throughputTrend ThroughputTrendSummary, latencyTrend LatencyTrendSummary, queue *QueueReport, slaDays int) []Insight {
+	insights := make([]Insight, 0, 8)
+	add := func(severity, area, message, metric string) {
+		if len(insights) >= 8 {
+			return
+		}
+		insights = append(insights, Insight{
+			Severity: severity,
+			Area:     area,
+			Message:  message,
+			Metric:   metric,
+		})
+	}
+
+	if overall.Count > 0 {
+		metric := fmt.Sprintf("breach %.1f%% | avg %.2f days", overall.SLABreachRate, overall.AverageDays)
+		if overall.RiskTier == "high" || overall.SLABreachRate >= 30 {
+			add("high", "overall", "SLA risk is elevated across the full review queue.", metric)
+		} else if overall.RiskTier == "medium" || overall.SLABreachRate >= 20 {
+			add("medium", "overall", "SLA risk is trending up across the full review queue.", metric)
+		}
+	}
+
+	stageCount := 0
+	for _, stage := range stages {
+		if stage.Count == 0 {
+			continue
+		}
+		stageSLA := float64(stageSLADays(stage, slaDays))
+		if stage.RiskTier == "low" && stage.AverageDays < stageSLA && stage.SLABreachRate < 20 {
+			continue
+		}
+		severity := "medium"
+		if stage.RiskTier == "high" || stage.SLABreachRate >= 35 || stage.AverageDays >= stageSLA*1.2 {
+			severity = "high"
+		}
+		metric := fmt.Sprintf("breach %.1f%% | avg %.2f days", stage.SLABreachRate, stage.AverageDays)
+		add(severity, "stage", fmt.Sprintf("Stage %s is driving delay risk.", stage.Stage), metric)
+		stageCount++
+		if stageCount >= 3 {
+			break
+		}
+	}
+
+	for _, trend := range throughputTrend.Trends {
+		if trend.Label != "overall" {
+			continue
+		}
+		if trend.DeltaPercent <= -20 || trend.Trend == "down" {
+			metric := fmt.Sprintf("delta %+d (%.1f%%) | current %.2f/week", trend.Delta, trend.DeltaPercent, trend.CurrentPerWeek)
+			severity := "medium"
+			if trend.DeltaPercent <= -30 {
+				severity = "high"
+			}
+			add(severity, "throughput", "Throughput is slowing versus the prior window.", metric)
+		}
+		break
+	}
+
+	for _, trend := range latencyTrend.Trends {
+		if trend.Label != "overall" {
+			continue
+		}
+		if trend.AvgDeltaDays >= 1.0 || trend.Trend == "up" {
+			metric := fmt.Sprintf("avg %+0.2f days | median %+0.2f days", trend.AvgDeltaDays, trend.MedianDeltaDays)
+			severity := "medium"
+			if trend.AvgDeltaDays >= 2.0 {
+				severity = "high"
+			}
+			add(severity, "latency", "Latency is worsening compared with the prior window.", metric)
+		}
+		break
+	}
+
+	if queue != nil {
+		if queue.OverdueCount > 0 {
+			metric := fmt.Sprintf("overdue %d | due soon %d", queue.OverdueCount, queue.DueSoonCount)
+			add("high", "queue", "There are overdue items in the active queue.", metric)
+		}
+		if queue.ClearancePlan != nil && queue.ClearancePlan.Status == "critical" {
+			metric := fmt.Sprintf("gap %.2f/day | target %d days", queue.ClearancePlan.GapDaily, queue.ClearancePlan.TargetDays)
+			add("high", "capacity", "Current throughput is below the clearance target.", metric)
+		}
+		if queue.Arrivals != nil && queue.Arrivals.NetDaily > 0 {
+			metric := fmt.Sprintf("arrivals %.2f/day | net +%.2f/day", queue.Arrivals.ForecastDaily, queue.Arrivals.NetDaily)
+			add("medium", "queue", "Forecast arrivals outpace review throughput, so the backlog keeps growing.", metric)
+		}
+		if queue.TotalPending > 0 {
+			unassignedRatio := float64(queue.UnassignedCount) / float64(queue.TotalPending)
+			if unassignedRatio >= 0.4 {
+				metric := fmt.Sprintf("unassigned %.1f%% of queue", unassignedRatio*100)
+				add("medium", "coverage", "Large share of the queue is unassigned.", metric)
+			}
+		}
+	}
+
+	return insights
+}
+
+// SanitizePath reduces a path to its base name so stored reports do not leak
+// local directory layouts.
+func SanitizePath(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+	return filepath.Base(value)
+}
@@ -0,0 +1,120 @@
+package forecast
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+const simulationHorizonDays = 365
+
+type ClearanceSimulation struct {
+	Trials                int     `json:"trials"`
+	HorizonDays           int     `json:"horizon_days"`
+	SampleDays            int     `json:"sample_days"`
+	P50Days               float64 `json:"p50_days"`
+	P80Days               float64 `json:"p80_days"`
+	P95Days               float64 `json:"p95_days"`
+	P50Date               string  `json:"p50_date"`
+	P80Date               string  `json:"p80_date"`
+	P95Date               string  `json:"p95_date"`
+	TargetDays            int     `json:"target_days"`
+	ProbClearWithinTarget float64 `json:"prob_clear_within_target"`
+	UnclearedTrials       int     `json:"uncleared_trials"`
+	Status                string  `json:"status"`
+}
+
+// simulateClearance resamples historical daily throughput to estimate how many
+// days the pending backlog needs to clear. When arrivals is set, each simulated
+// day first adds a Poisson draw around that day's forecast submissions. It
+// checks ctx between trials, so a cancelled request stops a long simulation.
+func simulateClearance(ctx context.Context, label string, pending int, samples []int, arrivals []float64, trials int, seed int64, targetDays int, asOf time.Time) (*ClearanceSimulation, error) {
+	if trials <= 0 || pending <= 0 {
+		return nil, nil
+	}
+	result := &ClearanceSimulation{
+		Trials:      trials,
+		HorizonDays: simulationHorizonDays,
+		SampleDays:  len(samples),
+		TargetDays:  targetDays,
+		Status:      "no throughput data",
+	}
+	total := 0
+	for _, count := range samples {
+		total += count
+	}
+	if total == 0 {
+		return result, nil
+	}
+
+	rng := rand.New(rand.NewSource(seed ^ labelSeed(label)))
+	clearDays := make([]float64, 0, trials)
+	withinTarget := 0
+	uncleared := 0
+	for trial := 0; trial < trials; trial++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		remaining := pending
+		days := 0
+		for remaining > 0 && days < simulationHorizonDays {
+			if arrivals != nil {
+				remaining += poisson(rng, arrivals[days])
+			}
+			remaining -= samples[rng.Intn(len(samples))]
+			days++
+		}
+		if remaining > 0 {
+			uncleared++
+		}
+		if remaining <= 0 && targetDays > 0 && days <= targetDays {
+			withinTarget++
+		}
+		clearDays = append(clearDays, float64(days))
+	}
+	sort.Float64s(clearDays)
+
+	result.P50Days = Round(percentile(clearDays, 50), 2)
+	result.P80Days = Round(percentile(clearDays, 80), 2)
+	result.P95Days = Round(percentile(clearDays, 95), 2)
+	result.P50Date = simulationDate(asOf, result.P50Days)
+	result.P80Date = simulationDate(asOf, result.P80Days)
+	result.P95Date = simulationDate(asOf, result.P95Days)
+	result.UnclearedTrials = uncleared
+	if targetDays > 0 {
+		result.ProbClearWithinTarget = Round(float64(withinTarget)/float64(trials)*100, 1)
+	}
+	result.Status = classifySimulation(result.P80Days, targetDays)
+	return result, nil
+}
+
+func simulationDate(asOf time.Time, days float64) string {
+	return asOf.AddDate(0, 0, int(math.Ceil(days))).Format("2006-01-02")
+}
+
+func classifySimulation(p80Days float64, targetDays int) string {
+	if p80Days >= simulationHorizonDays {
+		return "beyond horizon"
+	}
+	if targetDays <= 0 {
+		return "simulated"
+	}
+	switch {
+	case p80Days <= float64(targetDays):
+		return "on track"
+	case p80Days <= float64(targetDays)*1.5:
+		return "watch"
+	default:
+		return "at risk"
+	}
+}
+
+// labelSeed keeps each stage's random stream stable regardless of map order.
+func labelSeed(label string) int64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(label))
+	return int64(hash.Sum64())
+}
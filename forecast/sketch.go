@@ -0,0 +1,153 @@
+package forecast
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// sketchExactLimit is how many values a sketch keeps verbatim. Small stages and
+	// reviewers therefore report exact medians and P90s.
+	sketchExactLimit = 4096
+	// sketchRelativeError bounds the error of quantiles read from buckets.
+	sketchRelativeError = 0.005
+)
+
+var sketchLogGamma = math.Log((1 + sketchRelativeError) / (1 - sketchRelativeError))
+
+// quantileSketch estimates quantiles of non-negative durations in fixed memory.
+// It keeps values exactly until sketchExactLimit, then folds them into
+// logarithmic buckets whose width bounds the relative error of any quantile to
+// sketchRelativeError. Values at or below zero share a single bucket.
+type quantileSketch struct {
+	exact   []float64
+	sorted  bool
+	buckets map[int]int
+	zeros   int
+	count   int
+	min     float64
+	max     float64
+}
+
+func (s *quantileSketch) add(value float64) {
+	if s.count == 0 || value < s.min {
+		s.min = value
+	}
+	if s.count == 0 || value > s.max {
+		s.max = value
+	}
+	s.count++
+	if s.buckets == nil {
+		s.exact = append(s.exact, value)
+		s.sorted = false
+		if len(s.exact) > sketchExactLimit {
+			s.buckets = map[int]int{}
+			for _, exact := range s.exact {
+				s.addBucket(exact)
+			}
+			s.exact = nil
+		}
+		return
+	}
+	s.addBucket(value)
+}
+
+func (s *quantileSketch) addBucket(value float64) {
+	if value <= 0 {
+		s.zeros++
+		return
+	}
+	s.buckets[int(math.Ceil(math.Log(value)/sketchLogGamma))]++
+}
+
+// quantile returns the p-th percentile (0-100), interpolating between the two
+// nearest ranks the same way percentile does for a sorted slice.
+func (s *quantileSketch) quantile(p float64) float64 {
+	if s.count == 0 {
+		return 0
+	}
+	if s.buckets == nil {
+		if !s.sorted {
+			sort.Float64s(s.exact)
+			s.sorted = true
+		}
+		return percentile(s.exact, p)
+	}
+	if p <= 0 {
+		return s.min
+	}
+	if p >= 100 {
+		return s.max
+	}
+
+	rank := (p / 100) * float64(s.count-1)
+	lower := int(rank)
+	weight := rank - float64(lower)
+	lowerValue := s.valueAt(lower)
+	if weight == 0 || lower+1 >= s.count {
+		return lowerValue
+	}
+	return lowerValue + (s.valueAt(lower+1)-lowerValue)*weight
+}
+
+// weightedValue is a duration and how many observations share it.
+type weightedValue struct {
+	value float64
+	count int
+}
+
+// points lists the sketch's observations in ascending order: the exact values
+// while it holds them, otherwise one point per bucket at the value quantile
+// would report for it.
+func (s *quantileSketch) points() []weightedValue {
+	if s.buckets == nil {
+		if !s.sorted {
+			sort.Float64s(s.exact)
+			s.sorted = true
+		}
+		points := make([]weightedValue, 0, len(s.exact))
+		for _, value := range s.exact {
+			if n := len(points); n > 0 && points[n-1].value == value {
+				points[n-1].count++
+				continue
+			}
+			points = append(points, weightedValue{value: value, count: 1})
+		}
+		return points
+	}
+	keys := make([]int, 0, len(s.buckets))
+	for key := range s.buckets {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	points := make([]weightedValue, 0, len(keys)+1)
+	if s.zeros > 0 {
+		points = append(points, weightedValue{value: math.Max(s.min, 0), count: s.zeros})
+	}
+	for _, key := range keys {
+		value := 2 * math.Exp(float64(key)*sketchLogGamma) / (1 + math.Exp(sketchLogGamma))
+		points = append(points, weightedValue{value: math.Min(math.Max(value, s.min), s.max), count: s.buckets[key]})
+	}
+	return points
+}
+
+// valueAt estimates the value at a zero-based rank from the bucket counts.
+func (s *quantileSketch) valueAt(rank int) float64 {
+	if rank < s.zeros {
+		return math.Max(s.min, 0)
+	}
+	keys := make([]int, 0, len(s.buckets))
+	for key := range s.buckets {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	seen := s.zeros
+	for _, key := range keys {
+		seen += s.buckets[key]
+		if rank < seen {
+			value := 2 * math.Exp(float64(key)*sketchLogGamma) / (1 + math.Exp(sketchLogGamma))
+			return math.Min(math.Max(value, s.min), s.max)
+		}
+	}
+	return s.max
+}
@@ -0,0 +1,77 @@
+package forecast
+
+import "sort"
+
+// survivalStep is the Kaplan–Meier survival probability from days onward: the
+// share of items still unreviewed after that many days.
+type survivalStep struct {
+	days     float64
+	survival float64
+}
+
+// kaplanMeier estimates how long items take to be reviewed when some have not
+// been yet. Completed reviews are events; pending items are right-censored at
+// their current age, so they count as still at risk up to that age and no
+// further. At equal durations, reviews are applied before censoring.
+func kaplanMeier(completed []weightedValue, censored []float64) []survivalStep {
+	sort.Float64s(censored)
+	atRisk := len(censored)
+	for _, point := range completed {
+		atRisk += point.count
+	}
+	steps := make([]survivalStep, 0, len(completed))
+	survival := 1.0
+	next := 0
+	for _, point := range completed {
+		for next < len(censored) && censored[next] < point.value {
+			atRisk--
+			next++
+		}
+		if atRisk <= 0 {
+			break
+		}
+		survival *= 1 - float64(point.count)/float64(atRisk)
+		steps = append(steps, survivalStep{days: point.value, survival: survival})
+		atRisk -= point.count
+	}
+	return steps
+}
+
+// survivalQuantile returns the first duration by which at least p percent of
+// items are reviewed, or nil when the curve never gets there because too many
+// items are still pending.
+func survivalQuantile(steps []survivalStep, p float64) *float64 {
+	for _, step := range steps {
+		if step.survival <= 1-p/100+1e-9 {
+			days := Round(step.days, 2)
+			return &days
+		}
+	}
+	return nil
+}
+
+// survivalBefore returns the share of items not reviewed in under days, the
+// same cut-off SLA breaches use.
+func survivalBefore(steps []survivalStep, days float64) float64 {
+	survival := 1.0
+	for _, step := range steps {
+		if step.days >= days {
+			break
+		}
+		survival = step.survival
+	}
+	return survival
+}
+
+// buildSurvivalEstimate summarizes the Kaplan–Meier curve for one stage (or
+// overall), with the chance of finishing before breaching slaDays.
+func buildSurvivalEstimate(acc *latencyAccumulator, censored []float64, slaDays int) *SurvivalEstimate {
+	steps := kaplanMeier(acc.sketch.points(), censored)
+	return &SurvivalEstimate{
+		Completed:     acc.count,
+		Censored:      len(censored),
+		MedianDays:    survivalQuantile(steps, 50),
+		P90Days:       survivalQuantile(steps, 90),
+		ProbWithinSLA: Round((1-survivalBefore(steps, float64(slaDays)))*100, 1),
+	}
+}
@@ -0,0 +1,322 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+// Dialect describes how an events or queue export is laid out, so files from
+// different portals load without reshaping them first. The zero value reads
+// comma-separated UTF-8 with standard quoting.
+type Dialect struct {
+	// Delimiter is a single character or one of comma, semicolon, tab, or pipe.
+	Delimiter string `json:"delimiter,omitempty"`
+	// Quote is standard (RFC 4180), lazy (tolerate stray quotes), or none (fields
+	// are taken literally).
+	Quote string `json:"quote,omitempty"`
+	// Encoding is utf-8, utf-16, utf-16le, utf-16be, latin1, or windows-1252. A
+	// leading byte order mark is always stripped, and a UTF-16 one is honored
+	// even when Encoding is unset.
+	Encoding string `json:"encoding,omitempty"`
+	// Timezone is the IANA zone for timestamps without an offset (YYYY-MM-DD
+	// and YYYY-MM-DD HH:MM:SS); empty means UTC. RFC3339 offsets always win.
+	Timezone string `json:"timezone,omitempty"`
+	// Aliases lists other header names accepted for each field, e.g.
+	// {"application_id": ["App ID", "Application"]}.
+	Aliases map[string][]string `json:"aliases,omitempty"`
+	// Source is the path the dialect was loaded from.
+	Source string `json:"-"`
+}
+
+// LoadDialect reads a JSON dialect file. An empty path returns the default
+// dialect.
+func LoadDialect(path string) (Dialect, error) {
+	if strings.TrimSpace(path) == "" {
+		return Dialect{}, nil
+	}
+	payload, err := os.ReadFile(path)
+	if err != nil {
+		return Dialect{}, err
+	}
+	var dialect Dialect
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&dialect); err != nil {
+		return Dialect{}, fmt.Errorf("invalid ingest config: %w", err)
+	}
+	if err := dialect.Validate(); err != nil {
+		return Dialect{}, fmt.Errorf("invalid ingest config: %w", err)
+	}
+	dialect.Source = forecast.SanitizePath(path)
+	return dialect, nil
+}
+
+// Validate checks the delimiter, quote mode, encoding, timezone, and alias fields.
+func (d Dialect) Validate() error {
+	if _, err := d.delimiter(); err != nil {
+		return err
+	}
+	switch d.quoteMode() {
+	case "standard", "lazy", "none":
+	default:
+		return fmt.Errorf("unknown quote mode %q (use standard, lazy, or none)", d.Quote)
+	}
+	if !encodings[d.encoding()] {
+		return fmt.Errorf("unknown encoding %q (use utf-8, utf-16, utf-16le, utf-16be, latin1, or windows-1252)", d.Encoding)
+	}
+	if _, err := loadLocation(d.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone: %w", err)
+	}
+	aliases := make(map[string]string, len(d.Aliases))
+	for field := range d.Aliases {
+		aliases[normalizeName(field)] = ""
+	}
+	return validateMapping(aliases)
+}
+
+// LoadEvents reads a review events CSV written in this dialect.
+func (d Dialect) LoadEvents(path string) ([]forecast.ReviewEvent, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return d.ReadEvents(file)
+}
+
+// ReadEvents parses review events CSV written in this dialect.
+func (d Dialect) ReadEvents(r io.Reader) ([]forecast.ReviewEvent, error) {
+	return readEventsCSV(r, d, nil)
+}
+
+// LoadQueue reads a pending queue CSV written in this dialect.
+func (d Dialect) LoadQueue(path string) ([]forecast.QueueItem, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return d.ReadQueue(file)
+}
+
+// ReadQueue parses pending queue CSV written in this dialect.
+func (d Dialect) ReadQueue(r io.Reader) ([]forecast.QueueItem, error) {
+	return readQueueCSV(r, d, nil)
+}
+
+func (d Dialect) delimiter() (rune, error) {
+	value := d.Delimiter
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "comma":
+		return ',', nil
+	case "semicolon":
+		return ';', nil
+	case "tab", `\t`:
+		return '\t', nil
+	case "pipe":
+		return '|', nil
+	}
+	delimiter, size := utf8.DecodeRuneInString(value)
+	if size != len(value) || delimiter == '"' || delimiter == '\r' || delimiter == '\n' || delimiter == utf8.RuneError {
+		return 0, fmt.Errorf("invalid delimiter %q (use one character, comma, semicolon, tab, or pipe)", value)
+	}
+	return delimiter, nil
+}
+
+// location resolves Timezone, falling back to UTC when it is empty or invalid;
+// Validate reports invalid zones.
+func (d Dialect) location() *time.Location {
+	location, err := loadLocation(d.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// locations caches loaded zones, since time.LoadLocation reads the zone
+// database on every call and JSON records resolve the zone per record.
+var locations sync.Map
+
+func loadLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return time.UTC, nil
+	}
+	if cached, ok := locations.Load(name); ok {
+		return cached.(*time.Location), nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, location)
+	return location, nil
+}
+
+// ReadEventsJSON parses a JSON array of events, applying the aliases and
+// timezone.
+func (d Dialect) ReadEventsJSON(r io.Reader) ([]forecast.ReviewEvent, error) {
+	return readEventsJSON(r, d, nil)
+}
+
+// ReadQueueJSON parses a JSON array of queue items, applying the aliases and
+// timezone.
+func (d Dialect) ReadQueueJSON(r io.Reader) ([]forecast.QueueItem, error) {
+	return readQueueJSON(r, d, nil)
+}
+
+func (d Dialect) quoteMode() string {
+	mode := strings.ToLower(strings.TrimSpace(d.Quote))
+	if mode == "" {
+		return "standard"
+	}
+	return mode
+}
+
+func (d Dialect) encoding() string {
+	name := strings.ToLower(strings.TrimSpace(d.Encoding))
+	name = strings.NewReplacer("_", "-", " ", "-").Replace(name)
+	switch name {
+	case "", "utf8":
+		return "utf-8"
+	case "utf16":
+		return "utf-16"
+	case "iso-8859-1", "latin-1":
+		return "latin1"
+	case "cp1252":
+		return "windows-1252"
+	}
+	return name
+}
+
+// recordReader yields one CSV record per call and io.EOF at the end.
+type recordReader interface {
+	Read() ([]string, error)
+}
+
+// records decodes r to UTF-8 and returns a reader over its records. Records are
+// read one at a time, so the input never has to fit in memory.
+func (d Dialect) records(r io.Reader) (recordReader, error) {
+	text, err := d.reader(r)
+	if err != nil {
+		return nil, err
+	}
+	return d.split(text)
+}
+
+// split reads records from text that has already been decoded to UTF-8.
+func (d Dialect) split(text io.Reader) (recordReader, error) {
+	delimiter, err := d.delimiter()
+	if err != nil {
+		return nil, err
+	}
+	mode := d.quoteMode()
+	if mode == "none" {
+		scanner := bufio.NewScanner(text)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		return &unquotedReader{scanner: scanner, delimiter: string(delimiter)}, nil
+	}
+	reader := csv.NewReader(text)
+	reader.Comma = delimiter
+	reader.LazyQuotes = mode == "lazy"
+	reader.ReuseRecord = true
+	return reader, nil
+}
+
+// unquotedReader splits each non-empty line on the delimiter without treating
+// quotes specially.
+type unquotedReader struct {
+	scanner   *bufio.Scanner
+	delimiter string
+}
+
+func (u *unquotedReader) Read() ([]string, error) {
+	for u.scanner.Scan() {
+		line := strings.TrimSuffix(u.scanner.Text(), "\r")
+		if line != "" {
+			return strings.Split(line, u.delimiter), nil
+		}
+	}
+	if err := u.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// reader wraps r so it yields UTF-8 without a byte order mark. A UTF-16 byte
+// order mark selects UTF-16 even when the encoding is left at utf-8.
+func (d Dialect) reader(r io.Reader) (*bufio.Reader, error) {
+	name := d.encoding()
+	if !encodings[name] {
+		return nil, fmt.Errorf("unknown encoding %q", d.Encoding)
+	}
+	src := bufio.NewReaderSize(r, 64*1024)
+	prefix, _ := src.Peek(3)
+	littleBOM := bytes.HasPrefix(prefix, []byte{0xFF, 0xFE})
+	bigBOM := bytes.HasPrefix(prefix, []byte{0xFE, 0xFF})
+	if name == "utf-8" && (littleBOM || bigBOM) {
+		name = "utf-16"
+	}
+
+	var decoder *encoding.Decoder
+	switch name {
+	case "utf-16", "utf-16le", "utf-16be":
+		// Without a byte order mark, plain utf-16 defaults to little endian as
+		// spreadsheet exports on Windows do.
+		bigEndian := name == "utf-16be"
+		if littleBOM || bigBOM {
+			bigEndian = bigBOM
+			src.Discard(2)
+		}
+		endianness := unicode.LittleEndian
+		if bigEndian {
+			endianness = unicode.BigEndian
+		}
+		decoder = unicode.UTF16(endianness, unicode.IgnoreBOM).NewDecoder()
+	case "latin1":
+		decoder = charmap.ISO8859_1.NewDecoder()
+	case "windows-1252":
+		decoder = charmap.Windows1252.NewDecoder()
+	}
+	if decoder != nil {
+		return bufio.NewReaderSize(transform.NewReader(src, decoder), 64*1024), nil
+	}
+	if bytes.HasPrefix(prefix, []byte{0xEF, 0xBB, 0xBF}) {
+		src.Discard(3)
+	}
+	return src, nil
+}
+
+var encodings = map[string]bool{
+	"utf-8":        true,
+	"utf-16":       true,
+	"utf-16le":     true,
+	"utf-16be":     true,
+	"latin1":       true,
+	"windows-1252": true,
+}
+
+// normalizeName folds a header or alias so "App ID", "app-id", and "app_id"
+// compare equal.
+func normalizeName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_' || r == '\t'
+	}), "_")
+}
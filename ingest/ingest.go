@@ -0,0 +1,282 @@
+// Package ingest reads review events and pending queue items from CSV, JSON, and
+// Postgres queries.
+package ingest
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+var (
+	eventColumns = []string{"application_id", "stage", "submitted_at", "reviewed_at", "reviewer_id"}
+	queueColumns = []string{"application_id", "stage", "submitted_at"}
+)
+
+func LoadEvents(path string) ([]forecast.ReviewEvent, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadEvents(file)
+}
+
+// ReadEvents parses completed review events from CSV. Rows must carry
+// application_id, stage, submitted_at, reviewed_at, and reviewer_id; program and
+// priority are optional.
+func ReadEvents(r io.Reader) ([]forecast.ReviewEvent, error) {
+	return readEventsCSV(r, Dialect{}, nil)
+}
+
+func readEventsCSV(r io.Reader, dialect Dialect, mapping map[string]string) ([]forecast.ReviewEvent, error) {
+	records, err := dialect.records(r)
+	if err != nil {
+		return nil, err
+	}
+	var events []forecast.ReviewEvent
+	err = streamEventsCSV(records, dialect, mapping, nil, func(event forecast.ReviewEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// streamEventsCSV parses events one row at a time and hands each to fn, so an
+// export of any size is read in constant memory.
+func streamEventsCSV(records recordReader, dialect Dialect, mapping map[string]string, screen *screen, fn func(forecast.ReviewEvent) error) error {
+	location := dialect.location()
+	return forEachRow(records, dialect, mapping, eventColumns, "CSV must include header and at least one row", func(row []string, idx map[string]int, rowNumber int) error {
+		event, err := parseRow(row, idx, location)
+		keep, err := screen.event(rowNumber, event, err)
+		if err != nil {
+			return fmt.Errorf("row %d: %w", rowNumber, err)
+		}
+		if !keep {
+			return nil
+		}
+		return fn(event)
+	})
+}
+
+func LoadQueue(path string) ([]forecast.QueueItem, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadQueue(file)
+}
+
+// ReadQueue parses pending queue items from CSV. Rows must carry application_id,
+// stage, and submitted_at; reviewer_id, program, and priority are optional.
+func ReadQueue(r io.Reader) ([]forecast.QueueItem, error) {
+	return readQueueCSV(r, Dialect{}, nil)
+}
+
+func readQueueCSV(r io.Reader, dialect Dialect, mapping map[string]string) ([]forecast.QueueItem, error) {
+	records, err := dialect.records(r)
+	if err != nil {
+		return nil, err
+	}
+	return queueFromRecords(records, dialect, mapping, nil)
+}
+
+func queueFromRecords(records recordReader, dialect Dialect, mapping map[string]string, screen *screen) ([]forecast.QueueItem, error) {
+	var items []forecast.QueueItem
+	location := dialect.location()
+	err := forEachRow(records, dialect, mapping, queueColumns, "queue CSV must include header and at least one row", func(row []string, idx map[string]int, rowNumber int) error {
+		item, err := parseQueueRow(row, idx, location)
+		keep, err := screen.queueItem(rowNumber, item, err)
+		if err != nil {
+			return fmt.Errorf("row %d: %w", rowNumber, err)
+		}
+		if keep {
+			items = append(items, item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// forEachRow reads the header, checks the required columns, and passes each
+// data row to fn with its record number, counting the header as 1. Rows may be
+// reused between calls.
+func forEachRow(records recordReader, dialect Dialect, mapping map[string]string, required []string, emptyMessage string, fn func(row []string, idx map[string]int, rowNumber int) error) error {
+	header, err := records.Read()
+	if err == io.EOF {
+		return invalidData(errors.New(emptyMessage))
+	}
+	if err != nil {
+		return recordError(err)
+	}
+	idx, err := buildIndex(header, mapping, dialect.Aliases)
+	if err != nil {
+		return invalidData(err)
+	}
+	if err := requireColumns(idx, required, "column"); err != nil {
+		return invalidData(err)
+	}
+
+	rows := 0
+	for rowNumber := 2; ; rowNumber++ {
+		row, err := records.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return recordError(err)
+		}
+		if len(row) == 0 {
+			continue
+		}
+		rows++
+		if err := fn(row, idx, rowNumber); err != nil {
+			return err
+		}
+	}
+	if rows == 0 {
+		return invalidData(errors.New(emptyMessage))
+	}
+	return nil
+}
+
+// recordError marks malformed CSV as invalid data and passes read failures
+// through unchanged.
+func recordError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return invalidData(err)
+	}
+	return err
+}
+
+func requireColumns(idx map[string]int, required []string, label string) error {
+	for _, key := range required {
+		if _, ok := idx[key]; !ok {
+			return fmt.Errorf("missing required %s: %s", label, key)
+		}
+	}
+	return nil
+}
+
+func normalizeHeader(header []string) []string {
+	out := make([]string, len(header))
+	for i, name := range header {
+		out[i] = normalizeName(name)
+	}
+	return out
+}
+
+// parseRow reads one event row. Timestamps without an offset are taken as wall
+// clock time in location.
+func parseRow(row []string, idx map[string]int, location *time.Location) (forecast.ReviewEvent, error) {
+	get := func(key string) string {
+		pos, ok := idx[key]
+		if !ok || pos >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[pos])
+	}
+
+	submittedAt, err := ParseDateIn(get("submitted_at"), location)
+	if err != nil {
+		return forecast.ReviewEvent{}, &rowIssue{IssueUnparseableDate, fmt.Errorf("invalid submitted_at: %w", err)}
+	}
+	reviewedAt, err := ParseDateIn(get("reviewed_at"), location)
+	if err != nil {
+		return forecast.ReviewEvent{}, &rowIssue{IssueUnparseableDate, fmt.Errorf("invalid reviewed_at: %w", err)}
+	}
+	if reviewedAt.Before(submittedAt) {
+		return forecast.ReviewEvent{}, &rowIssue{IssueReviewedBeforeSubmitted, errors.New("reviewed_at is before submitted_at")}
+	}
+
+	return forecast.ReviewEvent{
+		ApplicationID: get("application_id"),
+		Stage:         get("stage"),
+		SubmittedAt:   submittedAt,
+		ReviewedAt:    reviewedAt,
+		ReviewerID:    get("reviewer_id"),
+		Program:       get("program"),
+		Priority:      get("priority"),
+	}, nil
+}
+
+func parseQueueRow(row []string, idx map[string]int, location *time.Location) (forecast.QueueItem, error) {
+	get := func(key string) string {
+		pos, ok := idx[key]
+		if !ok || pos >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[pos])
+	}
+
+	submittedAt, err := ParseDateIn(get("submitted_at"), location)
+	if err != nil {
+		return forecast.QueueItem{}, &rowIssue{IssueUnparseableDate, fmt.Errorf("invalid submitted_at: %w", err)}
+	}
+
+	return forecast.QueueItem{
+		ApplicationID: get("application_id"),
+		Stage:         get("stage"),
+		SubmittedAt:   submittedAt,
+		ReviewerID:    get("reviewer_id"),
+		Program:       get("program"),
+		Priority:      get("priority"),
+	}, nil
+}
+
+// ParseDate accepts RFC3339, YYYY-MM-DD, and YYYY-MM-DD HH:MM:SS timestamps.
+// Timestamps without an offset are read as UTC.
+func ParseDate(value string) (time.Time, error) {
+	return ParseDateIn(value, time.UTC)
+}
+
+// ParseUntilIn parses the upper bound of a date range as an exclusive end. A
+// bare YYYY-MM-DD date runs through the end of that day, so it becomes the next
+// midnight in location; a timestamp is returned as parsed and is itself the
+// cutoff.
+func ParseUntilIn(value string, location *time.Location) (time.Time, error) {
+	parsed, err := ParseDateIn(value, location)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if _, err := time.Parse("2006-01-02", strings.TrimSpace(value)); err == nil {
+		parsed = parsed.AddDate(0, 0, 1)
+	}
+	return parsed, nil
+}
+
+// ParseDateIn is ParseDate with timestamps that carry no offset (YYYY-MM-DD and
+// YYYY-MM-DD HH:MM:SS) read as wall clock time in location. RFC3339 values keep
+// their own offset, so mixed sources still compare as the same instants.
+func ParseDateIn(value string, location *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, errors.New("empty date")
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02", "2006-01-02 15:04:05"} {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported format: %s", value)
+}
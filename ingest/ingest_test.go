@@ -0,0 +1,408 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+	"unicode/utf16"
+
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+func TestReadEventsParsesOptionalColumns(t *testing.T) {
+	input := "Application_ID,Stage,Submitted_At,Reviewed_At,Reviewer_ID,Priority\n" +
+		"app-1,initial_review,2026-01-02,2026-01-05 09:30:00,rev-01,expedited\n"
+	events, err := ReadEvents(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadEvents failed: %v", err)
+	}
+	if len(events) != 1 || events[0].Priority != "expedited" || events[0].Program != "" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if got := events[0].ReviewedAt.Format("2006-01-02 15:04"); got != "2026-01-05 09:30" {
+		t.Fatalf("unexpected reviewed_at: %s", got)
+	}
+
+	if _, err := ReadQueue(strings.NewReader("application_id,stage\napp-2,initial_review\n")); err == nil || !strings.Contains(err.Error(), "submitted_at") {
+		t.Fatalf("expected missing submitted_at error, got %v", err)
+	}
+}
+
+func TestColumnMappingSwapsColumnsAndRejectsMissingOnes(t *testing.T) {
+	// The export labels the stage and reviewer columns the wrong way round.
+	input := "application_id,stage,reviewer_id,submitted_at,reviewed_at\n" +
+		"app-1,rev-01,initial_review,2026-01-02,2026-01-05\n"
+	mapping, err := ParseMapping("stage=reviewer_id,reviewer_id=stage")
+	if err != nil {
+		t.Fatalf("ParseMapping failed: %v", err)
+	}
+	// Map iteration order varies, so repeat to catch order-dependent results.
+	for i := 0; i < 50; i++ {
+		events, err := readEventsCSV(strings.NewReader(input), Dialect{}, mapping)
+		if err != nil {
+			t.Fatalf("read events: %v", err)
+		}
+		if events[0].Stage != "initial_review" || events[0].ReviewerID != "rev-01" {
+			t.Fatalf("expected swapped columns, got stage %q reviewer %q", events[0].Stage, events[0].ReviewerID)
+		}
+	}
+
+	mapping, err = ParseMapping("reviewer_id=assigned_to")
+	if err != nil {
+		t.Fatalf("ParseMapping failed: %v", err)
+	}
+	_, err = readEventsCSV(strings.NewReader(input), Dialect{}, mapping)
+	if err == nil || !strings.Contains(err.Error(), "assigned_to") {
+		t.Fatalf("expected missing mapped column error, got %v", err)
+	}
+}
+
+func TestOpenSourceMapsColumnsAndQuotesViews(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.csv")
+	input := "app_id,stage,received_at,decided_at,reviewer\n" +
+		"app-1,initial_review,2026-01-02,2026-01-05,rev-01\n"
+	if err := os.WriteFile(path, []byte(input), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mapping, err := ParseMapping("application_id=app_id, submitted_at=received_at,reviewed_at=Decided_At,reviewer_id=reviewer")
+	if err != nil {
+		t.Fatalf("ParseMapping failed: %v", err)
+	}
+	source, err := OpenSource(path, SourceOptions{Mapping: mapping})
+	if err != nil {
+		t.Fatalf("OpenSource failed: %v", err)
+	}
+	events, err := source.Events(context.Background())
+	if err != nil {
+		t.Fatalf("Events failed: %v", err)
+	}
+	if len(events) != 1 || events[0].ApplicationID != "app-1" || events[0].ReviewerID != "rev-01" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+
+	if _, err := ParseMapping("decision=decided_at"); err == nil {
+		t.Fatal("expected unknown mapping field to fail")
+	}
+	if _, err := OpenSource("view:tracker.reviews", SourceOptions{}); err == nil {
+		t.Fatal("expected view source without a DSN to fail")
+	}
+	view, err := OpenSource(`view:tracker.bad"name`, SourceOptions{DSN: "postgres://localhost/tracker"})
+	if err != nil {
+		t.Fatalf("OpenSource view failed: %v", err)
+	}
+	if got := view.(*SQLSource).Query; got != `SELECT * FROM "tracker"."bad""name"` {
+		t.Fatalf("unexpected view query: %s", got)
+	}
+}
+
+func TestDialectReadsAliasedSemicolonAndUTF16Exports(t *testing.T) {
+	dialect := Dialect{
+		Delimiter: "semicolon",
+		Aliases: map[string][]string{
+			"application_id": {"App ID"},
+			"stage":          {"Review Step"},
+			"submitted_at":   {"Date Received"},
+		},
+	}
+	if err := dialect.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	input := "\ufeffApp ID;Review Step;Date Received;Reviewer ID\r\nA-1;initial_review;2026-01-02;rev-01\r\n"
+	items, err := dialect.ReadQueue(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadQueue failed: %v", err)
+	}
+	if len(items) != 1 || items[0].ApplicationID != "A-1" || items[0].ReviewerID != "rev-01" {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+
+	var utf16 []byte
+	for _, r := range "\ufeffapplication_id\tstage\tsubmitted_at\nA-2\tcomité\t2026-01-03\n" {
+		utf16 = append(utf16, byte(r), byte(r>>8))
+	}
+	items, err = Dialect{Delimiter: "tab"}.ReadQueue(bytes.NewReader(utf16))
+	if err != nil {
+		t.Fatalf("ReadQueue UTF-16 failed: %v", err)
+	}
+	if len(items) != 1 || items[0].Stage != "comité" {
+		t.Fatalf("unexpected UTF-16 items: %+v", items)
+	}
+
+	items, err = Dialect{Encoding: "windows-1252", Quote: "none"}.ReadQueue(strings.NewReader("application_id,stage,submitted_at\n\"A-3,r\x96sum\xe9,2026-01-04\n"))
+	if err != nil {
+		t.Fatalf("ReadQueue windows-1252 failed: %v", err)
+	}
+	if len(items) != 1 || items[0].ApplicationID != `"A-3` || items[0].Stage != "r–sumé" {
+		t.Fatalf("unexpected windows-1252 items: %+v", items)
+	}
+
+	for _, bad := range []Dialect{{Delimiter: "::"}, {Quote: "single"}, {Encoding: "ebcdic"}, {Aliases: map[string][]string{"decision": {"x"}}}} {
+		if err := bad.Validate(); err == nil {
+			t.Fatalf("expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestReadEventsNDJSONReportsLineNumbersAndDetectsFormats(t *testing.T) {
+	input := `{"application_id":"app-1","stage":"initial_review","submitted_at":"2026-01-02","reviewed_at":"2026-01-05","reviewer_id":"rev-01"}` + "\n\n" +
+		`{"application_id":"app-2","stage":"initial_review","submitted_at":"2026-01-09","reviewed_at":"2026-01-05","reviewer_id":"rev-02"}` + "\n"
+	_, err := ReadEventsNDJSON(strings.NewReader(input))
+	if err == nil || !strings.Contains(err.Error(), "line 3: reviewed_at is before submitted_at") {
+		t.Fatalf("expected line 3 error, got %v", err)
+	}
+	items, err := ReadQueueNDJSON(strings.NewReader(`{"application_id":"app-3","stage":"final","submitted_at":"2026-01-04","priority":1}` + "\n"))
+	if err != nil || len(items) != 1 || items[0].Priority != "1" {
+		t.Fatalf("unexpected queue items %+v (%v)", items, err)
+	}
+
+	for _, tc := range []struct{ path, content, want string }{
+		{"events.json", "", FormatJSON},
+		{"events.jsonl", "", FormatNDJSON},
+		{"-", "  [{}]", FormatJSON},
+		{"-", "{}\n{}", FormatNDJSON},
+		{"export.dat", "application_id,stage", FormatCSV},
+	} {
+		if got := DetectFormat(tc.path, tc.content); got != tc.want {
+			t.Fatalf("DetectFormat(%q, %q) = %s, want %s", tc.path, tc.content, got, tc.want)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Fatal("expected unknown format to fail")
+	}
+}
+
+func TestLoadErrorsFromInputContentMatchErrInvalidData(t *testing.T) {
+	for name, load := range map[string]func() error{
+		"bad row": func() error {
+			_, err := ReadEvents(strings.NewReader("application_id,stage,submitted_at,reviewed_at,reviewer_id\nA-1,s,2026-01-02,nope,r\n"))
+			return err
+		},
+		"missing column": func() error {
+			_, err := ReadQueue(strings.NewReader("application_id,stage\nA-1,s\n"))
+			return err
+		},
+		"malformed csv": func() error {
+			_, err := ReadQueue(strings.NewReader("application_id,stage,submitted_at\nA-1,\"s,2026-01-02\n"))
+			return err
+		},
+		"malformed json": func() error {
+			_, err := ReadEventsJSON(strings.NewReader(`[{"application_id":`))
+			return err
+		},
+	} {
+		if err := load(); !errors.Is(err, ErrInvalidData) {
+			t.Errorf("%s: expected ErrInvalidData, got %v", name, err)
+		}
+	}
+	if _, err := ReadEvents(iotest.ErrReader(errors.New("disk gone"))); err == nil || errors.Is(err, ErrInvalidData) {
+		t.Fatalf("expected a read failure not to count as invalid data, got %v", err)
+	}
+}
+
+func TestReadEventsJSONRejectsKeysThatNormalizeToTheSameField(t *testing.T) {
+	input := `[{"application_id":"app-1","Application ID":"app-9","stage":"initial_review","submitted_at":"2026-01-02","reviewed_at":"2026-01-05","reviewer_id":"rev-01"}]`
+	_, err := ReadEventsJSON(strings.NewReader(input))
+	if err == nil || !strings.Contains(err.Error(), `record 1: fields "Application ID" and "application_id" both map to application_id`) {
+		t.Fatalf("expected duplicate field error, got %v", err)
+	}
+	events, err := ReadEventsJSON(strings.NewReader(`[{"Application ID":"app-1","stage":"initial_review","submitted_at":"2026-01-02","reviewed_at":"2026-01-05","reviewer_id":"rev-01"}]`))
+	if err != nil || len(events) != 1 || events[0].ApplicationID != "app-1" {
+		t.Fatalf("unexpected events %+v (%v)", events, err)
+	}
+}
+
+func TestUTF16LoneSurrogateKeepsTheFollowingUnit(t *testing.T) {
+	header := "application_id,stage,submitted_at\n"
+	var units []uint16
+	units = append(units, utf16.Encode([]rune(header+"A-1,"))...)
+	// A high surrogate followed by "x" instead of a low surrogate.
+	units = append(units, 0xD83D)
+	units = append(units, utf16.Encode([]rune("x,2026-01-04\n"))...)
+	encoded := []byte{0xFF, 0xFE}
+	for _, unit := range units {
+		encoded = append(encoded, byte(unit), byte(unit>>8))
+	}
+	items, err := Dialect{}.ReadQueue(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatalf("ReadQueue failed: %v", err)
+	}
+	if len(items) != 1 || items[0].Stage != "\uFFFDx" {
+		t.Fatalf("expected the lone surrogate to become U+FFFD before x, got %+v", items)
+	}
+}
+
+func TestStreamEventsDecodesInChunksAndStopsOnError(t *testing.T) {
+	text := "application_id,stage,submitted_at,reviewed_at,reviewer_id\n" +
+		"A-1,comité 📋,2026-01-02,2026-01-05,rev-01\n" +
+		"A-2,final,2026-01-03,2026-01-06,rev-02\n"
+	encoded := []byte{0xFE, 0xFF}
+	for _, unit := range utf16.Encode([]rune(text)) {
+		encoded = append(encoded, byte(unit>>8), byte(unit))
+	}
+	records, err := Dialect{}.records(iotest.OneByteReader(bytes.NewReader(encoded)))
+	if err != nil {
+		t.Fatalf("records failed: %v", err)
+	}
+	var stages []string
+	err = streamEventsCSV(records, Dialect{}, nil, nil, func(event forecast.ReviewEvent) error {
+		stages = append(stages, event.Stage)
+		return nil
+	})
+	if err != nil || len(stages) != 2 || stages[0] != "comité 📋" {
+		t.Fatalf("unexpected stages %q (%v)", stages, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "events.json")
+	payload := `[{"application_id":"A-1","stage":"initial","submitted_at":"2026-01-02","reviewed_at":"2026-01-05","reviewer_id":"r"},` +
+		`{"application_id":"A-2","stage":"initial","submitted_at":"2026-01-02","reviewed_at":"2026-01-05","reviewer_id":"r"}]`
+	if err := os.WriteFile(path, []byte(payload), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	source, err := OpenSource(path, SourceOptions{})
+	if err != nil {
+		t.Fatalf("OpenSource failed: %v", err)
+	}
+	seen := 0
+	err = source.StreamEvents(context.Background(), func(forecast.ReviewEvent) error {
+		seen++
+		return errors.New("stop")
+	})
+	if seen != 1 || err == nil || err.Error() != "record 1: stop" {
+		t.Fatalf("expected the stream to stop after one event, saw %d (%v)", seen, err)
+	}
+}
+
+// BenchmarkStreamEventsCSV parses a generated one million row export without
+// materializing it.
+func BenchmarkStreamEventsCSV(b *testing.B) {
+	const rows = 1_000_000
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		reader, writer := io.Pipe()
+		go func() {
+			buffered := bufio.NewWriter(writer)
+			buffered.WriteString("application_id,stage,submitted_at,reviewed_at,reviewer_id\n")
+			for i := 0; i < rows; i++ {
+				fmt.Fprintf(buffered, "app-%d,initial_review,2025-01-%02d,2025-02-%02d,rev-%d\n", i, 1+i%28, 1+i%28, i%50)
+			}
+			buffered.Flush()
+			writer.Close()
+		}()
+		records, err := Dialect{}.records(reader)
+		if err != nil {
+			b.Fatal(err)
+		}
+		count := 0
+		err = streamEventsCSV(records, Dialect{}, nil, nil, func(forecast.ReviewEvent) error {
+			count++
+			return nil
+		})
+		if err != nil || count != rows {
+			b.Fatalf("streamed %d rows (%v)", count, err)
+		}
+	}
+}
+
+func TestLenientQualitySkipsBadRowsAndTalliesIssues(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.csv")
+	csvText := "application_id,stage,submitted_at,reviewed_at,reviewer_id\n" +
+		"A-1,initial,2026-01-02,2026-01-05,rev-01\n" +
+		"A-2,initial,not-a-date,2026-01-05,rev-01\n" +
+		"A-3,initial,2026-01-09,2026-01-05,rev-01\n" +
+		"A-1,initial,2026-01-02,2026-01-06,rev-02\n" +
+		"A-4,initial,2026-01-02,2099-01-01,rev-02\n" +
+		"A-5,mystery,2026-01-02,2026-01-07,\n"
+	if err := os.WriteFile(path, []byte(csvText), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	quality := NewQuality(time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), []string{"Initial"})
+	source, err := OpenSource(path, SourceOptions{Quality: quality})
+	if err != nil {
+		t.Fatalf("OpenSource failed: %v", err)
+	}
+	events, err := source.Events(context.Background())
+	if err != nil {
+		t.Fatalf("lenient load failed: %v", err)
+	}
+	if len(events) != 2 || events[1].ApplicationID != "A-5" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+
+	ndjson := `{"application_id":"Q-1","stage":"initial","submitted_at":"2026-01-02"}` + "\n\n" + `{"application_id":"Q-2","stage":"initial","submitted_at":"yesterday"}` + "\n"
+	items, err := queueFromJSONRecords(forEachNDJSON, strings.NewReader(ndjson), Dialect{}, nil, quality.screen("queue", "queue.ndjson"))
+	if err != nil || len(items) != 1 {
+		t.Fatalf("unexpected queue items %+v (%v)", items, err)
+	}
+
+	report := quality.Report()
+	if len(report) != 2 || report[0].RowsRead != 6 || report[0].RowsSkipped != 4 || report[0].RowsAccepted != 2 {
+		t.Fatalf("unexpected events summary: %+v", report)
+	}
+	want := map[string][]int{
+		IssueUnparseableDate:         {3},
+		IssueReviewedBeforeSubmitted: {4},
+		IssueDuplicateRow:            {5},
+		IssueFutureTimestamp:         {6},
+		IssueBlankReviewer:           {7},
+		IssueUnknownStage:            {7},
+	}
+	if len(report[0].Issues) != len(want) {
+		t.Fatalf("unexpected issues: %+v", report[0].Issues)
+	}
+	for _, issue := range report[0].Issues {
+		if rows := want[issue.Issue]; issue.Count != 1 || len(issue.SampleRows) != 1 || issue.SampleRows[0] != rows[0] {
+			t.Fatalf("unexpected %s issue: %+v", issue.Issue, issue)
+		}
+	}
+	if got := report[1].Issues; len(got) != 1 || got[0].Issue != IssueUnparseableDate || got[0].SampleRows[0] != 3 {
+		t.Fatalf("expected NDJSON line 3 to be skipped, got %+v", got)
+	}
+
+	if _, err := ReadEvents(strings.NewReader(csvText)); err == nil || err.Error() != "row 3: invalid submitted_at: unsupported format: not-a-date" {
+		t.Fatalf("expected strict load to fail on row 3, got %v", err)
+	}
+}
+
+func TestDialectTimezoneAppliesOnlyToNaiveTimestamps(t *testing.T) {
+	dialect := Dialect{Timezone: "America/Los_Angeles"}
+	if err := dialect.Validate(); err != nil {
+		t.Skipf("zone database unavailable: %v", err)
+	}
+	input := "application_id,stage,submitted_at,reviewed_at,reviewer_id\n" +
+		"app-1,initial,2026-03-07,2026-03-08 12:00:00,rev-01\n" +
+		"app-2,initial,2026-03-07T09:00:00Z,2026-03-08T12:00:00+02:00,rev-01\n"
+	csvEvents, err := dialect.ReadEvents(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	jsonEvents, err := dialect.ReadEventsJSON(strings.NewReader(`[{"application_id":"app-1","stage":"initial","submitted_at":"2026-03-07","reviewed_at":"2026-03-08 12:00:00","reviewer_id":"rev-01"}]`))
+	if err != nil {
+		t.Fatalf("read json: %v", err)
+	}
+	for _, events := range [][]forecast.ReviewEvent{csvEvents, jsonEvents} {
+		if got := events[0].SubmittedAt.Format(time.RFC3339); got != "2026-03-07T00:00:00-08:00" {
+			t.Fatalf("expected a Pacific midnight, got %s", got)
+		}
+		// Noon on March 8 is after the spring-forward switch.
+		if got := events[0].ReviewedAt.Format(time.RFC3339); got != "2026-03-08T12:00:00-07:00" {
+			t.Fatalf("expected Pacific daylight time, got %s", got)
+		}
+	}
+	if got := csvEvents[1].ReviewedAt.Format(time.RFC3339); got != "2026-03-08T12:00:00+02:00" {
+		t.Fatalf("expected an explicit offset to be kept, got %s", got)
+	}
+
+	if err := (Dialect{Timezone: "Mars/Olympus"}).Validate(); err == nil {
+		t.Fatal("expected an unknown timezone to be rejected")
+	}
+}
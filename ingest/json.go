@@ -0,0 +1,232 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+// ReadEventsJSON parses a JSON array of event objects keyed by the CSV column
+// names (application_id, stage, submitted_at, reviewed_at, reviewer_id, ...).
+func ReadEventsJSON(r io.Reader) ([]forecast.ReviewEvent, error) {
+	return readEventsJSON(r, Dialect{}, nil)
+}
+
+func readEventsJSON(r io.Reader, dialect Dialect, mapping map[string]string) ([]forecast.ReviewEvent, error) {
+	return collectRecordEvents(forEachJSONRecord, r, dialect, mapping)
+}
+
+// ReadQueueJSON parses a JSON array of queue item objects keyed by the queue CSV
+// column names.
+func ReadQueueJSON(r io.Reader) ([]forecast.QueueItem, error) {
+	return readQueueJSON(r, Dialect{}, nil)
+}
+
+func readQueueJSON(r io.Reader, dialect Dialect, mapping map[string]string) ([]forecast.QueueItem, error) {
+	return queueFromJSONRecords(forEachJSONRecord, r, dialect, mapping, nil)
+}
+
+// ReadEventsNDJSON parses newline-delimited event objects, one per line. Blank
+// lines are skipped and errors name the line they came from.
+func ReadEventsNDJSON(r io.Reader) ([]forecast.ReviewEvent, error) {
+	return readEventsNDJSON(r, Dialect{}, nil)
+}
+
+func readEventsNDJSON(r io.Reader, dialect Dialect, mapping map[string]string) ([]forecast.ReviewEvent, error) {
+	return collectRecordEvents(forEachNDJSON, r, dialect, mapping)
+}
+
+// ReadQueueNDJSON parses newline-delimited queue item objects, one per line.
+func ReadQueueNDJSON(r io.Reader) ([]forecast.QueueItem, error) {
+	return readQueueNDJSON(r, Dialect{}, nil)
+}
+
+func readQueueNDJSON(r io.Reader, dialect Dialect, mapping map[string]string) ([]forecast.QueueItem, error) {
+	return queueFromJSONRecords(forEachNDJSON, r, dialect, mapping, nil)
+}
+
+// recordIterator walks JSON objects in r, passing each with its record or line
+// number.
+type recordIterator func(r io.Reader, fn func(record map[string]any, number int) error) error
+
+func collectRecordEvents(each recordIterator, r io.Reader, dialect Dialect, mapping map[string]string) ([]forecast.ReviewEvent, error) {
+	var events []forecast.ReviewEvent
+	err := streamRecordEvents(each, r, dialect, mapping, nil, func(event forecast.ReviewEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func streamRecordEvents(each recordIterator, r io.Reader, dialect Dialect, mapping map[string]string, screen *screen, fn func(forecast.ReviewEvent) error) error {
+	return each(r, func(record map[string]any, number int) error {
+		event, err := eventFromRecord(record, dialect, mapping)
+		keep, err := screen.event(number, event, err)
+		if err != nil || !keep {
+			return err
+		}
+		return fn(event)
+	})
+}
+
+func queueFromJSONRecords(each recordIterator, r io.Reader, dialect Dialect, mapping map[string]string, screen *screen) ([]forecast.QueueItem, error) {
+	var items []forecast.QueueItem
+	err := each(r, func(record map[string]any, number int) error {
+		item, err := queueItemFromRecord(record, dialect, mapping)
+		keep, err := screen.queueItem(number, item, err)
+		if keep {
+			items = append(items, item)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func eventFromRecord(record map[string]any, dialect Dialect, mapping map[string]string) (forecast.ReviewEvent, error) {
+	header, row, err := jsonRecordRow(record)
+	if err != nil {
+		return forecast.ReviewEvent{}, invalidData(err)
+	}
+	idx, err := buildIndex(header, mapping, dialect.Aliases)
+	if err != nil {
+		return forecast.ReviewEvent{}, invalidData(err)
+	}
+	if err := requireColumns(idx, eventColumns, "field"); err != nil {
+		return forecast.ReviewEvent{}, invalidData(err)
+	}
+	return parseRow(row, idx, dialect.location())
+}
+
+func queueItemFromRecord(record map[string]any, dialect Dialect, mapping map[string]string) (forecast.QueueItem, error) {
+	header, row, err := jsonRecordRow(record)
+	if err != nil {
+		return forecast.QueueItem{}, invalidData(err)
+	}
+	idx, err := buildIndex(header, mapping, dialect.Aliases)
+	if err != nil {
+		return forecast.QueueItem{}, invalidData(err)
+	}
+	if err := requireColumns(idx, queueColumns, "field"); err != nil {
+		return forecast.QueueItem{}, invalidData(err)
+	}
+	return parseQueueRow(row, idx, dialect.location())
+}
+
+// forEachJSONRecord decodes a JSON array one object at a time and passes each to
+// fn, prefixing errors with the record number.
+func forEachJSONRecord(r io.Reader, fn func(record map[string]any, number int) error) error {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	token, err := decoder.Token()
+	if err != nil {
+		return invalidData(fmt.Errorf("invalid JSON: %w", err))
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return invalidData(errors.New("invalid JSON: expected an array of records"))
+	}
+	count := 0
+	for decoder.More() {
+		count++
+		var record map[string]any
+		if err := decoder.Decode(&record); err != nil {
+			return invalidData(fmt.Errorf("record %d: invalid JSON: %w", count, err))
+		}
+		if err := fn(record, count); err != nil {
+			return fmt.Errorf("record %d: %w", count, err)
+		}
+	}
+	if _, err := decoder.Token(); err != nil {
+		return invalidData(fmt.Errorf("invalid JSON: %w", err))
+	}
+	if count == 0 {
+		return invalidData(errors.New("JSON must include at least one record"))
+	}
+	return nil
+}
+
+// forEachNDJSON decodes one object per non-blank line and passes it to fn,
+// prefixing errors with the line number.
+func forEachNDJSON(r io.Reader, fn func(record map[string]any, number int) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line, count := 0, 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var record map[string]any
+		decoder := json.NewDecoder(bytes.NewReader(text))
+		decoder.UseNumber()
+		if err := decoder.Decode(&record); err != nil {
+			return invalidData(fmt.Errorf("line %d: invalid JSON: %w", line, err))
+		}
+		if decoder.More() {
+			return invalidData(fmt.Errorf("line %d: invalid JSON: more than one value on the line", line))
+		}
+		if err := fn(record, line); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if count == 0 {
+		return invalidData(errors.New("NDJSON must include at least one record"))
+	}
+	return nil
+}
+
+// jsonRecordRow flattens a JSON object into the header/row shape the CSV parsers
+// use. Keys are taken in sorted order, and two keys that normalize to the same
+// column, such as "application_id" and "Application ID", are an error rather
+// than a pick that depends on map order.
+func jsonRecordRow(record map[string]any) ([]string, []string, error) {
+	keys := make([]string, 0, len(record))
+	for key := range record {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	header := make([]string, 0, len(record))
+	row := make([]string, 0, len(record))
+	seen := make(map[string]string, len(record))
+	for _, key := range keys {
+		name := normalizeName(key)
+		if other, ok := seen[name]; ok {
+			return nil, nil, fmt.Errorf("fields %q and %q both map to %s", other, key, name)
+		}
+		seen[name] = key
+		value := record[key]
+		text := ""
+		switch typed := value.(type) {
+		case nil:
+		case string:
+			text = typed
+		case json.Number:
+			text = typed.String()
+		case bool:
+			text = strconv.FormatBool(typed)
+		default:
+			text = fmt.Sprint(typed)
+		}
+		header = append(header, strings.TrimSpace(key))
+		row = append(row, text)
+	}
+	return header, row, nil
+}
@@ -0,0 +1,312 @@
+package ingest
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+// Data-quality issues tracked by a lenient load. Rows with the first four are
+// skipped; rows with the last two are kept and only counted.
+const (
+	IssueUnparseableDate         = "unparseable_date"
+	IssueReviewedBeforeSubmitted = "reviewed_before_submitted"
+	IssueFutureTimestamp         = "future_timestamp"
+	IssueDuplicateRow            = "duplicate_row"
+	IssueBlankReviewer           = "blank_reviewer"
+	IssueUnknownStage            = "unknown_stage"
+)
+
+var issueOrder = []string{
+	IssueUnparseableDate,
+	IssueReviewedBeforeSubmitted,
+	IssueFutureTimestamp,
+	IssueDuplicateRow,
+	IssueBlankReviewer,
+	IssueUnknownStage,
+}
+
+// maxSampleRows caps how many row numbers are kept per issue.
+const maxSampleRows = 10
+
+// ErrInvalidData matches, through errors.Is, load errors caused by what an
+// input contains rather than by reading it: unparseable rows, missing
+// columns, malformed CSV or JSON, and inputs without any rows.
+var ErrInvalidData = errors.New("invalid input data")
+
+// rowIssue marks a parse error that a lenient load can skip past.
+type rowIssue struct {
+	kind string
+	err  error
+}
+
+func (e *rowIssue) Error() string {
+	return e.err.Error()
+}
+
+func (e *rowIssue) Unwrap() error {
+	return e.err
+}
+
+func (e *rowIssue) Is(target error) bool {
+	return target == ErrInvalidData
+}
+
+// dataError marks an error as caused by the input's content, keeping its
+// message unchanged.
+type dataError struct {
+	err error
+}
+
+func (e *dataError) Error() string {
+	return e.err.Error()
+}
+
+func (e *dataError) Unwrap() error {
+	return e.err
+}
+
+func (e *dataError) Is(target error) bool {
+	return target == ErrInvalidData
+}
+
+// invalidData wraps a non-nil err so it matches ErrInvalidData.
+func invalidData(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &dataError{err: err}
+}
+
+// Quality screens rows for a lenient load. Rows with unparseable dates,
+// reviewed_at before submitted_at, timestamps after Now, or a repeated
+// application_id + stage + submitted_at are skipped; blank reviewers and stages
+// missing from KnownStages are kept. Each issue is counted with sample row
+// numbers, numbered as in load errors (CSV row, JSON record, or NDJSON line).
+type Quality struct {
+	Now         time.Time
+	KnownStages []string
+	// MaxDiagnostics is how many per-row diagnostics to keep across inputs;
+	// zero keeps none.
+	MaxDiagnostics int
+	inputs         []*screen
+	diagnostics    []Diagnostic
+	omitted        int
+}
+
+// Diagnostic describes one issue found on one row. Severity is "error" for
+// skipped rows and "warning" for kept ones.
+type Diagnostic struct {
+	Input    string `json:"input"`
+	Source   string `json:"source"`
+	Row      int    `json:"row"`
+	Severity string `json:"severity"`
+	Issue    string `json:"issue"`
+	Message  string `json:"message"`
+}
+
+// NewQuality starts a lenient load. An empty knownStages list only flags blank
+// stages as unknown.
+func NewQuality(now time.Time, knownStages []string) *Quality {
+	return &Quality{Now: now, KnownStages: knownStages}
+}
+
+// Report summarizes every input screened so far, in the order it was read.
+func (q *Quality) Report() []forecast.DataQualityInput {
+	if q == nil {
+		return nil
+	}
+	out := make([]forecast.DataQualityInput, 0, len(q.inputs))
+	for _, s := range q.inputs {
+		input := forecast.DataQualityInput{
+			Input:        s.input,
+			Source:       s.source,
+			RowsRead:     s.read,
+			RowsSkipped:  s.skipped,
+			RowsAccepted: s.read - s.skipped,
+			Issues:       []forecast.DataQualityIssue{},
+		}
+		for _, kind := range issueOrder {
+			if s.counts[kind] == 0 {
+				continue
+			}
+			action := "skipped"
+			if kept(kind) {
+				action = "kept"
+			}
+			input.Issues = append(input.Issues, forecast.DataQualityIssue{
+				Issue:      kind,
+				Action:     action,
+				Count:      s.counts[kind],
+				SampleRows: s.samples[kind],
+			})
+		}
+		out = append(out, input)
+	}
+	return out
+}
+
+// Diagnostics returns the per-row diagnostics kept so far and how many more were
+// found beyond MaxDiagnostics.
+func (q *Quality) Diagnostics() ([]Diagnostic, int) {
+	if q == nil {
+		return nil, 0
+	}
+	return q.diagnostics, q.omitted
+}
+
+// screen returns the screen for one input, or nil when q is nil so loads stay
+// strict.
+func (q *Quality) screen(input string, source string) *screen {
+	if q == nil {
+		return nil
+	}
+	known := map[string]bool{}
+	for _, stage := range q.KnownStages {
+		known[strings.ToLower(strings.TrimSpace(stage))] = true
+	}
+	s := &screen{
+		quality: q,
+		input:   input,
+		source:  source,
+		now:     q.Now,
+		known:   known,
+		counts:  map[string]int{},
+		samples: map[string][]int{},
+		seen:    map[uint64]int{},
+	}
+	q.inputs = append(q.inputs, s)
+	return s
+}
+
+type screen struct {
+	quality *Quality
+	input   string
+	source  string
+	now     time.Time
+	known   map[string]bool
+	read    int
+	skipped int
+	counts  map[string]int
+	samples map[string][]int
+	// seen maps hashed row keys, rather than the keys themselves, to the row
+	// they were first seen on so duplicate checks stay small on long histories.
+	seen map[uint64]int
+}
+
+// event reports whether a parsed event should be kept. A nil screen keeps every
+// row that parsed and returns parse errors unchanged; otherwise skippable errors
+// are counted and swallowed.
+func (s *screen) event(row int, event forecast.ReviewEvent, err error) (bool, error) {
+	if s == nil {
+		return err == nil, err
+	}
+	s.read++
+	if err != nil {
+		return s.parseError(row, err)
+	}
+	if s.future(row, "submitted_at", event.SubmittedAt) || s.future(row, "reviewed_at", event.ReviewedAt) ||
+		s.duplicate(row, event.ApplicationID, event.Stage, event.SubmittedAt) {
+		return false, nil
+	}
+	if event.ReviewerID == "" {
+		s.record(IssueBlankReviewer, row, "reviewer_id is blank")
+	}
+	s.checkStage(row, event.Stage)
+	return true, nil
+}
+
+// queueItem is event for queue rows; blank reviewers are normal there.
+func (s *screen) queueItem(row int, item forecast.QueueItem, err error) (bool, error) {
+	if s == nil {
+		return err == nil, err
+	}
+	s.read++
+	if err != nil {
+		return s.parseError(row, err)
+	}
+	if s.future(row, "submitted_at", item.SubmittedAt) || s.duplicate(row, item.ApplicationID, item.Stage, item.SubmittedAt) {
+		return false, nil
+	}
+	s.checkStage(row, item.Stage)
+	return true, nil
+}
+
+// parseError skips rows whose parse error is a known issue and returns any
+// other error, such as a missing column, unchanged.
+func (s *screen) parseError(row int, err error) (bool, error) {
+	var issue *rowIssue
+	if !errors.As(err, &issue) {
+		return false, err
+	}
+	s.skip(issue.kind, row, err.Error())
+	return false, nil
+}
+
+func (s *screen) future(row int, field string, value time.Time) bool {
+	if !value.After(s.now) {
+		return false
+	}
+	s.skip(IssueFutureTimestamp, row, fmt.Sprintf("%s %s is in the future", field, value.Format(time.RFC3339)))
+	return true
+}
+
+func (s *screen) duplicate(row int, applicationID string, stage string, submittedAt time.Time) bool {
+	hash := fnv.New64a()
+	hash.Write([]byte(applicationID + "\x00" + stage + "\x00" + submittedAt.UTC().Format(time.RFC3339Nano)))
+	key := hash.Sum64()
+	if first, ok := s.seen[key]; ok {
+		s.skip(IssueDuplicateRow, row, fmt.Sprintf("duplicate of row %d (same application_id, stage, and submitted_at)", first))
+		return true
+	}
+	s.seen[key] = row
+	return false
+}
+
+func (s *screen) checkStage(row int, stage string) {
+	name := strings.ToLower(strings.TrimSpace(stage))
+	switch {
+	case name == "":
+		s.record(IssueUnknownStage, row, "stage is blank")
+	case len(s.known) > 0 && !s.known[name]:
+		s.record(IssueUnknownStage, row, fmt.Sprintf("stage %q is not in the SLA policy", stage))
+	}
+}
+
+func (s *screen) skip(kind string, row int, message string) {
+	s.skipped++
+	s.record(kind, row, message)
+}
+
+func (s *screen) record(kind string, row int, message string) {
+	s.counts[kind]++
+	if len(s.samples[kind]) < maxSampleRows {
+		s.samples[kind] = append(s.samples[kind], row)
+	}
+	q := s.quality
+	if len(q.diagnostics) >= q.MaxDiagnostics {
+		q.omitted++
+		return
+	}
+	severity := "error"
+	if kept(kind) {
+		severity = "warning"
+	}
+	q.diagnostics = append(q.diagnostics, Diagnostic{
+		Input:    s.input,
+		Source:   s.source,
+		Row:      row,
+		Severity: severity,
+		Issue:    kind,
+		Message:  message,
+	})
+}
+
+// kept reports whether rows with this issue stay in the load.
+func kept(kind string) bool {
+	return kind == IssueBlankReviewer || kind == IssueUnknownStage
+}
@@ -0,0 +1,340 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+// Source yields review events or pending queue items from one location. A source
+// spec is a file path, "-" for stdin, "sql:<query>", or "view:<name>".
+type Source interface {
+	// Name describes the source for stored runs and messages without exposing
+	// credentials.
+	Name() string
+	Events(ctx context.Context) ([]forecast.ReviewEvent, error)
+	// StreamEvents passes each event to fn as it is read, without holding the
+	// whole history in memory. An error from fn stops the stream.
+	StreamEvents(ctx context.Context, fn func(forecast.ReviewEvent) error) error
+	QueueItems(ctx context.Context) ([]forecast.QueueItem, error)
+}
+
+// SourceOptions configures how a spec is opened.
+type SourceOptions struct {
+	// DSN is the Postgres connection string for sql: and view: sources.
+	DSN string
+	// Mapping renames source columns onto the expected fields, keyed by field
+	// (application_id, stage, submitted_at, reviewed_at, reviewer_id, program,
+	// priority).
+	Mapping map[string]string
+	// Dialect controls CSV parsing; its aliases also apply to query columns.
+	Dialect Dialect
+	// Format is auto, csv, json, or ndjson for file and stdin sources.
+	Format string
+	// Quality, when set, makes loads lenient: bad rows are skipped and tallied
+	// instead of failing the load.
+	Quality *Quality
+}
+
+const (
+	sqlPrefix  = "sql:"
+	viewPrefix = "view:"
+)
+
+// OpenSource resolves a source spec.
+func OpenSource(spec string, opts SourceOptions) (Source, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, errors.New("source is empty")
+	}
+	if err := validateMapping(opts.Mapping); err != nil {
+		return nil, err
+	}
+	if err := opts.Dialect.Validate(); err != nil {
+		return nil, err
+	}
+	switch {
+	case strings.HasPrefix(spec, sqlPrefix):
+		query := strings.TrimSpace(strings.TrimPrefix(spec, sqlPrefix))
+		if query == "" {
+			return nil, errors.New("sql source has no query")
+		}
+		return newSQLSource(query, spec, opts)
+	case strings.HasPrefix(spec, viewPrefix):
+		name := strings.TrimSpace(strings.TrimPrefix(spec, viewPrefix))
+		query, err := viewQuery(name)
+		if err != nil {
+			return nil, err
+		}
+		return newSQLSource(query, spec, opts)
+	}
+	format, err := ParseFormat(opts.Format)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSource{Path: spec, Format: format, Mapping: opts.Mapping, Dialect: opts.Dialect, Quality: opts.Quality}, nil
+}
+
+// IsSQLSpec reports whether a spec reads from Postgres.
+func IsSQLSpec(spec string) bool {
+	spec = strings.TrimSpace(spec)
+	return strings.HasPrefix(spec, sqlPrefix) || strings.HasPrefix(spec, viewPrefix)
+}
+
+// FileSource reads CSV, a JSON array, or NDJSON from a file, or from stdin when
+// Path is "-". An empty or "auto" Format picks one from the file extension and
+// then from the first byte of the content.
+type FileSource struct {
+	Path    string
+	Format  string
+	Mapping map[string]string
+	Dialect Dialect
+	Quality *Quality
+}
+
+func (s *FileSource) Name() string {
+	if s.Path == StdinPath {
+		return "stdin"
+	}
+	return s.Path
+}
+
+func (s *FileSource) Events(ctx context.Context) ([]forecast.ReviewEvent, error) {
+	return collectEvents(ctx, s)
+}
+
+func (s *FileSource) StreamEvents(ctx context.Context, fn func(forecast.ReviewEvent) error) error {
+	text, format, closer, err := s.open()
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+	fn = checkContext(ctx, fn)
+	screen := s.Quality.screen("events", s.Name())
+	switch format {
+	case FormatJSON:
+		return streamRecordEvents(forEachJSONRecord, text, s.Dialect, s.Mapping, screen, fn)
+	case FormatNDJSON:
+		return streamRecordEvents(forEachNDJSON, text, s.Dialect, s.Mapping, screen, fn)
+	}
+	records, err := s.Dialect.split(text)
+	if err != nil {
+		return err
+	}
+	return streamEventsCSV(records, s.Dialect, s.Mapping, screen, fn)
+}
+
+func (s *FileSource) QueueItems(ctx context.Context) ([]forecast.QueueItem, error) {
+	text, format, closer, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	screen := s.Quality.screen("queue", s.Name())
+	switch format {
+	case FormatJSON:
+		return queueFromJSONRecords(forEachJSONRecord, text, s.Dialect, s.Mapping, screen)
+	case FormatNDJSON:
+		return queueFromJSONRecords(forEachNDJSON, text, s.Dialect, s.Mapping, screen)
+	}
+	records, err := s.Dialect.split(text)
+	if err != nil {
+		return nil, err
+	}
+	return queueFromRecords(records, s.Dialect, s.Mapping, screen)
+}
+
+// formatPeek is how much decoded text format detection looks at.
+const formatPeek = 4096
+
+// open returns the input decoded to UTF-8 and its resolved format. Only the
+// first formatPeek bytes are read ahead to detect the format.
+func (s *FileSource) open() (*bufio.Reader, string, io.Closer, error) {
+	var file io.ReadCloser = io.NopCloser(os.Stdin)
+	if s.Path != StdinPath {
+		opened, err := os.Open(s.Path)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		file = opened
+	}
+	text, err := s.Dialect.reader(file)
+	if err != nil {
+		file.Close()
+		return nil, "", nil, err
+	}
+	format := s.Format
+	if format == "" || format == FormatAuto {
+		head, _ := text.Peek(formatPeek)
+		format = DetectFormat(s.Path, string(head))
+	}
+	return text, format, file, nil
+}
+
+// collectEvents gathers a source's stream into a slice.
+func collectEvents(ctx context.Context, source Source) ([]forecast.ReviewEvent, error) {
+	var events []forecast.ReviewEvent
+	err := source.StreamEvents(ctx, func(event forecast.ReviewEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// checkContext wraps fn so a long stream stops once ctx is cancelled. The
+// context is polled every few thousand events to keep the check cheap.
+func checkContext(ctx context.Context, fn func(forecast.ReviewEvent) error) func(forecast.ReviewEvent) error {
+	count := 0
+	return func(event forecast.ReviewEvent) error {
+		count++
+		if count%4096 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		return fn(event)
+	}
+}
+
+// Input formats accepted by FileSource.
+const (
+	FormatAuto   = "auto"
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+// StdinPath is the file path that reads from standard input.
+const StdinPath = "-"
+
+// ParseFormat normalizes an input format name.
+func ParseFormat(value string) (string, error) {
+	switch format := strings.ToLower(strings.TrimSpace(value)); format {
+	case "", FormatAuto:
+		return FormatAuto, nil
+	case FormatCSV, FormatJSON, FormatNDJSON:
+		return format, nil
+	case "jsonl":
+		return FormatNDJSON, nil
+	default:
+		return "", fmt.Errorf("unknown input format %q (use auto, csv, json, or ndjson)", value)
+	}
+}
+
+// DetectFormat picks a format from the path extension, falling back to the
+// content: a leading '[' is a JSON array, a leading '{' is NDJSON, and anything
+// else is CSV.
+func DetectFormat(path string, content string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	case ".csv", ".tsv", ".txt":
+		return FormatCSV
+	}
+	switch trimmed := strings.TrimLeft(content, " \t\r\n"); {
+	case strings.HasPrefix(trimmed, "["):
+		return FormatJSON
+	case strings.HasPrefix(trimmed, "{"):
+		return FormatNDJSON
+	}
+	return FormatCSV
+}
+
+// ParseMapping parses "field=column,field=column" into a column mapping.
+func ParseMapping(value string) (map[string]string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	mapping := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		field, column, ok := strings.Cut(pair, "=")
+		field = normalizeName(field)
+		column = strings.TrimSpace(column)
+		if !ok || field == "" || column == "" {
+			return nil, fmt.Errorf("invalid column mapping %q (want field=column)", strings.TrimSpace(pair))
+		}
+		mapping[field] = column
+	}
+	return mapping, validateMapping(mapping)
+}
+
+var mappableFields = map[string]bool{
+	"application_id": true,
+	"stage":          true,
+	"submitted_at":   true,
+	"reviewed_at":    true,
+	"reviewer_id":    true,
+	"program":        true,
+	"priority":       true,
+}
+
+func validateMapping(mapping map[string]string) error {
+	for field := range mapping {
+		if !mappableFields[field] {
+			fields := make([]string, 0, len(mappableFields))
+			for name := range mappableFields {
+				fields = append(fields, name)
+			}
+			sort.Strings(fields)
+			return fmt.Errorf("unknown mapping field %q (available: %s)", field, strings.Join(fields, ", "))
+		}
+	}
+	return nil
+}
+
+// buildIndex maps normalized header names to column positions. Explicitly
+// mapped columns take precedence; dialect aliases fill fields whose own name is
+// absent. Aliases and mappings are resolved against the header itself, so a
+// mapping that swaps two columns reads each from the other's position. A
+// mapped column missing from the header is an error.
+func buildIndex(header []string, mapping map[string]string, aliases map[string][]string) (map[string]int, error) {
+	positions := map[string]int{}
+	for i, name := range normalizeHeader(header) {
+		if _, ok := positions[name]; !ok {
+			positions[name] = i
+		}
+	}
+	idx := make(map[string]int, len(positions))
+	for name, pos := range positions {
+		idx[name] = pos
+	}
+	for field, names := range aliases {
+		field = normalizeName(field)
+		if _, ok := positions[field]; ok {
+			continue
+		}
+		for _, name := range names {
+			if pos, ok := positions[normalizeName(name)]; ok {
+				idx[field] = pos
+				break
+			}
+		}
+	}
+	fields := make([]string, 0, len(mapping))
+	for field := range mapping {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		pos, ok := positions[normalizeName(mapping[field])]
+		if !ok {
+			return nil, fmt.Errorf("mapped column %q for %s not found", mapping[field], field)
+		}
+		idx[field] = pos
+	}
+	return idx, nil
+}
@@ -0,0 +1,202 @@
+package ingest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+// SQLSource runs a query against Postgres and maps its result columns onto the
+// CSV field names, so tracker tables and views can feed the report directly.
+type SQLSource struct {
+	DSN     string
+	Query   string
+	Label   string
+	Mapping map[string]string
+	Aliases map[string][]string
+	// Timezone is the IANA zone for date and timestamp columns without a time
+	// zone; timestamptz columns are unaffected.
+	Timezone string
+	Quality  *Quality
+}
+
+func newSQLSource(query string, label string, opts SourceOptions) (*SQLSource, error) {
+	if strings.TrimSpace(opts.DSN) == "" {
+		return nil, fmt.Errorf("%s needs a source database DSN", label)
+	}
+	return &SQLSource{DSN: opts.DSN, Query: query, Label: label, Mapping: opts.Mapping, Aliases: opts.Dialect.Aliases, Timezone: opts.Dialect.Timezone, Quality: opts.Quality}, nil
+}
+
+func (s *SQLSource) Name() string {
+	return s.Label
+}
+
+func (s *SQLSource) Events(ctx context.Context) ([]forecast.ReviewEvent, error) {
+	return collectEvents(ctx, s)
+}
+
+func (s *SQLSource) StreamEvents(ctx context.Context, fn func(forecast.ReviewEvent) error) error {
+	count := 0
+	screen := s.Quality.screen("events", s.Label)
+	location := Dialect{Timezone: s.Timezone}.location()
+	err := s.scan(ctx, eventColumns, func(row []string, idx map[string]int, rowIndex int) error {
+		event, err := parseRow(row, idx, location)
+		keep, err := screen.event(rowIndex, event, err)
+		if err != nil || !keep {
+			return err
+		}
+		count++
+		return fn(event)
+	})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("%s returned no review events", s.Label)
+	}
+	return nil
+}
+
+// QueueItems returns the pending queue. An empty result is an empty queue rather
+// than an error, since a live view can legitimately be drained.
+func (s *SQLSource) QueueItems(ctx context.Context) ([]forecast.QueueItem, error) {
+	items := []forecast.QueueItem{}
+	screen := s.Quality.screen("queue", s.Label)
+	location := Dialect{Timezone: s.Timezone}.location()
+	err := s.scan(ctx, queueColumns, func(row []string, idx map[string]int, rowIndex int) error {
+		item, err := parseQueueRow(row, idx, location)
+		keep, err := screen.queueItem(rowIndex, item, err)
+		if keep {
+			items = append(items, item)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// scan runs the query and hands each row, rendered as strings, to fn.
+func (s *SQLSource) scan(ctx context.Context, required []string, fn func(row []string, idx map[string]int, rowIndex int) error) error {
+	db, err := sql.Open("pgx", s.DSN)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, s.Query)
+	if err != nil {
+		return fmt.Errorf("%s: %w", s.Label, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	idx, err := buildIndex(columns, s.Mapping, s.Aliases)
+	if err != nil {
+		return fmt.Errorf("%s: %w", s.Label, invalidData(err))
+	}
+	if err := requireColumns(idx, required, "column"); err != nil {
+		return fmt.Errorf("%s: %w", s.Label, invalidData(err))
+	}
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	location, err := loadLocation(s.Timezone)
+	if err != nil {
+		return fmt.Errorf("invalid timezone: %w", err)
+	}
+	values := make([]any, len(columns))
+	targets := make([]any, len(columns))
+	for i := range values {
+		targets[i] = &values[i]
+	}
+	row := make([]string, len(columns))
+	for rowIndex := 1; rows.Next(); rowIndex++ {
+		if err := rows.Scan(targets...); err != nil {
+			return fmt.Errorf("row %d: %w", rowIndex, err)
+		}
+		for i, value := range values {
+			if naive, ok := value.(time.Time); ok && isNaiveTimeColumn(types[i]) {
+				value = wallClock(naive, location)
+			}
+			row[i] = sqlText(value)
+		}
+		if err := fn(row, idx, rowIndex); err != nil {
+			return fmt.Errorf("row %d: %w", rowIndex, err)
+		}
+	}
+	return rows.Err()
+}
+
+// sqlText renders a scanned column the way it would appear in a CSV export.
+func sqlText(value any) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	case []byte:
+		return string(typed)
+	case time.Time:
+		return typed.Format(time.RFC3339)
+	case int64:
+		return strconv.FormatInt(typed, 10)
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(typed)
+	default:
+		return fmt.Sprint(typed)
+	}
+}
+
+// isNaiveTimeColumn reports whether a column holds dates or timestamps without a
+// time zone, which the driver returns as UTC wall clock values.
+func isNaiveTimeColumn(column *sql.ColumnType) bool {
+	switch strings.ToUpper(column.DatabaseTypeName()) {
+	case "DATE", "TIMESTAMP":
+		return true
+	}
+	return false
+}
+
+// wallClock reinterprets value's wall clock reading in location.
+func wallClock(value time.Time, location *time.Location) time.Time {
+	year, month, day := value.Date()
+	hour, minute, second := value.Clock()
+	return time.Date(year, month, day, hour, minute, second, value.Nanosecond(), location)
+}
+
+// viewQuery selects every column from a table or view name, optionally
+// schema-qualified, quoting each part so the name cannot carry SQL.
+func viewQuery(name string) (string, error) {
+	if name == "" {
+		return "", errors.New("view source has no name")
+	}
+	parts := strings.Split(name, ".")
+	if len(parts) > 2 {
+		return "", fmt.Errorf("invalid view name %q (use view or schema.view)", name)
+	}
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return "", fmt.Errorf("invalid view name %q (use view or schema.view)", name)
+		}
+		quoted[i] = `"` + strings.ReplaceAll(part, `"`, `""`) + `"`
+	}
+	return "SELECT * FROM " + strings.Join(quoted, "."), nil
+}
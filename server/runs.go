@@ -0,0 +1,178 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"groupscholar-review-queue-forecaster/ingest"
+	"groupscholar-review-queue-forecaster/store"
+)
+
+type runResponse struct {
+	ID             int64           `json:"id"`
+	CreatedAt      time.Time       `json:"created_at"`
+	GeneratedAt    time.Time       `json:"generated_at"`
+	TotalEvents    int             `json:"total_events"`
+	SLADays        int             `json:"sla_days"`
+	ThroughputDays int             `json:"throughput_days"`
+	TotalPending   *int64          `json:"total_pending,omitempty"`
+	AssignedCount  *int64          `json:"assigned_count,omitempty"`
+	OverdueCount   *int64          `json:"overdue_count,omitempty"`
+	Profile        string          `json:"profile,omitempty"`
+	InputPath      string          `json:"input_path,omitempty"`
+	QueuePath      string          `json:"queue_path,omitempty"`
+	Report         json.RawMessage `json:"report,omitempty"`
+}
+
+func newRunResponse(run store.RunSummary) runResponse {
+	response := runResponse{
+		ID:             run.ID,
+		CreatedAt:      run.CreatedAt,
+		GeneratedAt:    run.GeneratedAt,
+		TotalEvents:    run.TotalEvents,
+		SLADays:        run.SLADays,
+		ThroughputDays: run.Throughput,
+		Profile:        run.Profile.String,
+	}
+	if run.QueuePending.Valid {
+		response.TotalPending = &run.QueuePending.Int64
+	}
+	if run.QueueAssigned.Valid {
+		response.AssignedCount = &run.QueueAssigned.Int64
+	}
+	if run.QueueOverdue.Valid {
+		response.OverdueCount = &run.QueueOverdue.Int64
+	}
+	return response
+}
+
+func newRunRecordResponse(run store.RunRecord) runResponse {
+	response := newRunResponse(run.RunSummary)
+	response.InputPath = run.InputPath.String
+	response.QueuePath = run.QueuePath.String
+	response.Report = json.RawMessage(run.ReportJSON)
+	return response
+}
+
+func (s *Server) requireStore(w http.ResponseWriter) bool {
+	if s.cfg.Store == nil {
+		writeError(w, http.StatusServiceUnavailable, "no database configured")
+		return false
+	}
+	return true
+}
+
+// handleListRuns serves GET /v1/runs?limit=&since=&until=&profile=.
+func (s *Server) handleListRuns(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStore(w) {
+		return
+	}
+	filter, err := runFilter(r.URL.Query(), s.cfg.Options.Calendar.Location)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	runs, err := s.cfg.Store.ListRuns(r.Context(), filter)
+	if err != nil {
+		s.storeError(w, err)
+		return
+	}
+	response := make([]runResponse, 0, len(runs))
+	for _, run := range runs {
+		response = append(response, newRunResponse(run))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"runs": response})
+}
+
+// runFilter reads the run listing's query. since and until are dates in the
+// server's calendar timezone, and a bare until date covers that whole day.
+func runFilter(query url.Values, location *time.Location) (store.RunFilter, error) {
+	filter := store.RunFilter{Limit: 20, Profile: query.Get("profile")}
+	if value := strings.TrimSpace(query.Get("limit")); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > 500 {
+			return store.RunFilter{}, errors.New("invalid limit: " + value + " (1-500)")
+		}
+		filter.Limit = limit
+	}
+	for _, bound := range []struct {
+		name   string
+		target *time.Time
+		parse  func(string, *time.Location) (time.Time, error)
+	}{{"since", &filter.Since, ingest.ParseDateIn}, {"until", &filter.Until, ingest.ParseUntilIn}} {
+		value := strings.TrimSpace(query.Get(bound.name))
+		if value == "" {
+			continue
+		}
+		parsed, err := bound.parse(value, location)
+		if err != nil {
+			return store.RunFilter{}, errors.New("invalid " + bound.name + ": " + value)
+		}
+		*bound.target = parsed
+	}
+	return filter, nil
+}
+
+func (s *Server) handleGetRun(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStore(w) {
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid run id: "+r.PathValue("id"))
+		return
+	}
+	run, err := s.cfg.Store.GetRun(r.Context(), id)
+	if err != nil {
+		s.storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newRunRecordResponse(run))
+}
+
+func (s *Server) handleLatestRun(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStore(w) {
+		return
+	}
+	run, err := s.cfg.Store.LatestRun(r.Context(), false)
+	if err != nil {
+		s.storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newRunRecordResponse(run))
+}
+
+// handleLatestQueue serves the queue forecast from the newest run that had one.
+func (s *Server) handleLatestQueue(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStore(w) {
+		return
+	}
+	run, err := s.cfg.Store.LatestRun(r.Context(), true)
+	if err != nil {
+		s.storeError(w, err)
+		return
+	}
+	var report struct {
+		Queue json.RawMessage `json:"queue"`
+	}
+	if err := json.Unmarshal(run.ReportJSON, &report); err != nil || len(report.Queue) == 0 {
+		writeError(w, http.StatusNotFound, "latest run has no queue forecast")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"run": newRunResponse(run.RunSummary), "queue": report.Queue})
+}
+
+func (s *Server) storeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	s.cfg.Logger.Printf("store: %v", err)
+	writeError(w, http.StatusInternalServerError, "database error")
+}
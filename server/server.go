@@ -0,0 +1,221 @@
+// Package server exposes report building and stored runs over HTTP.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"groupscholar-review-queue-forecaster/forecast"
+	"groupscholar-review-queue-forecaster/ingest"
+	"groupscholar-review-queue-forecaster/store"
+)
+
+const defaultMaxUploadBytes = 32 << 20
+
+// Caps on query overrides that scale the work a single request can ask for.
+const (
+	maxSimulations = 100000
+	maxPriorityTop = 1000
+)
+
+type Config struct {
+	// Options are the defaults for uploaded reports; query parameters override them.
+	Options forecast.Options
+	// Store serves and records runs. Stored-run endpoints return 503 when nil.
+	Store *store.Store
+	// Dialect parses uploaded files; the zero value reads standard CSV with
+	// timestamps lacking an offset in UTC.
+	Dialect ingest.Dialect
+	// MaxUploadBytes caps request bodies (default 32 MiB).
+	MaxUploadBytes int64
+	Logger         *log.Logger
+}
+
+type Server struct {
+	cfg Config
+	mux *http.ServeMux
+}
+
+func New(cfg Config) *Server {
+	if cfg.MaxUploadBytes <= 0 {
+		cfg.MaxUploadBytes = defaultMaxUploadBytes
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = log.Default()
+	}
+	s := &Server{cfg: cfg, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /healthz", s.handleHealth)
+	s.mux.HandleFunc("POST /v1/reports", s.handleReport)
+	s.mux.HandleFunc("POST /v1/forecasts", s.handleForecast)
+	s.mux.HandleFunc("GET /v1/runs", s.handleListRuns)
+	s.mux.HandleFunc("GET /v1/runs/latest", s.handleLatestRun)
+	s.mux.HandleFunc("GET /v1/runs/latest/queue", s.handleLatestQueue)
+	s.mux.HandleFunc("GET /v1/runs/{id}", s.handleGetRun)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	s.mux.ServeHTTP(recorder, r)
+	s.cfg.Logger.Printf("%s %s %d %s", r.Method, r.URL.Path, recorder.status, time.Since(start).Round(time.Millisecond))
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok", "store": s.cfg.Store != nil})
+}
+
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	report, ok := s.buildReport(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func (s *Server) handleForecast(w http.ResponseWriter, r *http.Request) {
+	report, ok := s.buildReport(w, r)
+	if !ok {
+		return
+	}
+	if report.Queue == nil {
+		writeError(w, http.StatusBadRequest, "forecast requires a queue upload")
+		return
+	}
+	writeJSON(w, http.StatusOK, report.Queue)
+}
+
+// buildReport parses the upload and query overrides, builds the report, and stores
+// it when ?store=true. It writes the error response itself and reports ok=false.
+func (s *Server) buildReport(w http.ResponseWriter, r *http.Request) (forecast.Report, bool) {
+	opts, err := s.requestOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return forecast.Report{}, false
+	}
+	storeRun, err := queryBool(r, "store")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return forecast.Report{}, false
+	}
+	if storeRun && s.cfg.Store == nil {
+		writeError(w, http.StatusServiceUnavailable, "no database configured")
+		return forecast.Report{}, false
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, s.cfg.MaxUploadBytes)
+	upload, status, err := readUpload(r, s.cfg.MaxUploadBytes, s.cfg.Dialect)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return forecast.Report{}, false
+	}
+
+	report, err := forecast.BuildReport(r.Context(), upload.events, upload.queue, opts)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return forecast.Report{}, false
+	}
+	if storeRun {
+		id, err := s.cfg.Store.SaveReport(r.Context(), report, upload.eventsName, upload.queueName)
+		if err != nil {
+			s.cfg.Logger.Printf("store report: %v", err)
+			writeError(w, http.StatusInternalServerError, "failed to store report")
+			return forecast.Report{}, false
+		}
+		w.Header().Set("X-Run-ID", strconv.FormatInt(id, 10))
+	}
+	return report, true
+}
+
+// requestOptions layers query parameter overrides on the server defaults.
+func (s *Server) requestOptions(r *http.Request) (forecast.Options, error) {
+	opts := s.cfg.Options
+	query := r.URL.Query()
+	ints := []struct {
+		name   string
+		target *int
+		// max bounds the value when set.
+		max int
+	}{
+		{"sla_days", &opts.Policy.Default.SLADays, 0},
+		{"throughput_days", &opts.ThroughputDays, 0},
+		{"target_clear_days", &opts.TargetClearDays, 0},
+		{"priority_top", &opts.PriorityTop, maxPriorityTop},
+		{"simulations", &opts.Simulations, maxSimulations},
+	}
+	for _, param := range ints {
+		value := strings.TrimSpace(query.Get(param.name))
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return forecast.Options{}, errors.New("invalid " + param.name + ": " + value)
+		}
+		if param.max > 0 && parsed > param.max {
+			return forecast.Options{}, errors.New("invalid " + param.name + ": " + value + " (at most " + strconv.Itoa(param.max) + ")")
+		}
+		*param.target = parsed
+	}
+	if value := strings.TrimSpace(query.Get("due_soon_ratio")); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 || parsed >= 1 {
+			return forecast.Options{}, errors.New("invalid due_soon_ratio: " + value)
+		}
+		opts.Policy.Default.DueSoonRatio = parsed
+	}
+	if value := strings.TrimSpace(query.Get("seed")); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return forecast.Options{}, errors.New("invalid seed: " + value)
+		}
+		opts.SimulationSeed = parsed
+	}
+	if value := strings.TrimSpace(query.Get("as_of")); value != "" {
+		parsed, err := ingest.ParseDateIn(value, opts.Calendar.Location)
+		if err != nil {
+			return forecast.Options{}, errors.New("invalid as_of: " + value)
+		}
+		opts.AsOf = parsed
+	}
+	return opts, nil
+}
+
+func queryBool(r *http.Request, name string) (bool, error) {
+	value := strings.TrimSpace(r.URL.Query().Get(name))
+	if value == "" {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.New("invalid " + name + ": " + value)
+	}
+	return parsed, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+const testEvents = `application_id,stage,submitted_at,reviewed_at,reviewer_id
+app-1,initial_review,2026-01-01,2026-01-04,rev-01
+app-2,initial_review,2026-01-02,2026-01-08,rev-02
+`
+
+func newTestServer() *Server {
+	return New(Config{Options: forecast.DefaultOptions(), Logger: log.New(io.Discard, "", 0)})
+}
+
+func TestForecastAcceptsMultipartCSVAndJSONQueue(t *testing.T) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("events", "events.csv")
+	part.Write([]byte(testEvents))
+	part, _ = writer.CreateFormFile("queue", "queue.json")
+	part.Write([]byte(`[{"application_id": "app-3", "stage": "initial_review", "submitted_at": "2026-01-05", "reviewer_id": "rev-01"}]`))
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/forecasts?target_clear_days=7", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	rec := httptest.NewRecorder()
+	newTestServer().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var queue forecast.QueueReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &queue); err != nil {
+		t.Fatalf("decode queue: %v", err)
+	}
+	if queue.TotalPending != 1 || queue.ClearancePlan == nil || queue.ClearancePlan.TargetDays != 7 {
+		t.Fatalf("unexpected queue forecast: %+v", queue)
+	}
+}
+
+func TestReportRejectsBadInputAndMissingStore(t *testing.T) {
+	srv := newTestServer()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/reports", strings.NewReader(`{"events": [{"application_id": "app-1"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "missing required field") {
+		t.Fatalf("expected 400 for incomplete record, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/reports?store=true", strings.NewReader(testEvents))
+	req.Header.Set("Content-Type", "text/csv")
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without a store, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/runs/latest/queue", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 for stored runs without a store, got %d", rec.Code)
+	}
+}
+
+func TestReportRejectsOverridesAboveTheirCaps(t *testing.T) {
+	srv := newTestServer()
+	for _, query := range []string{"simulations=1000000000", "priority_top=5000"} {
+		req := httptest.NewRequest(http.MethodPost, "/v1/forecasts?"+query, strings.NewReader(testEvents))
+		req.Header.Set("Content-Type", "text/csv")
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "at most") {
+			t.Fatalf("expected 400 for %s, got %d: %s", query, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestRunFilterReadsDatesInTheCalendarTimezone(t *testing.T) {
+	pacific, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	filter, err := runFilter(url.Values{"since": {"2026-03-01"}, "until": {"2026-03-31"}}, pacific)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 3, 1, 0, 0, 0, 0, pacific); !filter.Since.Equal(want) {
+		t.Fatalf("expected since at local midnight %s, got %s", want, filter.Since)
+	}
+	if want := time.Date(2026, 4, 1, 0, 0, 0, 0, pacific); !filter.Until.Equal(want) {
+		t.Fatalf("expected until to run through the local day to %s, got %s", want, filter.Until)
+	}
+	if _, err := runFilter(url.Values{"until": {"soon"}}, pacific); err == nil {
+		t.Fatal("expected an invalid until to fail")
+	}
+}
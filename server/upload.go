@@ -0,0 +1,126 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"groupscholar-review-queue-forecaster/forecast"
+	"groupscholar-review-queue-forecaster/ingest"
+)
+
+type upload struct {
+	events     []forecast.ReviewEvent
+	queue      []forecast.QueueItem
+	eventsName string
+	queueName  string
+}
+
+// readUpload accepts three request shapes:
+//   - multipart/form-data with an "events" file and optional "queue" file (CSV, or
+//     JSON when the part is named *.json or sent as application/json)
+//   - application/json with {"events": [...], "queue": [...]}
+//   - text/csv with the events CSV as the body
+//
+// Every part is parsed with the configured dialect; JSON uses its aliases and
+// timezone.
+func readUpload(r *http.Request, maxBytes int64, dialect ingest.Dialect) (upload, int, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return upload{}, http.StatusUnsupportedMediaType, errors.New("missing or invalid Content-Type")
+	}
+
+	var out upload
+	switch mediaType {
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(maxBytes); err != nil {
+			return upload{}, uploadStatus(err), fmt.Errorf("invalid multipart upload: %w", err)
+		}
+		events, header, err := r.FormFile("events")
+		if err != nil {
+			return upload{}, http.StatusBadRequest, errors.New("multipart upload requires an events file")
+		}
+		defer events.Close()
+		out.eventsName = header.Filename
+		if isJSONPart(header) {
+			out.events, err = dialect.ReadEventsJSON(events)
+		} else {
+			out.events, err = dialect.ReadEvents(events)
+		}
+		if err != nil {
+			return upload{}, http.StatusBadRequest, fmt.Errorf("invalid events: %w", err)
+		}
+
+		queue, header, err := r.FormFile("queue")
+		if errors.Is(err, http.ErrMissingFile) {
+			return out, http.StatusOK, nil
+		}
+		if err != nil {
+			return upload{}, http.StatusBadRequest, fmt.Errorf("invalid queue: %w", err)
+		}
+		defer queue.Close()
+		out.queueName = header.Filename
+		if isJSONPart(header) {
+			out.queue, err = dialect.ReadQueueJSON(queue)
+		} else {
+			out.queue, err = dialect.ReadQueue(queue)
+		}
+		if err != nil {
+			return upload{}, http.StatusBadRequest, fmt.Errorf("invalid queue: %w", err)
+		}
+	case "application/json":
+		var body struct {
+			Events json.RawMessage `json:"events"`
+			Queue  json.RawMessage `json:"queue"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			return upload{}, uploadStatus(err), fmt.Errorf("invalid JSON body: %w", err)
+		}
+		if len(body.Events) == 0 {
+			return upload{}, http.StatusBadRequest, errors.New("JSON body requires an events array")
+		}
+		out.eventsName = "upload.json"
+		out.events, err = dialect.ReadEventsJSON(bytes.NewReader(body.Events))
+		if err != nil {
+			return upload{}, http.StatusBadRequest, fmt.Errorf("invalid events: %w", err)
+		}
+		if len(body.Queue) > 0 && string(body.Queue) != "null" {
+			out.queueName = "upload.json"
+			out.queue, err = dialect.ReadQueueJSON(bytes.NewReader(body.Queue))
+			if err != nil {
+				return upload{}, http.StatusBadRequest, fmt.Errorf("invalid queue: %w", err)
+			}
+		}
+	case "text/csv":
+		out.eventsName = "upload.csv"
+		out.events, err = dialect.ReadEvents(r.Body)
+		if err != nil {
+			return upload{}, uploadStatus(err), fmt.Errorf("invalid events: %w", err)
+		}
+	default:
+		return upload{}, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported Content-Type %q (use multipart/form-data, application/json, or text/csv)", mediaType)
+	}
+	return out, http.StatusOK, nil
+}
+
+func isJSONPart(header *multipart.FileHeader) bool {
+	if strings.EqualFold(filepath.Ext(header.Filename), ".json") {
+		return true
+	}
+	mediaType, _, _ := mime.ParseMediaType(header.Header.Get("Content-Type"))
+	return mediaType == "application/json"
+}
+
+func uploadStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
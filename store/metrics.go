@@ -0,0 +1,220 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+const (
+	ScopeStage    = "stage"
+	ScopeReviewer = "reviewer"
+	ScopeQueue    = "queue"
+)
+
+// seriesMetrics maps each scope to the metric columns db series may chart.
+var seriesMetrics = map[string]map[string]string{
+	ScopeStage: columnSet("count", "average_days", "median_days", "p90_days", "max_days", "sla_breach_count",
+		"sla_breach_rate", "distinct_reviewers", "on_time", "at_risk", "overdue"),
+	ScopeReviewer: columnSet("count", "average_days", "median_days", "p90_days", "max_days", "sla_breach_count",
+		"sla_breach_rate", "throughput_per_week", "window_count"),
+	ScopeQueue: columnSet("pending_count", "avg_age_days", "overdue_count", "due_soon_count", "on_track_count",
+		"daily_throughput", "estimated_clear_days", "required_daily_throughput", "throughput_gap_daily"),
+}
+
+var scopeTables = map[string]struct{ table, key string }{
+	ScopeStage:    {"stage_metrics", "stage"},
+	ScopeReviewer: {"reviewer_metrics", "reviewer_id"},
+	ScopeQueue:    {"queue_stage_forecasts", "stage"},
+}
+
+func columnSet(columns ...string) map[string]string {
+	set := make(map[string]string, len(columns))
+	for _, column := range columns {
+		set[column] = column
+	}
+	return set
+}
+
+// SeriesMetrics lists the metrics available for a scope, sorted.
+func SeriesMetrics(scope string) []string {
+	names := make([]string, 0, len(seriesMetrics[scope]))
+	for name := range seriesMetrics[scope] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SeriesQuery selects a metric history. Key defaults to "overall" for stage and
+// queue scopes; Limit defaults to 20 runs; Since and Until bound generated_at,
+// with Until exclusive.
+type SeriesQuery struct {
+	Metric string
+	Scope  string
+	Key    string
+	Limit  int
+	Since  time.Time
+	Until  time.Time
+}
+
+type execer interface {
+	queryer
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// insertMetrics writes the per-stage, per-reviewer, and queue-stage rows for a run.
+func (s *Store) insertMetrics(ctx context.Context, db execer, runID int64, generatedAt time.Time, report forecast.Report) error {
+	stageQuery := fmt.Sprintf(`
+INSERT INTO %s (run_id, generated_at, stage, sla_days, count, average_days, median_days, p90_days, max_days,
+	sla_breach_count, sla_breach_rate, distinct_reviewers, on_time, at_risk, overdue, risk_tier)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+`, s.table("stage_metrics"))
+	overall := report.Overall
+	if overall.Stage == "" {
+		overall.Stage = "overall"
+	}
+	for _, stage := range append([]forecast.StageStats{overall}, report.Stages...) {
+		if _, err := db.ExecContext(ctx, stageQuery, runID, generatedAt, stage.Stage, stage.SLADays, stage.Count,
+			stage.AverageDays, stage.MedianDays, stage.P90Days, stage.MaxDays, stage.SLABreachCount, stage.SLABreachRate,
+			stage.DistinctReviewers, stage.AgingBuckets.OnTime, stage.AgingBuckets.AtRisk, stage.AgingBuckets.Overdue, stage.RiskTier); err != nil {
+			return fmt.Errorf("insert stage metrics: %w", err)
+		}
+	}
+
+	reviewerQuery := fmt.Sprintf(`
+INSERT INTO %s (run_id, generated_at, reviewer_id, count, average_days, median_days, p90_days, max_days,
+	sla_breach_count, sla_breach_rate, throughput_per_week, window_count, risk_tier)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+`, s.table("reviewer_metrics"))
+	for _, reviewer := range report.Reviewers {
+		if _, err := db.ExecContext(ctx, reviewerQuery, runID, generatedAt, reviewer.ReviewerID, reviewer.Count,
+			reviewer.AverageDays, reviewer.MedianDays, reviewer.P90Days, reviewer.MaxDays, reviewer.SLABreachCount,
+			reviewer.SLABreachRate, reviewer.ThroughputPerWeek, reviewer.WindowCount, reviewer.RiskTier); err != nil {
+			return fmt.Errorf("insert reviewer metrics: %w", err)
+		}
+	}
+
+	queue := report.Queue
+	if queue == nil {
+		return nil
+	}
+	queueQuery := fmt.Sprintf(`
+INSERT INTO %s (run_id, generated_at, as_of, stage, sla_days, pending_count, avg_age_days, overdue_count, due_soon_count,
+	on_track_count, daily_throughput, estimated_clear_days, clearance_status, required_daily_throughput,
+	throughput_gap_daily, capacity_status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+`, s.table("queue_stage_forecasts"))
+	var dailyThroughput, requiredDaily, gapDaily sql.NullFloat64
+	var clearanceStatus sql.NullString
+	if plan := queue.ClearancePlan; plan != nil {
+		dailyThroughput = sql.NullFloat64{Float64: plan.CurrentDaily, Valid: true}
+		requiredDaily = sql.NullFloat64{Float64: plan.RequiredDaily, Valid: true}
+		gapDaily = sql.NullFloat64{Float64: plan.GapDaily, Valid: true}
+		clearanceStatus = sql.NullString{String: plan.Status, Valid: true}
+	}
+	if _, err := db.ExecContext(ctx, queueQuery, runID, generatedAt, queue.AsOf, "overall", nil, queue.TotalPending,
+		queue.AvgAgeDays, queue.OverdueCount, queue.DueSoonCount, queue.OnTrackCount, dailyThroughput, nil,
+		clearanceStatus, requiredDaily, gapDaily, nil); err != nil {
+		return fmt.Errorf("insert queue stage forecasts: %w", err)
+	}
+	for _, stage := range queue.Stages {
+		if _, err := db.ExecContext(ctx, queueQuery, runID, generatedAt, queue.AsOf, stage.Stage, stage.SLADays,
+			stage.PendingCount, stage.AvgAgeDays, stage.OverdueCount, stage.DueSoonCount, stage.OnTrackCount,
+			stage.DailyThroughput, stage.EstimatedClearDays, stage.ClearanceStatus, stage.RequiredDailyThroughput,
+			stage.ThroughputGapDaily, stage.CapacityStatus); err != nil {
+			return fmt.Errorf("insert queue stage forecasts: %w", err)
+		}
+	}
+	return nil
+}
+
+// Validate checks the scope and metric and fills the default key and limit.
+func (q *SeriesQuery) Validate() error {
+	q.Scope = strings.TrimSpace(q.Scope)
+	if q.Scope == "" {
+		q.Scope = ScopeStage
+	}
+	if _, ok := scopeTables[q.Scope]; !ok {
+		return fmt.Errorf("unknown series scope %q (use stage, reviewer, or queue)", q.Scope)
+	}
+	metric := strings.ReplaceAll(strings.TrimSpace(q.Metric), "-", "_")
+	if _, ok := seriesMetrics[q.Scope][metric]; !ok {
+		return fmt.Errorf("unknown %s metric %q (available: %s)", q.Scope, q.Metric, strings.Join(SeriesMetrics(q.Scope), ", "))
+	}
+	q.Metric = metric
+	q.Key = strings.TrimSpace(q.Key)
+	if q.Key == "" {
+		if q.Scope == ScopeReviewer {
+			return fmt.Errorf("reviewer series requires a reviewer id")
+		}
+		q.Key = "overall"
+	}
+	if q.Limit <= 0 {
+		q.Limit = 20
+	}
+	return nil
+}
+
+// MetricSeries returns the most recent values of one metric, oldest first.
+func (s *Store) MetricSeries(ctx context.Context, query SeriesQuery) (forecast.MetricSeries, error) {
+	if err := query.Validate(); err != nil {
+		return forecast.MetricSeries{}, err
+	}
+	source := scopeTables[query.Scope]
+	column := seriesMetrics[query.Scope][query.Metric]
+	key := query.Key
+
+	args := []any{key}
+	conditions := []string{fmt.Sprintf("%s = $1", source.key)}
+	if !query.Since.IsZero() {
+		args = append(args, query.Since)
+		conditions = append(conditions, fmt.Sprintf("generated_at >= $%d", len(args)))
+	}
+	if !query.Until.IsZero() {
+		args = append(args, query.Until)
+		conditions = append(conditions, fmt.Sprintf("generated_at < $%d", len(args)))
+	}
+	args = append(args, query.Limit)
+	statement := fmt.Sprintf(`
+SELECT run_id, generated_at, %s::DOUBLE PRECISION
+FROM %s
+WHERE %s
+ORDER BY generated_at DESC, run_id DESC
+LIMIT $%d
+`, column, s.table(source.table), strings.Join(conditions, " AND "), len(args))
+
+	rows, err := s.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return forecast.MetricSeries{}, err
+	}
+	defer rows.Close()
+
+	series := forecast.MetricSeries{Metric: query.Metric, Scope: query.Scope, Key: key, Points: []forecast.MetricPoint{}}
+	for rows.Next() {
+		var point forecast.MetricPoint
+		var generatedAt time.Time
+		var value sql.NullFloat64
+		if err := rows.Scan(&point.RunID, &generatedAt, &value); err != nil {
+			return forecast.MetricSeries{}, err
+		}
+		point.GeneratedAt = generatedAt.UTC().Format(time.RFC3339)
+		if value.Valid {
+			v := value.Float64
+			point.Value = &v
+		}
+		series.Points = append(series.Points, point)
+	}
+	if err := rows.Err(); err != nil {
+		return forecast.MetricSeries{}, err
+	}
+	for i, j := 0, len(series.Points)-1; i < j; i, j = i+1, j-1 {
+		series.Points[i], series.Points[j] = series.Points[j], series.Points[i]
+	}
+	return series, nil
+}
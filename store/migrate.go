@@ -0,0 +1,226 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// ErrMigrationsPending is returned by Open when the database schema is behind the
+// embedded migrations.
+var ErrMigrationsPending = errors.New("database schema has pending migrations")
+
+// Migration is one embedded schema change. Files are named NNNN_name.sql and use
+// {{schema}} wherever the configured schema belongs.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// MigrationStatus reports whether a migration has been applied to the database.
+type MigrationStatus struct {
+	Migration
+	AppliedAt sql.NullTime
+}
+
+// Migrations returns the embedded migrations in version order.
+func Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	migrations := make([]Migration, 0, len(entries))
+	seen := map[int]string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		prefix, label, ok := strings.Cut(strings.TrimSuffix(name, ".sql"), "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: name must look like 0001_name.sql", name)
+		}
+		if previous, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migration %s: version %d already used by %s", name, version, previous)
+		}
+		seen[version] = name
+		payload, err := migrationFiles.ReadFile("migrations/" + name)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{Version: version, Name: label, SQL: string(payload)})
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Migrate applies pending migrations in order and returns the ones it applied.
+// A session advisory lock keyed on the schema serializes concurrent migrators;
+// each migration and its schema_version row commit in one transaction.
+func (s *Store) Migrate(ctx context.Context) ([]Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	lockKey := s.migrationLockKey()
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return nil, fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey)
+
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", pqQuoteIdentifier(s.schema))); err != nil {
+		return nil, err
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %s (
+	version INT PRIMARY KEY,
+	name TEXT NOT NULL,
+	applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+)`, s.table("schema_version"))); err != nil {
+		return nil, err
+	}
+
+	current, err := s.currentVersion(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	var applied []Migration
+	for _, migration := range migrations {
+		if migration.Version <= current {
+			continue
+		}
+		if err := s.applyMigration(ctx, conn, migration); err != nil {
+			return applied, fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		applied = append(applied, migration)
+	}
+	return applied, nil
+}
+
+func (s *Store) applyMigration(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	statement := strings.ReplaceAll(migration.SQL, "{{schema}}", pqQuoteIdentifier(s.schema))
+	if _, err := tx.ExecContext(ctx, statement); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (version, name) VALUES ($1, $2)", s.table("schema_version")), migration.Version, migration.Name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MigrationStatus lists every embedded migration with its applied time, if any.
+func (s *Store) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	applied := map[int]time.Time{}
+	exists, err := s.versionTableExists(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		rows, err := s.db.QueryContext(ctx, fmt.Sprintf("SELECT version, applied_at FROM %s", s.table("schema_version")))
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var version int
+			var appliedAt time.Time
+			if err := rows.Scan(&version, &appliedAt); err != nil {
+				return nil, err
+			}
+			applied[version] = appliedAt
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{Migration: migration}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.AppliedAt = sql.NullTime{Time: appliedAt, Valid: true}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// checkVersion fails with ErrMigrationsPending unless every embedded migration
+// has been applied. It only reads schema_version, so opening a store never runs
+// DDL.
+func (s *Store) checkVersion(ctx context.Context) error {
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+	current, err := s.currentVersion(ctx, s.db)
+	if err != nil {
+		return err
+	}
+	latest := 0
+	if len(migrations) > 0 {
+		latest = migrations[len(migrations)-1].Version
+	}
+	if current < latest {
+		return fmt.Errorf("%w: schema %s is at version %d, want %d (run `db migrate up`)", ErrMigrationsPending, s.schema, current, latest)
+	}
+	return nil
+}
+
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (s *Store) currentVersion(ctx context.Context, db queryer) (int, error) {
+	exists, err := s.versionTableExists(ctx, db)
+	if err != nil || !exists {
+		return 0, err
+	}
+	var version int
+	err = db.QueryRowContext(ctx, fmt.Sprintf("SELECT COALESCE(MAX(version), 0) FROM %s", s.table("schema_version"))).Scan(&version)
+	return version, err
+}
+
+func (s *Store) versionTableExists(ctx context.Context, db queryer) (bool, error) {
+	var name sql.NullString
+	if err := db.QueryRowContext(ctx, "SELECT to_regclass($1)::TEXT", s.table("schema_version")).Scan(&name); err != nil {
+		return false, err
+	}
+	return name.Valid, nil
+}
+
+// migrationLockKey derives the advisory lock id from the schema so migrators for
+// different schemas in one database do not block each other.
+func (s *Store) migrationLockKey() int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte("review-queue-forecaster:" + s.schema))
+	return int64(hash.Sum64())
+}
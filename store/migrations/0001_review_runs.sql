@@ -0,0 +1,15 @@
+-- Runs table as originally created by ensureRunsTable; IF NOT EXISTS lets
+-- databases created before migrations adopt the versioned history.
+CREATE TABLE IF NOT EXISTS {{schema}}.review_runs (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	generated_at TIMESTAMPTZ NOT NULL,
+	input_path TEXT,
+	queue_path TEXT,
+	sla_days INT NOT NULL,
+	throughput_days INT NOT NULL,
+	total_events INT NOT NULL,
+	report JSONB NOT NULL,
+	queue_summary JSONB
+);
+CREATE INDEX IF NOT EXISTS review_runs_created_at_idx ON {{schema}}.review_runs (created_at DESC);
@@ -0,0 +1,2 @@
+-- Resolved flag values and their sources for each run.
+ALTER TABLE {{schema}}.review_runs ADD COLUMN IF NOT EXISTS run_config JSONB;
@@ -0,0 +1,115 @@
+-- Per-run rows for charting stage, reviewer, and queue history without JSON
+-- path queries. "overall" rows carry the report-wide figures.
+CREATE TABLE IF NOT EXISTS {{schema}}.stage_metrics (
+	run_id BIGINT NOT NULL REFERENCES {{schema}}.review_runs (id) ON DELETE CASCADE,
+	generated_at TIMESTAMPTZ NOT NULL,
+	stage TEXT NOT NULL,
+	sla_days INT,
+	count INT NOT NULL,
+	average_days DOUBLE PRECISION,
+	median_days DOUBLE PRECISION,
+	p90_days DOUBLE PRECISION,
+	max_days DOUBLE PRECISION,
+	sla_breach_count INT,
+	sla_breach_rate DOUBLE PRECISION,
+	distinct_reviewers INT,
+	on_time INT,
+	at_risk INT,
+	overdue INT,
+	risk_tier TEXT,
+	PRIMARY KEY (run_id, stage)
+);
+CREATE INDEX IF NOT EXISTS stage_metrics_stage_idx ON {{schema}}.stage_metrics (stage, generated_at DESC);
+
+CREATE TABLE IF NOT EXISTS {{schema}}.reviewer_metrics (
+	run_id BIGINT NOT NULL REFERENCES {{schema}}.review_runs (id) ON DELETE CASCADE,
+	generated_at TIMESTAMPTZ NOT NULL,
+	reviewer_id TEXT NOT NULL,
+	count INT NOT NULL,
+	average_days DOUBLE PRECISION,
+	median_days DOUBLE PRECISION,
+	p90_days DOUBLE PRECISION,
+	max_days DOUBLE PRECISION,
+	sla_breach_count INT,
+	sla_breach_rate DOUBLE PRECISION,
+	throughput_per_week DOUBLE PRECISION,
+	window_count INT,
+	risk_tier TEXT,
+	PRIMARY KEY (run_id, reviewer_id)
+);
+CREATE INDEX IF NOT EXISTS reviewer_metrics_reviewer_idx ON {{schema}}.reviewer_metrics (reviewer_id, generated_at DESC);
+
+CREATE TABLE IF NOT EXISTS {{schema}}.queue_stage_forecasts (
+	run_id BIGINT NOT NULL REFERENCES {{schema}}.review_runs (id) ON DELETE CASCADE,
+	generated_at TIMESTAMPTZ NOT NULL,
+	as_of TEXT,
+	stage TEXT NOT NULL,
+	sla_days INT,
+	pending_count INT NOT NULL,
+	avg_age_days DOUBLE PRECISION,
+	overdue_count INT,
+	due_soon_count INT,
+	on_track_count INT,
+	daily_throughput DOUBLE PRECISION,
+	estimated_clear_days DOUBLE PRECISION,
+	clearance_status TEXT,
+	required_daily_throughput DOUBLE PRECISION,
+	throughput_gap_daily DOUBLE PRECISION,
+	capacity_status TEXT,
+	PRIMARY KEY (run_id, stage)
+);
+CREATE INDEX IF NOT EXISTS queue_stage_forecasts_stage_idx ON {{schema}}.queue_stage_forecasts (stage, generated_at DESC);
+
+-- Backfill runs stored before these tables existed.
+INSERT INTO {{schema}}.stage_metrics (run_id, generated_at, stage, sla_days, count, average_days, median_days, p90_days, max_days,
+	sla_breach_count, sla_breach_rate, distinct_reviewers, on_time, at_risk, overdue, risk_tier)
+SELECT r.id, r.generated_at, COALESCE(NULLIF(s.value->>'stage', ''), 'overall'), (s.value->>'sla_days')::INT, COALESCE((s.value->>'count')::INT, 0),
+	(s.value->>'average_days')::DOUBLE PRECISION, (s.value->>'median_days')::DOUBLE PRECISION,
+	(s.value->>'p90_days')::DOUBLE PRECISION, (s.value->>'max_days')::DOUBLE PRECISION,
+	(s.value->>'sla_breach_count')::INT, (s.value->>'sla_breach_rate')::DOUBLE PRECISION, (s.value->>'distinct_reviewers')::INT,
+	(s.value->'aging_buckets'->>'on_time')::INT, (s.value->'aging_buckets'->>'at_risk')::INT, (s.value->'aging_buckets'->>'overdue')::INT,
+	s.value->>'risk_tier'
+FROM {{schema}}.review_runs r
+CROSS JOIN LATERAL (
+	SELECT r.report->'overall' AS value
+	UNION ALL
+	SELECT value FROM jsonb_array_elements(COALESCE(r.report->'stages', '[]'::JSONB))
+) s
+WHERE s.value IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO {{schema}}.reviewer_metrics (run_id, generated_at, reviewer_id, count, average_days, median_days, p90_days, max_days,
+	sla_breach_count, sla_breach_rate, throughput_per_week, window_count, risk_tier)
+SELECT r.id, r.generated_at, v.value->>'reviewer_id', COALESCE((v.value->>'count')::INT, 0),
+	(v.value->>'average_days')::DOUBLE PRECISION, (v.value->>'median_days')::DOUBLE PRECISION,
+	(v.value->>'p90_days')::DOUBLE PRECISION, (v.value->>'max_days')::DOUBLE PRECISION,
+	(v.value->>'sla_breach_count')::INT, (v.value->>'sla_breach_rate')::DOUBLE PRECISION,
+	(v.value->>'throughput_per_week')::DOUBLE PRECISION, (v.value->>'window_count')::INT, v.value->>'risk_tier'
+FROM {{schema}}.review_runs r
+CROSS JOIN LATERAL jsonb_array_elements(COALESCE(r.report->'reviewers', '[]'::JSONB)) v
+WHERE v.value->>'reviewer_id' IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO {{schema}}.queue_stage_forecasts (run_id, generated_at, as_of, stage, sla_days, pending_count, avg_age_days, overdue_count,
+	due_soon_count, on_track_count, daily_throughput, estimated_clear_days, clearance_status, required_daily_throughput,
+	throughput_gap_daily, capacity_status)
+SELECT r.id, r.generated_at, r.report->'queue'->>'as_of', q.value->>'stage', (q.value->>'sla_days')::INT,
+	COALESCE((q.value->>'pending_count')::INT, 0), (q.value->>'avg_age_days')::DOUBLE PRECISION, (q.value->>'overdue_count')::INT,
+	(q.value->>'due_soon_count')::INT, (q.value->>'on_track_count')::INT, (q.value->>'daily_throughput')::DOUBLE PRECISION,
+	(q.value->>'estimated_clear_days')::DOUBLE PRECISION, q.value->>'clearance_status',
+	(q.value->>'required_daily_throughput')::DOUBLE PRECISION, (q.value->>'throughput_gap_daily')::DOUBLE PRECISION, q.value->>'capacity_status'
+FROM {{schema}}.review_runs r
+CROSS JOIN LATERAL jsonb_array_elements(COALESCE(r.report->'queue'->'stages', '[]'::JSONB)) q
+WHERE q.value->>'stage' IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO {{schema}}.queue_stage_forecasts (run_id, generated_at, as_of, stage, pending_count, avg_age_days, overdue_count,
+	due_soon_count, on_track_count, daily_throughput, clearance_status, required_daily_throughput, throughput_gap_daily)
+SELECT r.id, r.generated_at, r.report->'queue'->>'as_of', 'overall', COALESCE((r.report->'queue'->>'total_pending')::INT, 0),
+	(r.report->'queue'->>'avg_age_days')::DOUBLE PRECISION, (r.report->'queue'->>'overdue_count')::INT,
+	(r.report->'queue'->>'due_soon_count')::INT, (r.report->'queue'->>'on_track_count')::INT,
+	(r.report->'queue'->'clearance_plan'->>'current_daily')::DOUBLE PRECISION, r.report->'queue'->'clearance_plan'->>'status',
+	(r.report->'queue'->'clearance_plan'->>'required_daily')::DOUBLE PRECISION, (r.report->'queue'->'clearance_plan'->>'gap_daily')::DOUBLE PRECISION
+FROM {{schema}}.review_runs r
+WHERE jsonb_typeof(r.report->'queue') = 'object'
+ON CONFLICT DO NOTHING;
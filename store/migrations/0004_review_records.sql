@@ -0,0 +1,40 @@
+-- Raw review events and pending queue items behind each report, upserted on
+-- application_id + stage + submitted_at so re-ingesting a file is a no-op.
+CREATE TABLE IF NOT EXISTS {{schema}}.review_events (
+	application_id TEXT NOT NULL,
+	stage TEXT NOT NULL,
+	submitted_at TIMESTAMPTZ NOT NULL,
+	reviewed_at TIMESTAMPTZ NOT NULL,
+	reviewer_id TEXT NOT NULL,
+	program TEXT,
+	priority TEXT,
+	source TEXT,
+	ingested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (application_id, stage, submitted_at)
+);
+CREATE INDEX IF NOT EXISTS review_events_reviewed_at_idx ON {{schema}}.review_events (reviewed_at);
+
+-- Each ingested queue file is a capture; an item belongs to a capture when the
+-- capture falls between the item's first and last sighting.
+CREATE TABLE IF NOT EXISTS {{schema}}.queue_captures (
+	captured_at TIMESTAMPTZ PRIMARY KEY,
+	source TEXT,
+	item_count INT NOT NULL,
+	recorded_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS {{schema}}.queue_snapshots (
+	application_id TEXT NOT NULL,
+	stage TEXT NOT NULL,
+	submitted_at TIMESTAMPTZ NOT NULL,
+	reviewer_id TEXT,
+	program TEXT,
+	priority TEXT,
+	first_seen_at TIMESTAMPTZ NOT NULL,
+	last_seen_at TIMESTAMPTZ NOT NULL,
+	source TEXT,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (application_id, stage, submitted_at)
+);
+CREATE INDEX IF NOT EXISTS queue_snapshots_seen_idx ON {{schema}}.queue_snapshots (first_seen_at, last_seen_at);
@@ -0,0 +1,229 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+// UpsertResult counts how ingestion changed the stored rows.
+type UpsertResult struct {
+	Inserted  int
+	Updated   int
+	Unchanged int
+}
+
+// RecordRange bounds stored review events by reviewed_at, from Since up to but
+// not including Until. Zero values are open.
+type RecordRange struct {
+	Since time.Time
+	Until time.Time
+}
+
+// UpsertEvents stores review events keyed on application_id, stage, and
+// submitted_at. Rows that already match are left untouched, so ingesting the
+// same file twice reports every row as unchanged.
+func (s *Store) UpsertEvents(ctx context.Context, events []forecast.ReviewEvent, source string) (UpsertResult, error) {
+	query := fmt.Sprintf(`
+INSERT INTO %s AS e (application_id, stage, submitted_at, reviewed_at, reviewer_id, program, priority, source)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (application_id, stage, submitted_at) DO UPDATE SET
+	reviewed_at = EXCLUDED.reviewed_at,
+	reviewer_id = EXCLUDED.reviewer_id,
+	program = EXCLUDED.program,
+	priority = EXCLUDED.priority,
+	source = EXCLUDED.source,
+	updated_at = NOW()
+WHERE (e.reviewed_at, e.reviewer_id, e.program, e.priority)
+	IS DISTINCT FROM (EXCLUDED.reviewed_at, EXCLUDED.reviewer_id, EXCLUDED.program, EXCLUDED.priority)
+RETURNING (xmax = 0)
+`, s.table("review_events"))
+
+	return s.upsertRows(ctx, query, len(events), func(i int) []any {
+		event := events[i]
+		return []any{event.ApplicationID, event.Stage, event.SubmittedAt, event.ReviewedAt, event.ReviewerID,
+			nullString(event.Program), nullString(event.Priority), nullString(source)}
+	})
+}
+
+// UpsertQueueSnapshot records a capture of the pending queue taken at capturedAt.
+// Items seen before widen their first/last sighting window instead of
+// duplicating.
+func (s *Store) UpsertQueueSnapshot(ctx context.Context, items []forecast.QueueItem, capturedAt time.Time, source string) (UpsertResult, error) {
+	query := fmt.Sprintf(`
+INSERT INTO %s AS q (application_id, stage, submitted_at, reviewer_id, program, priority, first_seen_at, last_seen_at, source)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $7, $8)
+ON CONFLICT (application_id, stage, submitted_at) DO UPDATE SET
+	reviewer_id = CASE WHEN EXCLUDED.last_seen_at >= q.last_seen_at THEN EXCLUDED.reviewer_id ELSE q.reviewer_id END,
+	program = CASE WHEN EXCLUDED.last_seen_at >= q.last_seen_at THEN EXCLUDED.program ELSE q.program END,
+	priority = CASE WHEN EXCLUDED.last_seen_at >= q.last_seen_at THEN EXCLUDED.priority ELSE q.priority END,
+	first_seen_at = LEAST(q.first_seen_at, EXCLUDED.first_seen_at),
+	last_seen_at = GREATEST(q.last_seen_at, EXCLUDED.last_seen_at),
+	source = EXCLUDED.source,
+	updated_at = NOW()
+WHERE (EXCLUDED.last_seen_at >= q.last_seen_at
+		AND (q.reviewer_id, q.program, q.priority) IS DISTINCT FROM (EXCLUDED.reviewer_id, EXCLUDED.program, EXCLUDED.priority))
+	OR EXCLUDED.first_seen_at < q.first_seen_at
+	OR EXCLUDED.last_seen_at > q.last_seen_at
+RETURNING (xmax = 0)
+`, s.table("queue_snapshots"))
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return UpsertResult{}, err
+	}
+	defer tx.Rollback()
+
+	captureQuery := fmt.Sprintf(`
+INSERT INTO %s (captured_at, source, item_count)
+VALUES ($1, $2, $3)
+ON CONFLICT (captured_at) DO UPDATE SET source = EXCLUDED.source, item_count = EXCLUDED.item_count
+`, s.table("queue_captures"))
+	if _, err := tx.ExecContext(ctx, captureQuery, capturedAt, nullString(source), len(items)); err != nil {
+		return UpsertResult{}, err
+	}
+	result, err := upsertRowsTx(ctx, tx, query, len(items), func(i int) []any {
+		item := items[i]
+		return []any{item.ApplicationID, item.Stage, item.SubmittedAt, nullString(item.ReviewerID),
+			nullString(item.Program), nullString(item.Priority), capturedAt, nullString(source)}
+	})
+	if err != nil {
+		return UpsertResult{}, err
+	}
+	return result, tx.Commit()
+}
+
+func (s *Store) upsertRows(ctx context.Context, query string, count int, args func(i int) []any) (UpsertResult, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return UpsertResult{}, err
+	}
+	defer tx.Rollback()
+
+	result, err := upsertRowsTx(ctx, tx, query, count, args)
+	if err != nil {
+		return UpsertResult{}, err
+	}
+	return result, tx.Commit()
+}
+
+// upsertRowsTx runs an INSERT ... ON CONFLICT ... RETURNING (xmax = 0) statement
+// per row. No returned row means the conflict's WHERE clause skipped the update.
+func upsertRowsTx(ctx context.Context, tx *sql.Tx, query string, count int, args func(i int) []any) (UpsertResult, error) {
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return UpsertResult{}, err
+	}
+	defer stmt.Close()
+
+	var result UpsertResult
+	for i := 0; i < count; i++ {
+		var inserted bool
+		err := stmt.QueryRowContext(ctx, args(i)...).Scan(&inserted)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			result.Unchanged++
+		case err != nil:
+			return UpsertResult{}, fmt.Errorf("row %d: %w", i+1, err)
+		case inserted:
+			result.Inserted++
+		default:
+			result.Updated++
+		}
+	}
+	return result, nil
+}
+
+// LoadEvents returns stored review events reviewed within the range.
+func (s *Store) LoadEvents(ctx context.Context, r RecordRange) ([]forecast.ReviewEvent, error) {
+	var conditions []string
+	var args []any
+	if !r.Since.IsZero() {
+		args = append(args, r.Since)
+		conditions = append(conditions, fmt.Sprintf("reviewed_at >= $%d", len(args)))
+	}
+	if !r.Until.IsZero() {
+		args = append(args, r.Until)
+		conditions = append(conditions, fmt.Sprintf("reviewed_at < $%d", len(args)))
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	query := fmt.Sprintf(`
+SELECT application_id, stage, submitted_at, reviewed_at, reviewer_id, COALESCE(program, ''), COALESCE(priority, '')
+FROM %s
+%s
+ORDER BY reviewed_at, application_id, stage
+`, s.table("review_events"), where)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []forecast.ReviewEvent
+	for rows.Next() {
+		var event forecast.ReviewEvent
+		if err := rows.Scan(&event.ApplicationID, &event.Stage, &event.SubmittedAt, &event.ReviewedAt, &event.ReviewerID, &event.Program, &event.Priority); err != nil {
+			return nil, err
+		}
+		event.SubmittedAt = event.SubmittedAt.UTC()
+		event.ReviewedAt = event.ReviewedAt.UTC()
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// LoadQueueSnapshot returns the queue as captured most recently at or before
+// asOf (or the latest capture when asOf is zero), along with the capture time.
+// It returns no items and a zero time when nothing has been captured.
+func (s *Store) LoadQueueSnapshot(ctx context.Context, asOf time.Time) ([]forecast.QueueItem, time.Time, error) {
+	var capturedAt sql.NullTime
+	captureQuery := fmt.Sprintf("SELECT MAX(captured_at) FROM %s", s.table("queue_captures"))
+	var args []any
+	if !asOf.IsZero() {
+		captureQuery += " WHERE captured_at <= $1"
+		args = append(args, asOf)
+	}
+	if err := s.db.QueryRowContext(ctx, captureQuery, args...).Scan(&capturedAt); err != nil {
+		return nil, time.Time{}, err
+	}
+	if !capturedAt.Valid {
+		return nil, time.Time{}, nil
+	}
+
+	query := fmt.Sprintf(`
+SELECT application_id, stage, submitted_at, COALESCE(reviewer_id, ''), COALESCE(program, ''), COALESCE(priority, '')
+FROM %s
+WHERE first_seen_at <= $1 AND last_seen_at >= $1
+ORDER BY submitted_at, application_id, stage
+`, s.table("queue_snapshots"))
+	rows, err := s.db.QueryContext(ctx, query, capturedAt.Time)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer rows.Close()
+
+	var items []forecast.QueueItem
+	for rows.Next() {
+		var item forecast.QueueItem
+		if err := rows.Scan(&item.ApplicationID, &item.Stage, &item.SubmittedAt, &item.ReviewerID, &item.Program, &item.Priority); err != nil {
+			return nil, time.Time{}, err
+		}
+		item.SubmittedAt = item.SubmittedAt.UTC()
+		items = append(items, item)
+	}
+	return items, capturedAt.Time.UTC(), rows.Err()
+}
+
+func nullString(value string) sql.NullString {
+	value = strings.TrimSpace(value)
+	return sql.NullString{String: value, Valid: value != ""}
+}
@@ -0,0 +1,444 @@
+// Package store persists report runs to Postgres.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+// DefaultSchema is used when no schema is configured.
+const DefaultSchema = "gs_review_queue_forecaster"
+
+// ErrNotFound is returned when a requested run does not exist.
+var ErrNotFound = errors.New("run not found")
+
+type Config struct {
+	DSN    string
+	Schema string
+	// SkipVersionCheck opens the store even when migrations are pending, for
+	// callers that run or report on migrations.
+	SkipVersionCheck bool
+}
+
+type RunInsert struct {
+	GeneratedAt    time.Time
+	InputPath      string
+	QueuePath      string
+	SLADays        int
+	ThroughputDays int
+	TotalEvents    int
+	ReportJSON     []byte
+	QueueJSON      []byte
+	ConfigJSON     []byte
+}
+
+type RunSummary struct {
+	ID            int64
+	CreatedAt     time.Time
+	GeneratedAt   time.Time
+	TotalEvents   int
+	SLADays       int
+	Throughput    int
+	QueuePending  sql.NullInt64
+	QueueAssigned sql.NullInt64
+	QueueOverdue  sql.NullInt64
+	Profile       sql.NullString
+}
+
+type RunRecord struct {
+	RunSummary
+	InputPath  sql.NullString
+	QueuePath  sql.NullString
+	ReportJSON []byte
+}
+
+// Report decodes the stored report snapshot.
+func (r RunRecord) Report() (forecast.Report, error) {
+	var report forecast.Report
+	if err := json.Unmarshal(r.ReportJSON, &report); err != nil {
+		return forecast.Report{}, fmt.Errorf("run %d: decode report: %w", r.ID, err)
+	}
+	return report, nil
+}
+
+// RunFilter narrows ListRuns. Zero values are ignored; Limit defaults to 5.
+type RunFilter struct {
+	Limit   int
+	Since   time.Time
+	Until   time.Time
+	Profile string
+}
+
+// Store reads and writes runs in a single Postgres schema.
+type Store struct {
+	db     *sql.DB
+	schema string
+}
+
+// ResolveConfig picks the DSN from the flag value or the
+// GS_REVIEW_QUEUE_FORECASTER_DB_URL, GS_REVIEW_QUEUE_DB_URL, and DATABASE_URL
+// environment variables, in that order.
+func ResolveConfig(dsnFlag string, schema string) (Config, error) {
+	dsn := strings.TrimSpace(dsnFlag)
+	if dsn == "" {
+		dsn = strings.TrimSpace(os.Getenv("GS_REVIEW_QUEUE_FORECASTER_DB_URL"))
+	}
+	if dsn == "" {
+		dsn = strings.TrimSpace(os.Getenv("GS_REVIEW_QUEUE_DB_URL"))
+	}
+	if dsn == "" {
+		dsn = strings.TrimSpace(os.Getenv("DATABASE_URL"))
+	}
+	if dsn == "" {
+		return Config{}, errors.New("database DSN missing: set --db-url, GS_REVIEW_QUEUE_FORECASTER_DB_URL, GS_REVIEW_QUEUE_DB_URL, or DATABASE_URL")
+	}
+	if strings.TrimSpace(schema) == "" {
+		schema = DefaultSchema
+	}
+	return Config{DSN: dsn, Schema: schema}, nil
+}
+
+// Open connects to Postgres and, unless cfg.SkipVersionCheck is set, fails with
+// ErrMigrationsPending when the schema is behind the embedded migrations.
+func Open(ctx context.Context, cfg Config) (*Store, error) {
+	if strings.TrimSpace(cfg.Schema) == "" {
+		cfg.Schema = DefaultSchema
+	}
+	db, err := sql.Open("pgx", cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := db.PingContext(pingCtx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	store := &Store{db: db, schema: cfg.Schema}
+	if !cfg.SkipVersionCheck {
+		if err := store.checkVersion(ctx); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) Schema() string {
+	return s.schema
+}
+
+func (s *Store) table(name string) string {
+	return pqQuoteIdentifier(s.schema) + "." + name
+}
+
+// Seed stores a sample run when the runs table is empty and reports whether it did.
+func (s *Store) Seed(ctx context.Context) (bool, error) {
+	var count int
+	row := s.db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", s.table("review_runs")))
+	if err := row.Scan(&count); err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	seedNow := time.Now().AddDate(0, 0, -1)
+	seedReport := forecast.Report{
+		GeneratedAt: seedNow.Format(time.RFC3339),
+		TotalEvents: 48,
+		Overall: forecast.StageStats{
+			Stage:             "overall",
+			Count:             48,
+			AverageDays:       6.4,
+			MedianDays:        6.0,
+			P90Days:           11.2,
+			MaxDays:           14.5,
+			SLABreachCount:    8,
+			SLABreachRate:     16.7,
+			DistinctReviewers: 9,
+			AgingBuckets:      forecast.AgingBuckets{OnTime: 36, AtRisk: 9, Overdue: 3},
+			RiskTier:          "medium",
+		},
+		SLADays:         10,
+		Throughput:      forecast.ThroughputSummary{AsOf: seedNow.Format(time.RFC3339), WindowDays: 28, EventsInWindow: 42, ThroughputPerWeek: 10.5},
+		ThroughputTrend: forecast.ThroughputTrendSummary{CurrentWindowStart: seedNow.AddDate(0, 0, -28).Format(time.RFC3339), CurrentWindowEnd: seedNow.Format(time.RFC3339), PriorWindowStart: seedNow.AddDate(0, 0, -56).Format(time.RFC3339), PriorWindowEnd: seedNow.AddDate(0, 0, -28).Format(time.RFC3339), WindowDays: 28, Trends: []forecast.ThroughputTrend{forecast.BuildTrend("overall", 42, 38, 28)}},
+		Queue: &forecast.QueueReport{
+			AsOf:            seedNow.Format(time.RFC3339),
+			TotalPending:    18,
+			AssignedCount:   12,
+			UnassignedCount: 6,
+			OverdueCount:    4,
+			DueSoonCount:    5,
+			OnTrackCount:    9,
+			AvgAgeDays:      5.3,
+			ThroughputDays:  28,
+			DueSoonRatio:    0.8,
+			ClearancePlan: &forecast.QueueClearancePlan{
+				TargetDays:     14,
+				RequiredDaily:  1.29,
+				RequiredWeekly: 9.03,
+				CurrentDaily:   1.5,
+				CurrentWeekly:  10.5,
+				GapDaily:       -0.21,
+				GapWeekly:      -1.47,
+				Status:         "on track",
+			},
+		},
+	}
+	seedQueueSummary := map[string]int{"total_pending": 18, "assigned_count": 12, "unassigned_count": 6, "overdue_count": 4}
+
+	reportJSON, err := json.Marshal(seedReport)
+	if err != nil {
+		return false, err
+	}
+	queueJSON, err := json.Marshal(seedQueueSummary)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = s.insertRunWithMetrics(ctx, seedReport, RunInsert{
+		GeneratedAt:    seedNow,
+		InputPath:      "seed:sample-events.csv",
+		QueuePath:      "seed:sample-queue.csv",
+		SLADays:        10,
+		ThroughputDays: 28,
+		TotalEvents:    48,
+		ReportJSON:     reportJSON,
+		QueueJSON:      queueJSON,
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SaveReport stores a report along with its queue summary, run config, and
+// per-stage, per-reviewer, and queue-stage metric rows, and returns the new run id.
+func (s *Store) SaveReport(ctx context.Context, report forecast.Report, inputPath string, queuePath string) (int64, error) {
+	generatedAt, err := time.Parse(time.RFC3339, report.GeneratedAt)
+	if err != nil {
+		generatedAt = time.Now()
+	}
+
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		return 0, err
+	}
+	queueJSON := []byte(nil)
+	if report.Queue != nil {
+		queueSummary := map[string]int{
+			"total_pending":    report.Queue.TotalPending,
+			"assigned_count":   report.Queue.AssignedCount,
+			"unassigned_count": report.Queue.UnassignedCount,
+			"overdue_count":    report.Queue.OverdueCount,
+			"due_soon_count":   report.Queue.DueSoonCount,
+			"on_track_count":   report.Queue.OnTrackCount,
+		}
+		queueJSON, err = json.Marshal(queueSummary)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	configJSON := []byte(nil)
+	if report.RunConfig != nil {
+		configJSON, err = json.Marshal(report.RunConfig)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return s.insertRunWithMetrics(ctx, report, RunInsert{
+		GeneratedAt:    generatedAt,
+		InputPath:      forecast.SanitizePath(inputPath),
+		QueuePath:      forecast.SanitizePath(queuePath),
+		SLADays:        report.SLADays,
+		ThroughputDays: report.Throughput.WindowDays,
+		TotalEvents:    report.TotalEvents,
+		ReportJSON:     reportJSON,
+		QueueJSON:      queueJSON,
+		ConfigJSON:     configJSON,
+	})
+}
+
+// insertRunWithMetrics stores the run row and its metric rows in one transaction.
+func (s *Store) insertRunWithMetrics(ctx context.Context, report forecast.Report, run RunInsert) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	id, err := s.insertRun(ctx, tx, run)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.insertMetrics(ctx, tx, id, run.GeneratedAt, report); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// InsertRun stores a run row only; SaveReport also fills the metric tables.
+func (s *Store) InsertRun(ctx context.Context, run RunInsert) (int64, error) {
+	return s.insertRun(ctx, s.db, run)
+}
+
+func (s *Store) insertRun(ctx context.Context, db queryer, run RunInsert) (int64, error) {
+	query := fmt.Sprintf(`
+INSERT INTO %s (generated_at, input_path, queue_path, sla_days, throughput_days, total_events, report, queue_summary, run_config)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id
+`, s.table("review_runs"))
+	var id int64
+	err := db.QueryRowContext(ctx, query, run.GeneratedAt, run.InputPath, run.QueuePath, run.SLADays, run.ThroughputDays, run.TotalEvents, run.ReportJSON, nullableJSON(run.QueueJSON), nullableJSON(run.ConfigJSON)).Scan(&id)
+	return id, err
+}
+
+const runSummaryColumns = `id, created_at, generated_at, total_events, sla_days, throughput_days,
+	(queue_summary->>'total_pending')::INT AS total_pending,
+	(queue_summary->>'assigned_count')::INT AS assigned_count,
+	(queue_summary->>'overdue_count')::INT AS overdue_count,
+	run_config->>'profile' AS profile`
+
+// ListRuns returns the most recent runs matching the filter, newest first. Since
+// and Until bound generated_at, with Until exclusive.
+func (s *Store) ListRuns(ctx context.Context, filter RunFilter) ([]RunSummary, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 5
+	}
+	var conditions []string
+	var args []any
+	if !filter.Since.IsZero() {
+		args = append(args, filter.Since)
+		conditions = append(conditions, fmt.Sprintf("generated_at >= $%d", len(args)))
+	}
+	if !filter.Until.IsZero() {
+		args = append(args, filter.Until)
+		conditions = append(conditions, fmt.Sprintf("generated_at < $%d", len(args)))
+	}
+	if strings.TrimSpace(filter.Profile) != "" {
+		args = append(args, strings.TrimSpace(filter.Profile))
+		conditions = append(conditions, fmt.Sprintf("run_config->>'profile' = $%d", len(args)))
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query := fmt.Sprintf(`
+SELECT %s
+FROM %s
+%s
+ORDER BY created_at DESC
+LIMIT $%d
+`, runSummaryColumns, s.table("review_runs"), where, len(args))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []RunSummary
+	for rows.Next() {
+		var summary RunSummary
+		if err := rows.Scan(&summary.ID, &summary.CreatedAt, &summary.GeneratedAt, &summary.TotalEvents, &summary.SLADays, &summary.Throughput, &summary.QueuePending, &summary.QueueAssigned, &summary.QueueOverdue, &summary.Profile); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+func (s *Store) GetRun(ctx context.Context, id int64) (RunRecord, error) {
+	query := fmt.Sprintf(`
+SELECT %s,
+	input_path, queue_path, report
+FROM %s
+WHERE id = $1
+`, runSummaryColumns, s.table("review_runs"))
+
+	run, err := scanRunRecord(s.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, ErrNotFound) {
+		return RunRecord{}, fmt.Errorf("run %d: %w", id, ErrNotFound)
+	}
+	return run, err
+}
+
+// LatestRun returns the newest run, or the newest run that included a pending
+// queue when withQueue is set.
+func (s *Store) LatestRun(ctx context.Context, withQueue bool) (RunRecord, error) {
+	where := ""
+	if withQueue {
+		where = "WHERE queue_summary IS NOT NULL"
+	}
+	query := fmt.Sprintf(`
+SELECT %s,
+	input_path, queue_path, report
+FROM %s
+%s
+ORDER BY created_at DESC
+LIMIT 1
+`, runSummaryColumns, s.table("review_runs"), where)
+
+	return scanRunRecord(s.db.QueryRowContext(ctx, query))
+}
+
+// PreviousRun returns the newest run stored before the run with the given id.
+func (s *Store) PreviousRun(ctx context.Context, id int64) (RunRecord, error) {
+	query := fmt.Sprintf(`
+SELECT %s,
+	input_path, queue_path, report
+FROM %s
+WHERE id < $1
+ORDER BY id DESC
+LIMIT 1
+`, runSummaryColumns, s.table("review_runs"))
+
+	run, err := scanRunRecord(s.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, ErrNotFound) {
+		return RunRecord{}, fmt.Errorf("no run before %d: %w", id, ErrNotFound)
+	}
+	return run, err
+}
+
+func scanRunRecord(row *sql.Row) (RunRecord, error) {
+	var run RunRecord
+	err := row.Scan(&run.ID, &run.CreatedAt, &run.GeneratedAt, &run.TotalEvents, &run.SLADays, &run.Throughput, &run.QueuePending, &run.QueueAssigned, &run.QueueOverdue, &run.Profile, &run.InputPath, &run.QueuePath, &run.ReportJSON)
+	if errors.Is(err, sql.ErrNoRows) {
+		return RunRecord{}, ErrNotFound
+	}
+	if err != nil {
+		return RunRecord{}, err
+	}
+	return run, nil
+}
+
+func nullableJSON(payload []byte) any {
+	if len(payload) == 0 {
+		return nil
+	}
+	return payload
+}
+
+func pqQuoteIdentifier(value string) string {
+	return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"groupscholar-review-queue-forecaster/export"
+	"groupscholar-review-queue-forecaster/forecast"
+)
+
+func TestRunRecordReportReproducesBrief(t *testing.T) {
+	submitted := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	events := []forecast.ReviewEvent{
+		{ApplicationID: "A-1", Stage: "initial", SubmittedAt: submitted, ReviewedAt: submitted.AddDate(0, 0, 4), ReviewerID: "rev-1"},
+		{ApplicationID: "A-2", Stage: "initial", SubmittedAt: submitted, ReviewedAt: submitted.AddDate(0, 0, 12), ReviewerID: "rev-2"},
+	}
+	queue := []forecast.QueueItem{{ApplicationID: "A-3", Stage: "initial", SubmittedAt: submitted.AddDate(0, 0, 2)}}
+	opts := forecast.DefaultOptions()
+	opts.AsOf = submitted.AddDate(0, 0, 14)
+	report, err := forecast.BuildReport(context.Background(), events, queue, opts)
+	if err != nil {
+		t.Fatalf("BuildReport failed: %v", err)
+	}
+	payload, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+
+	stored, err := RunRecord{RunSummary: RunSummary{ID: 7}, ReportJSON: payload}.Report()
+	if err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+	if got, want := export.BuildBrief(stored), export.BuildBrief(report); got != want {
+		t.Fatalf("brief from stored run differs:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestMigrationsAreOrderedAndSchemaQualified(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("Migrations failed: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatalf("expected embedded migrations")
+	}
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			t.Fatalf("expected contiguous versions, got %d at position %d", migration.Version, i)
+		}
+		if !strings.Contains(migration.SQL, "{{schema}}.") {
+			t.Fatalf("migration %04d_%s must qualify tables with {{schema}}", migration.Version, migration.Name)
+		}
+	}
+}
+
+func TestSeriesQueryValidateDefaultsAndRejectsUnknownMetrics(t *testing.T) {
+	query := SeriesQuery{Metric: "p90-days"}
+	if err := query.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if query.Scope != ScopeStage || query.Key != "overall" || query.Metric != "p90_days" || query.Limit != 20 {
+		t.Fatalf("unexpected defaults: %+v", query)
+	}
+
+	bad := SeriesQuery{Metric: "pending_count", Scope: ScopeStage}
+	if err := bad.Validate(); err == nil || !strings.Contains(err.Error(), "p90_days") {
+		t.Fatalf("expected unknown metric error listing stage metrics, got %v", err)
+	}
+	reviewer := SeriesQuery{Metric: "throughput_per_week", Scope: ScopeReviewer}
+	if err := reviewer.Validate(); err == nil {
+		t.Fatalf("expected reviewer series without an id to fail")
+	}
+}